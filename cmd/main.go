@@ -2,9 +2,11 @@
 package main
 
 import (
+	"os"
+
 	"ecom-internship/internal/app"
 )
 
 func main() {
-	app.Run()
+	os.Exit(app.Run())
 }
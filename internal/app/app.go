@@ -2,30 +2,44 @@
 package app
 
 import (
-	"context"
 	"errors"
+	"flag"
+	"fmt"
 	"log"
 	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
-	"time"
 
 	"ecom-internship/internal/config"
 )
 
-// Run initializes and starts the HTTP server with graceful shutdown.
-func Run() {
-	cfg, err := config.Load()
+// Run initializes and starts the HTTP server with graceful shutdown and
+// returns the process exit code: non-zero if a listener failed or in-flight
+// requests were not drained in time.
+func Run() int {
+	cfg, opts, err := config.Parse(os.Args[0], os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		return 0
+	}
+
 	if err != nil {
 		log.Fatal(err)
 	}
 
+	if opts.PrintConfig {
+		if err := cfg.Print(os.Stdout); err != nil {
+			log.Fatal(err)
+		}
+
+		return 0
+	}
+
 	if err := cfg.Validate(); err != nil {
-		log.Fatal("invalid config: ", err)
+		log.Fatal("invalid config:\n", err)
 	}
 
-	app, err := setup(cfg)
+	app, err := setup(cfg, os.Args)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -35,21 +49,59 @@ func Run() {
 	done := make(chan os.Signal, 1)
 	signal.Notify(done, syscall.SIGINT, syscall.SIGTERM)
 
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+
+	// A listener that fails, e.g. because its port is taken, stops the process.
+	failed := make(chan error, 3)
+
 	go func() {
 		if err := app.Server.Start(); err != nil && !errors.Is(err, http.ErrServerClosed) {
-			app.Logger.Error("failed to start server", "error", err)
+			failed <- fmt.Errorf("server: %w", err)
 		}
 	}()
 
-	<-done
-	app.Logger.Info("server is shutting down...")
+	if app.Admin != nil {
+		go func() {
+			if err := app.Admin.Start(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				failed <- fmt.Errorf("admin server: %w", err)
+			}
+		}()
+	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
+	if app.GRPC != nil {
+		go func() {
+			if err := app.GRPC.Start(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				failed <- fmt.Errorf("grpc server: %w", err)
+			}
+		}()
+	}
+
+	exitCode := 0
+
+	for running := true; running; {
+		select {
+		case <-hangup:
+			app.Logger.Info("received SIGHUP, reloading config")
+
+			// Rejected configurations are logged by the reloader.
+			app.reloader.Reload() //nolint:errcheck,gosec
+		case sig := <-done:
+			app.Logger.Info("received signal, shutting down", "signal", sig.String())
+
+			running = false
+		case err := <-failed:
+			app.Logger.Error("failed to start server", "error", err)
+
+			exitCode = 1
+			running = false
+		}
+	}
 
-	if err := app.Server.Stop(ctx); err != nil {
-		app.Logger.Error("failed to shutdown server", "error", err)
+	// A second signal skips the remaining delay and stops waiting for requests.
+	if !app.shutdown(cfg.Server, done) {
+		exitCode = 1
 	}
 
-	app.Logger.Info("server stopped gracefully")
+	return exitCode
 }
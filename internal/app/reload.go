@@ -0,0 +1,87 @@
+package app
+
+import (
+	"sync"
+
+	"ecom-internship/internal/config"
+	"ecom-internship/internal/logger"
+	"ecom-internship/internal/server"
+)
+
+// reloader re-reads the configuration from the same sources as at startup and
+// applies the settings that can change at runtime: log levels, rate limits,
+// CORS policy and request timeouts.
+type reloader struct {
+	args   []string
+	log    logger.Logger
+	router *server.Router
+
+	mu sync.Mutex
+	// started is the configuration the process was started with; changes of
+	// settings that need a restart are reported against it until then.
+	started *config.Config
+	current *config.Config
+}
+
+func newReloader(cfg *config.Config, args []string, log logger.Logger, router *server.Router) *reloader {
+	return &reloader{args: args, log: log, router: router, started: cfg, current: cfg}
+}
+
+// Config returns the last loaded configuration.
+func (rl *reloader) Config() *config.Config {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	return rl.current
+}
+
+// Reload validates the new configuration before applying anything, so an
+// invalid one leaves the running configuration untouched.
+func (rl *reloader) Reload() ([]config.Change, error) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	cfg, _, err := config.Parse(rl.args[0], rl.args[1:])
+	if err == nil {
+		err = cfg.Validate()
+	}
+
+	if err != nil {
+		rl.log.Error("config reload rejected", "error", err)
+
+		return nil, err
+	}
+
+	var changes []config.Change
+
+	for _, c := range rl.current.Diff(cfg) {
+		if c.Reloadable {
+			changes = append(changes, c)
+		}
+	}
+
+	for _, c := range rl.started.Diff(cfg) {
+		if !c.Reloadable {
+			changes = append(changes, c)
+		}
+	}
+
+	if lc, ok := rl.log.(logger.LevelController); ok {
+		lc.SetLevels(logger.Levels{Default: cfg.Logger.Level, Components: cfg.Logger.ComponentLevels})
+	}
+
+	rl.router.Reload(cfg)
+	rl.current = cfg
+
+	for _, c := range changes {
+		if c.Reloadable {
+			rl.log.Info("config setting changed", "key", c.Key, "old", c.Old, "new", c.New)
+		} else {
+			rl.log.Warn("config setting changed, restart required to apply", "key", c.Key, "old", c.Old, "new", c.New)
+		}
+	}
+
+	rl.log.Info("config reloaded", "changes", len(changes))
+
+	return changes, nil
+}
@@ -1,57 +1,143 @@
 package app
 
 import (
+	"fmt"
+	"io"
 	"log/slog"
+	"os"
 
 	"ecom-internship/internal/config"
 	"ecom-internship/internal/database"
+	"ecom-internship/internal/database/instrumented"
 	"ecom-internship/internal/database/mem"
+	"ecom-internship/internal/health"
 	"ecom-internship/internal/logger"
 	"ecom-internship/internal/logger/std"
+	"ecom-internship/internal/metrics"
+	"ecom-internship/internal/rpc"
 	"ecom-internship/internal/server"
+	"ecom-internship/internal/tracing"
+	"ecom-internship/internal/validation"
 )
 
 // App represents the main application with its dependencies.
 type App struct {
 	Server   *server.Server
+	Admin    *server.Server // nil when the admin listener is disabled
+	GRPC     *server.Server // nil when the gRPC listener is disabled
 	Database database.Database
 	Logger   logger.Logger
+	Tracer   *tracing.Tracer
+	Health   *health.Checker
+
+	reloader  *reloader
+	accessLog io.Closer // nil unless the access log is written to a file
 }
 
-func setup(cfg *config.Config) (*App, error) {
+// setup builds the application from cfg; args are the command line the
+// configuration was parsed from and are parsed again on reload.
+func setup(cfg *config.Config, args []string) (*App, error) {
 	rootLogger, err := initLogger(cfg.Logger)
 	if err != nil {
 		return nil, err
 	}
 
+	reg := metrics.NewRegistry()
+	metrics.RegisterRuntime(reg)
+
+	tracer := initTracer(cfg.Tracing, rootLogger.With("component", "tracing"))
+
 	dbLogger := rootLogger.With("component", "database")
 	db, err := initDatabase(cfg.Storage, dbLogger)
 	if err != nil {
 		return nil, err
 	}
 
+	db = instrumented.New(db, reg, tracer)
+
+	hc := health.New(health.DefaultTimeout)
+	if p, ok := db.(database.Pinger); ok {
+		hc.Register("storage", p.Ping)
+	}
+
+	accessOut, accessCloser, err := initAccessLog(cfg)
+	if err != nil {
+		return nil, err
+	}
+
 	srvLogger := rootLogger.With("component", "server")
-	srv, err := initServer(cfg.Server, srvLogger, db)
+	router := server.NewRouter(cfg, srvLogger, db, reg, tracer, hc, accessOut)
+
+	srv, err := server.New(cfg.Server, router, srvLogger)
 	if err != nil {
 		return nil, err
 	}
 
+	rl := newReloader(cfg, args, rootLogger.With("component", "config"), router)
+
+	var admin *server.Server
+	if cfg.Server.AdminPort != "" {
+		adminLogger := rootLogger.With("component", "admin")
+		adminRouter := server.NewAdminRouter(adminLogger, reg, db, rl.Reload, rl.Config, rootLogger)
+
+		admin, err = server.NewAdmin(cfg.Server, adminRouter, adminLogger)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var grpc *server.Server
+	if cfg.Server.GRPCPort != "" {
+		grpcLogger := rootLogger.With("component", "grpc")
+		api := rpc.New(grpcLogger, db, validation.New(cfg.Validation), cfg.Server.GRPCMaxMessageSize)
+
+		grpc, err = server.NewGRPC(cfg.Server, api, grpcLogger)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	return &App{
-		Server:   srv,
-		Database: db,
-		Logger:   rootLogger,
+		Server:    srv,
+		Admin:     admin,
+		GRPC:      grpc,
+		Database:  db,
+		Logger:    rootLogger,
+		Tracer:    tracer,
+		Health:    hc,
+		reloader:  rl,
+		accessLog: accessCloser,
 	}, nil
 }
 
-//nolint:unparam
-func initLogger(cfg *config.LoggerConfig) (logger.Logger, error) {
+func initLogger(cfg *config.LoggerConfig) (*std.StdLogger, error) {
 	switch cfg.Type {
 	case "std":
-		return std.New(cfg.Level), nil
+		return std.NewFromConfig(cfg)
 	default:
 		slog.Warn("unknown logger type, using std")
 
-		return std.New(cfg.Level), nil
+		return std.NewFromConfig(cfg)
+	}
+}
+
+// initAccessLog opens the access log output; a nil writer sends records to
+// the server logger. The closer is set only for files.
+func initAccessLog(cfg *config.Config) (io.Writer, io.Closer, error) {
+	switch output := cfg.AccessLog.Output; output {
+	case "":
+		return nil, nil, nil
+	case "stdout":
+		return os.Stdout, nil, nil
+	case "stderr":
+		return os.Stderr, nil, nil
+	default:
+		w, err := std.NewFileWriter(output, cfg.Logger)
+		if err != nil {
+			return nil, nil, fmt.Errorf("access log: %w", err)
+		}
+
+		return w, w, nil
 	}
 }
 
@@ -67,10 +153,10 @@ func initDatabase(cfg *config.StorageConfig, log logger.Logger) (database.Databa
 	}
 }
 
-//nolint:unparam
-func initServer(cfg *config.ServerConfig, log logger.Logger, db database.Database) (*server.Server, error) {
-	router := server.NewRouter(log, db)
-	srv := server.New(cfg, router, log)
+func initTracer(cfg *config.TracingConfig, log logger.Logger) *tracing.Tracer {
+	if cfg.Endpoint == "" {
+		return tracing.NewTracer(nil)
+	}
 
-	return srv, nil
+	return tracing.NewTracer(tracing.NewOTLPExporter(cfg.Endpoint, cfg.ServiceName, cfg.ExportInterval, log))
 }
@@ -0,0 +1,132 @@
+package app
+
+import (
+	"context"
+	"io"
+	"log"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"ecom-internship/internal/config"
+	"ecom-internship/internal/server"
+)
+
+// flushTimeout bounds flushing spans and closing the storage and log sinks
+// after the listeners have stopped.
+const flushTimeout = 5 * time.Second
+
+// shutdown stops the application in phases:
+//
+//  1. readiness fails, so that load balancers stop routing new requests;
+//  2. the shutdown delay passes while requests are still served;
+//  3. the API, gRPC and admin listeners stop accepting connections together,
+//     the gRPC watch streams end with UNAVAILABLE and in-flight requests on
+//     all listeners are drained concurrently under one deadline;
+//  4. the storage, span exporter, access log and log sinks are flushed and closed.
+//
+// A signal from force skips the rest of the delay and the drain. It reports
+// whether all requests were drained in time.
+func (app *App) shutdown(cfg *config.ServerConfig, force <-chan os.Signal) bool {
+	app.Health.SetShuttingDown()
+
+	if cfg.ShutdownDelay > 0 {
+		app.Logger.Info("readiness disabled, waiting before closing listeners", "delay", cfg.ShutdownDelay.String())
+
+		select {
+		case <-time.After(cfg.ShutdownDelay):
+		case <-force:
+			app.Logger.Warn("received second signal, skipping shutdown delay")
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	if cfg.ShutdownTimeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, cfg.ShutdownTimeout)
+		defer cancel()
+	}
+
+	go func() {
+		select {
+		case <-force:
+			app.Logger.Warn("received second signal, closing connections")
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	servers := []*server.Server{app.Server}
+	if app.GRPC != nil {
+		servers = append(servers, app.GRPC)
+	}
+
+	if app.Admin != nil {
+		servers = append(servers, app.Admin)
+	}
+
+	// Each Stop signals draining and closes its listeners before waiting, so
+	// stopping the servers together closes all listeners at once and gives
+	// every server the whole deadline.
+	var (
+		wg      sync.WaitGroup
+		timeout atomic.Bool
+	)
+
+	for _, srv := range servers {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			if err := srv.Stop(ctx); err != nil {
+				timeout.Store(true)
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	drained := !timeout.Load()
+
+	app.flush()
+
+	if drained {
+		app.Logger.Info("server stopped gracefully")
+	} else {
+		app.Logger.Error("server stopped before all requests were served")
+	}
+
+	// The file and syslog sinks are closed last, after the final messages.
+	if c, ok := app.Logger.(io.Closer); ok {
+		if err := c.Close(); err != nil {
+			log.Println("failed to close log sinks:", err)
+		}
+	}
+
+	return drained
+}
+
+// flush closes the components that buffer data once no requests can write more of it.
+func (app *App) flush() {
+	ctx, cancel := context.WithTimeout(context.Background(), flushTimeout)
+	defer cancel()
+
+	if c, ok := app.Database.(io.Closer); ok {
+		if err := c.Close(); err != nil {
+			app.Logger.Error("failed to close storage", "error", err)
+		}
+	}
+
+	if err := app.Tracer.Shutdown(ctx); err != nil {
+		app.Logger.Error("failed to flush spans", "error", err)
+	}
+
+	if app.accessLog != nil {
+		if err := app.accessLog.Close(); err != nil {
+			app.Logger.Error("failed to close access log", "error", err)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+// Package buildinfo reports the version of the running binary.
+//
+// Version, Commit and Date are injected at build time:
+//
+//	go build -ldflags "-X ecom-internship/internal/buildinfo.Version=v1.2.0 \
+//		-X ecom-internship/internal/buildinfo.Commit=$(git rev-parse HEAD) \
+//		-X ecom-internship/internal/buildinfo.Date=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+package buildinfo
+
+import "runtime/debug"
+
+// Set with -ldflags -X.
+var (
+	Version = "dev"
+	Commit  = ""
+	Date    = ""
+)
+
+// Info describes the running binary.
+type Info struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit,omitempty"`
+	Date      string `json:"date,omitempty"`
+	GoVersion string `json:"go_version"`
+	Module    string `json:"module"`
+	// Modified reports uncommitted changes in the working tree the binary was built from.
+	Modified bool `json:"modified,omitempty"`
+}
+
+// Get returns the injected values, falling back to the version control
+// information embedded by the go command when they were not set.
+func Get() Info {
+	info := Info{Version: Version, Commit: Commit, Date: Date}
+
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return info
+	}
+
+	info.GoVersion = bi.GoVersion
+	info.Module = bi.Main.Path
+
+	for _, s := range bi.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			if info.Commit == "" {
+				info.Commit = s.Value
+			}
+		case "vcs.time":
+			if info.Date == "" {
+				info.Date = s.Value
+			}
+		case "vcs.modified":
+			info.Modified = s.Value == "true"
+		}
+	}
+
+	return info
+}
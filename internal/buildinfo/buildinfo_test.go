@@ -0,0 +1,21 @@
+package buildinfo
+
+import (
+	"runtime"
+	"testing"
+)
+
+func TestGet(t *testing.T) {
+	defer func(version, commit string) { Version, Commit = version, commit }(Version, Commit)
+
+	Version, Commit = "v1.2.0", "abc123"
+
+	info := Get()
+	if info.Version != "v1.2.0" || info.Commit != "abc123" {
+		t.Errorf("Expected injected values, got %+v", info)
+	}
+
+	if info.GoVersion != runtime.Version() {
+		t.Errorf("Expected Go version %s, got %s", runtime.Version(), info.GoVersion)
+	}
+}
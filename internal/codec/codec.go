@@ -0,0 +1,176 @@
+// Package codec provides content negotiation and encoding of HTTP payloads
+// in JSON, XML, YAML and MessagePack.
+package codec
+
+import (
+	"errors"
+	"io"
+	"mime"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Codec encodes and decodes payloads of a single media type.
+type Codec interface {
+	// ContentType returns the value of the Content-Type header for encoded payloads.
+	ContentType() string
+	Encode(w io.Writer, v any) error
+	Decode(r io.Reader, v any) error
+}
+
+// Negotiation errors.
+var (
+	ErrNotAcceptable        = errors.New("none of the accepted media types is supported")
+	ErrUnsupportedMediaType = errors.New("unsupported media type")
+)
+
+// Available codecs.
+var (
+	JSON    Codec = jsonCodec{}
+	XML     Codec = xmlCodec{}
+	YAML    Codec = yamlCodec{}
+	MsgPack Codec = msgpackCodec{}
+)
+
+// mediaTypes maps supported media types to codecs in order of server preference.
+var mediaTypes = []struct {
+	mediaType string
+	codec     Codec
+}{
+	{"application/json", JSON},
+	{"application/xml", XML},
+	{"text/xml", XML},
+	{"application/yaml", YAML},
+	{"application/x-yaml", YAML},
+	{"text/yaml", YAML},
+	{"application/msgpack", MsgPack},
+	{"application/vnd.msgpack", MsgPack},
+	{"application/x-msgpack", MsgPack},
+}
+
+type acceptRange struct {
+	mediaType string
+	q         float64
+	order     int
+}
+
+// Negotiate selects a codec for the given Accept header value.
+// An empty header accepts anything and yields JSON.
+func Negotiate(accept string) (Codec, error) {
+	if strings.TrimSpace(accept) == "" {
+		return JSON, nil
+	}
+
+	ranges := parseAccept(accept)
+
+	for _, ar := range ranges {
+		if ar.q <= 0 {
+			continue
+		}
+
+		for _, mt := range mediaTypes {
+			if matches(ar.mediaType, mt.mediaType) && !excluded(ranges, mt.mediaType) {
+				return mt.codec, nil
+			}
+		}
+	}
+
+	return nil, ErrNotAcceptable
+}
+
+// ForContentType selects a codec for decoding a body with the given Content-Type.
+// An empty header is treated as JSON.
+func ForContentType(contentType string) (Codec, error) {
+	if strings.TrimSpace(contentType) == "" {
+		return JSON, nil
+	}
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil, ErrUnsupportedMediaType
+	}
+
+	for _, mt := range mediaTypes {
+		if mt.mediaType == mediaType {
+			return mt.codec, nil
+		}
+	}
+
+	return nil, ErrUnsupportedMediaType
+}
+
+// parseAccept returns media ranges ordered by quality, then specificity, then position.
+func parseAccept(accept string) []acceptRange {
+	var ranges []acceptRange
+
+	for i, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+
+		q := 1.0
+
+		if v, ok := params["q"]; ok {
+			if parsed, err := strconv.ParseFloat(v, 64); err == nil && parsed >= 0 && parsed <= 1 {
+				q = parsed
+			}
+		}
+
+		ranges = append(ranges, acceptRange{mediaType: mediaType, q: q, order: i})
+	}
+
+	slices.SortStableFunc(ranges, func(a, b acceptRange) int {
+		switch {
+		case a.q != b.q:
+			if a.q > b.q {
+				return -1
+			}
+
+			return 1
+		case specificity(a.mediaType) != specificity(b.mediaType):
+			return specificity(b.mediaType) - specificity(a.mediaType)
+		default:
+			return a.order - b.order
+		}
+	})
+
+	return ranges
+}
+
+// excluded reports whether the most specific range matching mediaType has q=0.
+func excluded(ranges []acceptRange, mediaType string) bool {
+	best := -1
+	q := 0.0
+
+	for _, ar := range ranges {
+		if s := specificity(ar.mediaType); matches(ar.mediaType, mediaType) && s > best {
+			best = s
+			q = ar.q
+		}
+	}
+
+	return best >= 0 && q <= 0
+}
+
+func specificity(mediaRange string) int {
+	switch {
+	case mediaRange == "*/*":
+		return 0
+	case strings.HasSuffix(mediaRange, "/*"):
+		return 1
+	default:
+		return 2
+	}
+}
+
+func matches(mediaRange, mediaType string) bool {
+	if mediaRange == "*/*" || mediaRange == mediaType {
+		return true
+	}
+
+	prefix, ok := strings.CutSuffix(mediaRange, "/*")
+
+	return ok && strings.HasPrefix(mediaType, prefix+"/")
+}
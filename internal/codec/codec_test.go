@@ -0,0 +1,171 @@
+package codec
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+type payload struct {
+	ID      int        `json:"id"      xml:"id"`
+	Caption string     `json:"caption" xml:"caption"`
+	Done    bool       `json:"done"    xml:"done"`
+	Score   float64    `json:"score"   xml:"score"`
+	Tags    []string   `json:"tags"    xml:"tag"`
+	Due     *time.Time `json:"due,omitempty" xml:"due,omitempty"`
+}
+
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		accept string
+		want   Codec
+		err    error
+	}{
+		{"", JSON, nil},
+		{"*/*", JSON, nil},
+		{"application/xml", XML, nil},
+		{"text/html, application/yaml;q=0.5, application/xml;q=0.9", XML, nil},
+		{"application/*;q=0.1, application/msgpack", MsgPack, nil},
+		{"application/json;q=0, */*", XML, nil},
+		{"text/yaml", YAML, nil},
+		{"text/html", nil, ErrNotAcceptable},
+		{"application/json;q=0", nil, ErrNotAcceptable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.accept, func(t *testing.T) {
+			got, err := Negotiate(tt.accept)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("Expected error %v, got %v", tt.err, err)
+			}
+			if got != tt.want {
+				t.Errorf("Expected %T, got %T", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestForContentType(t *testing.T) {
+	if c, err := ForContentType(""); err != nil || c != JSON {
+		t.Errorf("Expected JSON for empty content type, got %T, %v", c, err)
+	}
+	if c, err := ForContentType("application/x-msgpack"); err != nil || c != MsgPack {
+		t.Errorf("Expected MsgPack, got %T, %v", c, err)
+	}
+	if _, err := ForContentType("text/plain"); !errors.Is(err, ErrUnsupportedMediaType) {
+		t.Errorf("Expected ErrUnsupportedMediaType, got %v", err)
+	}
+}
+
+func TestCodecs_RoundTrip(t *testing.T) {
+	due := time.Date(2026, 1, 10, 18, 0, 0, 0, time.UTC)
+	in := payload{
+		ID:      42,
+		Caption: "Buy: milk # and bread",
+		Done:    true,
+		Score:   -1.5,
+		Tags:    []string{"home", "", "123", "multi\nline"},
+		Due:     &due,
+	}
+
+	for _, c := range []Codec{JSON, XML, YAML, MsgPack} {
+		t.Run(c.ContentType(), func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := c.Encode(&buf, in); err != nil {
+				t.Fatalf("Encode failed: %v", err)
+			}
+
+			var out payload
+			if err := c.Decode(&buf, &out); err != nil {
+				t.Fatalf("Decode failed: %v", err)
+			}
+
+			if out.ID != in.ID || out.Caption != in.Caption || out.Done != in.Done || out.Score != in.Score {
+				t.Errorf("Expected %+v, got %+v", in, out)
+			}
+			if strings.Join(out.Tags, "|") != strings.Join(in.Tags, "|") {
+				t.Errorf("Expected tags %q, got %q", in.Tags, out.Tags)
+			}
+			if out.Due == nil || !out.Due.Equal(due) {
+				t.Errorf("Expected due %v, got %v", due, out.Due)
+			}
+		})
+	}
+}
+
+func TestYAML_Decode(t *testing.T) {
+	input := `# a todo
+---
+id: 0x1F
+caption: 'It''s done'   # trailing comment
+done: yes
+score: 2.50
+tags:
+- first
+- "second ☺"
+- [nested, 1]
+due: 2026-01-10T18:00:00Z
+description: |
+  line one
+    indented
+
+  line three
+folded: >-
+  one
+  two
+`
+
+	var out map[string]any
+	if err := YAML.Decode(strings.NewReader(input), &out); err != nil {
+		t.Fatalf("Decode failed: %v", err)
+	}
+
+	want := map[string]any{
+		"id":          float64(31),
+		"caption":     "It's done",
+		"done":        "yes",
+		"score":       2.5,
+		"due":         "2026-01-10T18:00:00Z",
+		"description": "line one\n  indented\n\nline three\n",
+		"folded":      "one two",
+	}
+
+	for key, value := range want {
+		if out[key] != value {
+			t.Errorf("Expected %s=%#v, got %#v", key, value, out[key])
+		}
+	}
+
+	tags, ok := out["tags"].([]any)
+	if !ok || len(tags) != 3 || tags[1] != "second ☺" {
+		t.Errorf("Unexpected tags: %#v", out["tags"])
+	}
+}
+
+func TestYAML_DecodeErrors(t *testing.T) {
+	inputs := []string{
+		"key: value\n  nested: bad\n",
+		"key: &anchor value\n",
+		"a: 1\na: 2\n",
+		"key: \"unterminated\n",
+		"\tkey: value\n",
+	}
+
+	for _, input := range inputs {
+		var out map[string]any
+		if err := YAML.Decode(strings.NewReader(input), &out); err == nil {
+			t.Errorf("Expected error for %q", input)
+		}
+	}
+}
+
+func TestMsgPack_DecodeTruncated(t *testing.T) {
+	var out map[string]any
+
+	// A map header announcing 15 entries followed by nothing.
+	if err := MsgPack.Decode(bytes.NewReader([]byte{0x8f}), &out); !errors.Is(err, errMsgPackTruncated) {
+		t.Errorf("Expected errMsgPackTruncated, got %v", err)
+	}
+}
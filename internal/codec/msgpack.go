@@ -0,0 +1,379 @@
+package codec
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"time"
+)
+
+var (
+	errMsgPackTruncated = errors.New("msgpack: unexpected end of data")
+	errMsgPackTrailing  = errors.New("msgpack: trailing data after value")
+	errMsgPackKey       = errors.New("msgpack: map keys must be strings")
+)
+
+// timestampExt is the MessagePack extension type reserved for timestamps.
+const timestampExt = -1
+
+type msgpackCodec struct{}
+
+func (msgpackCodec) ContentType() string {
+	return "application/msgpack"
+}
+
+func (msgpackCodec) Encode(w io.Writer, v any) error {
+	tree, err := toTree(v)
+	if err != nil {
+		return err
+	}
+
+	bw := bufio.NewWriter(w)
+	if err := writeMsgPack(bw, tree); err != nil {
+		return err
+	}
+
+	return bw.Flush()
+}
+
+func (msgpackCodec) Decode(r io.Reader, v any) error {
+	data, err := readAll(r)
+	if err != nil {
+		return err
+	}
+
+	dec := &msgpackDecoder{data: data}
+
+	tree, err := dec.value(0)
+	if err != nil {
+		return err
+	}
+
+	if dec.pos != len(dec.data) {
+		return errMsgPackTrailing
+	}
+
+	return fromTree(tree, v)
+}
+
+//nolint:cyclop
+func writeMsgPack(w *bufio.Writer, v any) error {
+	switch v := v.(type) {
+	case nil:
+		return w.WriteByte(0xc0)
+	case bool:
+		if v {
+			return w.WriteByte(0xc3)
+		}
+
+		return w.WriteByte(0xc2)
+	case json.Number:
+		return writeMsgPackNumber(w, v)
+	case string:
+		writeMsgPackHeader(w, len(v), 0xa0, 31, 0xd9, 0xda, 0xdb)
+		_, err := w.WriteString(v)
+
+		return err
+	case []any:
+		writeMsgPackHeader(w, len(v), 0x90, 15, 0, 0xdc, 0xdd)
+
+		for _, item := range v {
+			if err := writeMsgPack(w, item); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	case object:
+		writeMsgPackHeader(w, len(v), 0x80, 15, 0, 0xde, 0xdf)
+
+		for _, m := range v {
+			if err := writeMsgPack(w, m.key); err != nil {
+				return err
+			}
+
+			if err := writeMsgPack(w, m.value); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	default:
+		return fmt.Errorf("msgpack: unsupported type %T", v)
+	}
+}
+
+func writeMsgPackNumber(w *bufio.Writer, n json.Number) error {
+	if i, err := strconv.ParseInt(string(n), 10, 64); err == nil {
+		writeMsgPackInt(w, i)
+
+		return nil
+	}
+
+	f, err := n.Float64()
+	if err != nil {
+		return err
+	}
+
+	w.WriteByte(0xcb) //nolint:errcheck,gosec
+
+	return binary.Write(w, binary.BigEndian, math.Float64bits(f))
+}
+
+//nolint:errcheck,gosec
+func writeMsgPackInt(w *bufio.Writer, i int64) {
+	switch {
+	case i >= 0 && i <= 127:
+		w.WriteByte(byte(i))
+	case i < 0 && i >= -32:
+		w.WriteByte(byte(i))
+	case i >= math.MinInt8 && i <= math.MaxInt8:
+		w.Write([]byte{0xd0, byte(i)})
+	case i >= math.MinInt16 && i <= math.MaxInt16:
+		w.WriteByte(0xd1)
+		binary.Write(w, binary.BigEndian, int16(i))
+	case i >= math.MinInt32 && i <= math.MaxInt32:
+		w.WriteByte(0xd2)
+		binary.Write(w, binary.BigEndian, int32(i))
+	default:
+		w.WriteByte(0xd3)
+		binary.Write(w, binary.BigEndian, i)
+	}
+}
+
+// writeMsgPackHeader writes a length prefix using the fix, 8, 16 or 32 bit form.
+// A zero code means the 8 bit form does not exist for the type.
+//
+//nolint:errcheck,gosec
+func writeMsgPackHeader(w *bufio.Writer, n int, fix byte, fixMax int, code8, code16, code32 byte) {
+	switch {
+	case n <= fixMax:
+		w.WriteByte(fix | byte(n))
+	case code8 != 0 && n <= math.MaxUint8:
+		w.Write([]byte{code8, byte(n)})
+	case n <= math.MaxUint16:
+		w.WriteByte(code16)
+		binary.Write(w, binary.BigEndian, uint16(n))
+	default:
+		w.WriteByte(code32)
+		binary.Write(w, binary.BigEndian, uint32(n))
+	}
+}
+
+type msgpackDecoder struct {
+	data []byte
+	pos  int
+}
+
+func (d *msgpackDecoder) read(n int) ([]byte, error) {
+	if n < 0 || n > len(d.data)-d.pos {
+		return nil, errMsgPackTruncated
+	}
+
+	b := d.data[d.pos : d.pos+n]
+	d.pos += n
+
+	return b, nil
+}
+
+func (d *msgpackDecoder) uint(size int) (uint64, error) {
+	b, err := d.read(size)
+	if err != nil {
+		return 0, err
+	}
+
+	var v uint64
+	for _, c := range b {
+		v = v<<8 | uint64(c)
+	}
+
+	return v, nil
+}
+
+func (d *msgpackDecoder) int(size int) (int64, error) {
+	v, err := d.uint(size)
+	if err != nil {
+		return 0, err
+	}
+
+	shift := 64 - 8*size
+
+	return int64(v<<shift) >> shift, nil //nolint:gosec
+}
+
+//nolint:cyclop,funlen,gocyclo
+func (d *msgpackDecoder) value(depth int) (any, error) {
+	if depth > maxDepth {
+		return nil, errTooDeep
+	}
+
+	b, err := d.read(1)
+	if err != nil {
+		return nil, err
+	}
+
+	c := b[0]
+
+	switch {
+	case c <= 0x7f:
+		return int64(c), nil
+	case c >= 0xe0:
+		return int64(int8(c)), nil //nolint:gosec
+	case c&0xe0 == 0xa0:
+		return d.str(int(c & 0x1f))
+	case c&0xf0 == 0x90:
+		return d.array(int(c&0x0f), depth)
+	case c&0xf0 == 0x80:
+		return d.mapping(int(c&0x0f), depth)
+	}
+
+	switch c {
+	case 0xc0:
+		return nil, nil
+	case 0xc2:
+		return false, nil
+	case 0xc3:
+		return true, nil
+	case 0xcc, 0xcd, 0xce, 0xcf:
+		return d.uint(1 << (c - 0xcc))
+	case 0xd0, 0xd1, 0xd2, 0xd3:
+		return d.int(1 << (c - 0xd0))
+	case 0xca:
+		v, err := d.uint(4)
+
+		return float64(math.Float32frombits(uint32(v))), err //nolint:gosec
+	case 0xcb:
+		v, err := d.uint(8)
+
+		return math.Float64frombits(v), err
+	case 0xd9, 0xda, 0xdb:
+		n, err := d.uint(1 << (c - 0xd9))
+		if err != nil {
+			return nil, err
+		}
+
+		return d.str(int(n)) //nolint:gosec
+	case 0xc4, 0xc5, 0xc6:
+		n, err := d.uint(1 << (c - 0xc4))
+		if err != nil {
+			return nil, err
+		}
+
+		return d.read(int(n)) //nolint:gosec
+	case 0xdc, 0xdd:
+		n, err := d.uint(2 << (c - 0xdc))
+		if err != nil {
+			return nil, err
+		}
+
+		return d.array(int(n), depth) //nolint:gosec
+	case 0xde, 0xdf:
+		n, err := d.uint(2 << (c - 0xde))
+		if err != nil {
+			return nil, err
+		}
+
+		return d.mapping(int(n), depth) //nolint:gosec
+	case 0xd4, 0xd5, 0xd6, 0xd7, 0xd8:
+		return d.ext(1 << (c - 0xd4))
+	case 0xc7, 0xc8, 0xc9:
+		n, err := d.uint(1 << (c - 0xc7))
+		if err != nil {
+			return nil, err
+		}
+
+		return d.ext(int(n)) //nolint:gosec
+	default:
+		return nil, fmt.Errorf("msgpack: invalid type code 0x%x", c)
+	}
+}
+
+func (d *msgpackDecoder) str(n int) (string, error) {
+	b, err := d.read(n)
+
+	return string(b), err
+}
+
+func (d *msgpackDecoder) array(n, depth int) ([]any, error) {
+	if n > len(d.data)-d.pos {
+		return nil, errMsgPackTruncated
+	}
+
+	arr := make([]any, 0, n)
+
+	for range n {
+		item, err := d.value(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+
+		arr = append(arr, item)
+	}
+
+	return arr, nil
+}
+
+func (d *msgpackDecoder) mapping(n, depth int) (map[string]any, error) {
+	if n > len(d.data)-d.pos {
+		return nil, errMsgPackTruncated
+	}
+
+	m := make(map[string]any, n)
+
+	for range n {
+		key, err := d.value(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+
+		k, ok := key.(string)
+		if !ok {
+			return nil, errMsgPackKey
+		}
+
+		if m[k], err = d.value(depth + 1); err != nil {
+			return nil, err
+		}
+	}
+
+	return m, nil
+}
+
+// ext decodes extension values. Timestamps are returned as time.Time,
+// other extensions as raw bytes.
+func (d *msgpackDecoder) ext(n int) (any, error) {
+	typ, err := d.int(1)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := d.read(n)
+	if err != nil {
+		return nil, err
+	}
+
+	if typ != timestampExt {
+		return data, nil
+	}
+
+	switch n {
+	case 4:
+		return time.Unix(int64(binary.BigEndian.Uint32(data)), 0).UTC(), nil
+	case 8:
+		v := binary.BigEndian.Uint64(data)
+
+		return time.Unix(int64(v&0x3ffffffff), int64(v>>34)).UTC(), nil //nolint:gosec
+	case 12:
+		nsec := binary.BigEndian.Uint32(data[:4])
+		sec := int64(binary.BigEndian.Uint64(data[4:])) //nolint:gosec
+
+		return time.Unix(sec, int64(nsec)).UTC(), nil
+	default:
+		return nil, fmt.Errorf("msgpack: invalid timestamp length %d", n)
+	}
+}
@@ -0,0 +1,117 @@
+package codec
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// YAML and MessagePack payloads are mapped to Go values through JSON, so both
+// formats follow the json struct tags and marshalers of the models.
+
+// maxDepth limits nesting of decoded documents.
+const maxDepth = 64
+
+var errTooDeep = errors.New("document nesting is too deep")
+
+// member is a key-value pair of an object preserving the field order.
+type member struct {
+	key   string
+	value any
+}
+
+// object is a JSON object with ordered members.
+type object []member
+
+// toTree converts v into a tree of nil, bool, json.Number, string, []any and object.
+func toTree(v any) (any, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	return readTree(dec)
+}
+
+func readTree(dec *json.Decoder) (any, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	delim, ok := tok.(json.Delim)
+	if !ok {
+		return tok, nil
+	}
+
+	switch delim {
+	case '[':
+		arr := make([]any, 0)
+
+		for dec.More() {
+			item, err := readTree(dec)
+			if err != nil {
+				return nil, err
+			}
+
+			arr = append(arr, item)
+		}
+
+		_, err = dec.Token()
+
+		return arr, err
+	case '{':
+		obj := make(object, 0)
+
+		for dec.More() {
+			keyTok, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+
+			key, _ := keyTok.(string)
+
+			value, err := readTree(dec)
+			if err != nil {
+				return nil, err
+			}
+
+			obj = append(obj, member{key: key, value: value})
+		}
+
+		_, err = dec.Token()
+
+		return obj, err
+	default:
+		return nil, fmt.Errorf("unexpected delimiter %v", delim)
+	}
+}
+
+// fromTree stores a generic decoded value into v.
+func fromTree(tree any, v any) error {
+	data, err := json.Marshal(tree)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(data, v)
+}
+
+// readAll reads the whole body, treating an empty one as io.EOF like json.Decoder does.
+func readAll(r io.Reader) ([]byte, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) == 0 {
+		return nil, io.EOF
+	}
+
+	return data, nil
+}
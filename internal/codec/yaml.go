@@ -0,0 +1,174 @@
+package codec
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+)
+
+// yamlCodec encodes payloads as YAML 1.2 block documents.
+// Decoding supports the subset of YAML used for API payloads, see yamlParser.
+type yamlCodec struct{}
+
+func (yamlCodec) ContentType() string {
+	return "application/yaml; charset=utf-8"
+}
+
+func (yamlCodec) Encode(w io.Writer, v any) error {
+	tree, err := toTree(v)
+	if err != nil {
+		return err
+	}
+
+	bw := bufio.NewWriter(w)
+
+	bw.WriteString("---\n") //nolint:errcheck,gosec
+	writeYAMLBlock(bw, tree, 0)
+
+	return bw.Flush()
+}
+
+func (yamlCodec) Decode(r io.Reader, v any) error {
+	data, err := readAll(r)
+	if err != nil {
+		return err
+	}
+
+	tree, err := parseYAML(data)
+	if err != nil {
+		return err
+	}
+
+	return fromTree(tree, v)
+}
+
+// writeYAMLBlock writes a value that occupies its own lines.
+//
+//nolint:errcheck,gosec
+func writeYAMLBlock(w *bufio.Writer, v any, indent int) {
+	pad := strings.Repeat("  ", indent)
+
+	switch v := v.(type) {
+	case object:
+		if len(v) == 0 {
+			w.WriteString(pad + "{}\n")
+
+			return
+		}
+
+		for _, m := range v {
+			w.WriteString(pad + yamlScalar(m.key) + ":")
+			writeYAMLValue(w, m.value, indent+1)
+		}
+	case []any:
+		if len(v) == 0 {
+			w.WriteString(pad + "[]\n")
+
+			return
+		}
+
+		for _, item := range v {
+			if obj, ok := item.(object); ok && len(obj) > 0 {
+				// Objects start on the dash line: "- key: value".
+				var buf bytes.Buffer
+
+				bw := bufio.NewWriter(&buf)
+				writeYAMLBlock(bw, obj, indent+1)
+				bw.Flush()
+
+				w.WriteString(pad + "- ")
+				w.Write(buf.Bytes()[len(pad)+2:])
+
+				continue
+			}
+
+			w.WriteString(pad + "-")
+			writeYAMLValue(w, item, indent+1)
+		}
+	default:
+		w.WriteString(pad + yamlScalar(v) + "\n")
+	}
+}
+
+// writeYAMLValue writes a value following a "key:" or "-" indicator.
+//
+//nolint:errcheck,gosec
+func writeYAMLValue(w *bufio.Writer, v any, indent int) {
+	switch v := v.(type) {
+	case object:
+		if len(v) == 0 {
+			w.WriteString(" {}\n")
+
+			return
+		}
+
+		w.WriteString("\n")
+		writeYAMLBlock(w, v, indent)
+	case []any:
+		if len(v) == 0 {
+			w.WriteString(" []\n")
+
+			return
+		}
+
+		w.WriteString("\n")
+		writeYAMLBlock(w, v, indent)
+	default:
+		w.WriteString(" " + yamlScalar(v) + "\n")
+	}
+}
+
+func yamlScalar(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		if v {
+			return "true"
+		}
+
+		return "false"
+	case json.Number:
+		return v.String()
+	case string:
+		if yamlPlainSafe(v) {
+			return v
+		}
+
+		// JSON strings are valid YAML double-quoted scalars.
+		var buf bytes.Buffer
+
+		enc := json.NewEncoder(&buf)
+		enc.SetEscapeHTML(false)
+		enc.Encode(v) //nolint:errcheck,gosec
+
+		return strings.TrimSuffix(buf.String(), "\n")
+	default:
+		return ""
+	}
+}
+
+// yamlPlainSafe reports whether s can be written unquoted and read back as the same string.
+func yamlPlainSafe(s string) bool {
+	if s == "" || s != strings.TrimSpace(s) {
+		return false
+	}
+
+	if _, isString := parseYAMLPlain(s).(string); !isString {
+		return false
+	}
+
+	if strings.ContainsAny(s[:1], "-?:,[]{}#&*!|>'\"%@`") {
+		return false
+	}
+
+	for _, r := range s {
+		if r < ' ' || r == 0x7f {
+			return false
+		}
+	}
+
+	return !strings.Contains(s, ": ") && !strings.Contains(s, " #") && !strings.HasSuffix(s, ":")
+}
@@ -0,0 +1,703 @@
+package codec
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// yamlParser reads the subset of YAML 1.2 needed for API payloads: block
+// mappings and sequences, plain, quoted and block (| and >) scalars, flow
+// collections and comments. Anchors, aliases, tags and multi-document
+// streams are not supported.
+type yamlParser struct {
+	lines []string
+	pos   int
+}
+
+var (
+	errYAMLUnsupported = errors.New("yaml: unsupported syntax")
+	errYAMLTab         = errors.New("yaml: tabs are not allowed in indentation")
+
+	yamlInt   = regexp.MustCompile(`^[-+]?[0-9]+$`)
+	yamlFloat = regexp.MustCompile(`^[-+]?(\.[0-9]+|[0-9]+(\.[0-9]*)?)([eE][-+]?[0-9]+)?$`)
+)
+
+func parseYAML(data []byte) (any, error) {
+	text := strings.ReplaceAll(string(data), "\r\n", "\n")
+	text = strings.TrimPrefix(text, "\ufeff")
+
+	p := &yamlParser{lines: strings.Split(text, "\n")}
+
+	for i, line := range p.lines {
+		if trimmed := strings.TrimLeft(line, " "); strings.HasPrefix(trimmed, "\t") {
+			return nil, fmt.Errorf("%w (line %d)", errYAMLTab, i+1)
+		}
+	}
+
+	p.skipBlank()
+
+	if p.pos < len(p.lines) && strings.HasPrefix(p.lines[p.pos], "%") {
+		return nil, p.errorf("directives are not supported")
+	}
+
+	if p.pos < len(p.lines) && isDocumentMarker(p.lines[p.pos], "---") {
+		rest := strings.TrimSpace(stripComment(p.lines[p.pos][3:]))
+		if rest != "" {
+			p.lines[p.pos] = rest
+		} else {
+			p.pos++
+			p.skipBlank()
+		}
+	}
+
+	if p.pos >= len(p.lines) {
+		return nil, nil
+	}
+
+	value, err := p.block(indentOf(p.lines[p.pos]), 0)
+	if err != nil {
+		return nil, err
+	}
+
+	p.skipBlank()
+
+	if p.pos < len(p.lines) && isDocumentMarker(p.lines[p.pos], "...") {
+		p.pos++
+		p.skipBlank()
+	}
+
+	if p.pos < len(p.lines) {
+		return nil, p.errorf("unexpected content")
+	}
+
+	return value, nil
+}
+
+func (p *yamlParser) errorf(format string, args ...any) error {
+	return fmt.Errorf("%w at line %d: %s", errYAMLUnsupported, p.pos+1, fmt.Sprintf(format, args...))
+}
+
+func isDocumentMarker(line, marker string) bool {
+	return line == marker || strings.HasPrefix(line, marker+" ")
+}
+
+func indentOf(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " "))
+}
+
+// skipBlank moves past empty and comment-only lines.
+func (p *yamlParser) skipBlank() {
+	for p.pos < len(p.lines) {
+		trimmed := strings.TrimSpace(p.lines[p.pos])
+		if trimmed != "" && !strings.HasPrefix(trimmed, "#") {
+			return
+		}
+
+		p.pos++
+	}
+}
+
+// current returns the comment-free content of the current line.
+func (p *yamlParser) current() string {
+	return strings.TrimSpace(stripComment(p.lines[p.pos]))
+}
+
+func isSequenceItem(content string) bool {
+	return content == "-" || strings.HasPrefix(content, "- ")
+}
+
+// block parses the node starting at the current line, which is indented by indent.
+func (p *yamlParser) block(indent, depth int) (any, error) {
+	if depth > maxDepth {
+		return nil, errTooDeep
+	}
+
+	content := p.current()
+
+	if isSequenceItem(content) {
+		return p.sequence(indent, depth)
+	}
+
+	if _, _, ok, err := splitKey(content); err != nil {
+		return nil, p.errorf("%v", err)
+	} else if ok {
+		return p.mapping(indent, depth)
+	}
+
+	value, err := parseYAMLInline(content)
+	if err != nil {
+		return nil, p.errorf("%v", err)
+	}
+
+	p.pos++
+
+	return value, nil
+}
+
+func (p *yamlParser) sequence(indent, depth int) (any, error) {
+	items := make([]any, 0)
+
+	for {
+		p.skipBlank()
+
+		if p.pos >= len(p.lines) || indentOf(p.lines[p.pos]) != indent || !isSequenceItem(p.current()) {
+			break
+		}
+
+		line := p.lines[p.pos]
+		rest := strings.TrimLeft(line[indent+1:], " ")
+
+		var (
+			item any
+			err  error
+		)
+
+		if strings.TrimSpace(stripComment(rest)) == "" {
+			p.pos++
+			item, err = p.nested(indent, depth)
+		} else {
+			// Re-read the item content as a block indented past the dash.
+			itemIndent := len(line) - len(rest)
+			p.lines[p.pos] = strings.Repeat(" ", itemIndent) + rest
+			item, err = p.block(itemIndent, depth+1)
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		items = append(items, item)
+	}
+
+	return items, p.checkDedent(indent)
+}
+
+func (p *yamlParser) mapping(indent, depth int) (any, error) {
+	m := make(map[string]any)
+
+	for {
+		p.skipBlank()
+
+		if p.pos >= len(p.lines) || indentOf(p.lines[p.pos]) != indent {
+			break
+		}
+
+		key, rest, ok, err := splitKey(p.current())
+		if err != nil {
+			return nil, p.errorf("%v", err)
+		}
+
+		if !ok {
+			return nil, p.errorf("expected a mapping key")
+		}
+
+		if _, dup := m[key]; dup {
+			return nil, p.errorf("duplicate key %q", key)
+		}
+
+		value, err := p.mappingValue(rest, indent, depth)
+		if err != nil {
+			return nil, err
+		}
+
+		m[key] = value
+	}
+
+	return m, p.checkDedent(indent)
+}
+
+func (p *yamlParser) mappingValue(rest string, indent, depth int) (any, error) {
+	switch {
+	case rest == "":
+		p.pos++
+		p.skipBlank()
+
+		// Sequences may be written at the same indentation as their key.
+		if p.pos < len(p.lines) && indentOf(p.lines[p.pos]) == indent && isSequenceItem(p.current()) {
+			return p.sequence(indent, depth+1)
+		}
+
+		return p.nested(indent, depth)
+	case strings.HasPrefix(rest, "|") || strings.HasPrefix(rest, ">"):
+		return p.blockScalar(rest, indent)
+	default:
+		value, err := parseYAMLInline(rest)
+		if err != nil {
+			return nil, p.errorf("%v", err)
+		}
+
+		p.pos++
+
+		return value, nil
+	}
+}
+
+// nested parses a child block indented deeper than parent or returns null if there is none.
+func (p *yamlParser) nested(parent, depth int) (any, error) {
+	p.skipBlank()
+
+	if p.pos >= len(p.lines) {
+		return nil, nil
+	}
+
+	indent := indentOf(p.lines[p.pos])
+	if indent <= parent {
+		return nil, nil
+	}
+
+	return p.block(indent, depth+1)
+}
+
+// checkDedent fails if a collection ended on a line indented deeper than itself.
+func (p *yamlParser) checkDedent(indent int) error {
+	if p.pos < len(p.lines) && indentOf(p.lines[p.pos]) > indent {
+		return p.errorf("bad indentation")
+	}
+
+	return nil
+}
+
+//nolint:cyclop,funlen
+func (p *yamlParser) blockScalar(header string, parent int) (any, error) {
+	style := header[0]
+	chomp := byte(0)
+	explicit := 0
+
+	for _, c := range header[1:] {
+		switch {
+		case c == '-' || c == '+':
+			chomp = byte(c)
+		case c >= '1' && c <= '9':
+			explicit = int(c - '0')
+		default:
+			return nil, p.errorf("invalid block scalar header %q", header)
+		}
+	}
+
+	p.pos++
+
+	var lines []string
+
+	contentIndent := 0
+	if explicit > 0 {
+		contentIndent = parent + explicit
+	}
+
+	for p.pos < len(p.lines) {
+		line := p.lines[p.pos]
+
+		if strings.TrimSpace(line) == "" {
+			lines = append(lines, "")
+			p.pos++
+
+			continue
+		}
+
+		ind := indentOf(line)
+		if contentIndent == 0 {
+			contentIndent = ind
+		}
+
+		if ind <= parent || ind < contentIndent {
+			break
+		}
+
+		lines = append(lines, line[contentIndent:])
+		p.pos++
+	}
+
+	if contentIndent <= parent && len(lines) > 0 && strings.Join(lines, "") != "" {
+		return nil, p.errorf("bad block scalar indentation")
+	}
+
+	// Trailing blank lines are subject to chomping.
+	trailing := 0
+	for trailing < len(lines) && lines[len(lines)-1-trailing] == "" {
+		trailing++
+	}
+
+	body := lines[:len(lines)-trailing]
+
+	var text string
+	if style == '|' {
+		text = strings.Join(body, "\n")
+	} else {
+		text = foldLines(body)
+	}
+
+	if len(body) == 0 {
+		text = ""
+	}
+
+	switch chomp {
+	case '-':
+	case '+':
+		if len(body) > 0 {
+			text += "\n"
+		}
+
+		text += strings.Repeat("\n", trailing)
+	default:
+		if len(body) > 0 {
+			text += "\n"
+		}
+	}
+
+	return text, nil
+}
+
+// foldLines joins lines of a folded scalar: single line breaks become spaces,
+// each empty line becomes a line break and more-indented lines are kept as is.
+func foldLines(lines []string) string {
+	var b strings.Builder
+
+	for i, line := range lines {
+		if i > 0 {
+			prev := lines[i-1]
+
+			switch {
+			case line == "":
+				b.WriteByte('\n')
+			case prev == "":
+				// The break was already written for the empty lines.
+			case strings.HasPrefix(line, " ") || strings.HasPrefix(prev, " "):
+				b.WriteByte('\n')
+			default:
+				b.WriteByte(' ')
+			}
+		}
+
+		b.WriteString(line)
+	}
+
+	return b.String()
+}
+
+// stripComment removes a trailing comment that is not inside a quoted scalar.
+func stripComment(s string) string {
+	var quote byte
+
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+
+		switch {
+		case quote != 0:
+			if c == '\\' && quote == '"' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			if i == 0 || strings.IndexByte(" [{,:", s[i-1]) >= 0 {
+				quote = c
+			}
+		case c == '#' && (i == 0 || s[i-1] == ' '):
+			return s[:i]
+		}
+	}
+
+	return s
+}
+
+// splitKey splits "key: value" into its parts. ok is false if content is not a mapping entry.
+func splitKey(content string) (string, string, bool, error) {
+	if content == "" {
+		return "", "", false, nil
+	}
+
+	if content[0] == '"' || content[0] == '\'' {
+		key, n, err := parseQuoted(content)
+		if err != nil {
+			return "", "", false, err
+		}
+
+		rest := strings.TrimLeft(content[n:], " ")
+		if !strings.HasPrefix(rest, ":") || (len(rest) > 1 && rest[1] != ' ') {
+			return "", "", false, nil
+		}
+
+		return key, strings.TrimSpace(rest[1:]), true, nil
+	}
+
+	if strings.ContainsAny(content[:1], "[{") {
+		return "", "", false, nil
+	}
+
+	for i := 0; i < len(content); i++ {
+		if content[i] == ':' && (i+1 == len(content) || content[i+1] == ' ') {
+			return strings.TrimSpace(content[:i]), strings.TrimSpace(content[i+1:]), true, nil
+		}
+	}
+
+	return "", "", false, nil
+}
+
+// parseYAMLInline parses a scalar or flow collection occupying the rest of a line.
+func parseYAMLInline(s string) (any, error) {
+	if s == "" {
+		return nil, nil
+	}
+
+	switch s[0] {
+	case '"', '\'':
+		value, n, err := parseQuoted(s)
+		if err != nil {
+			return nil, err
+		}
+
+		if strings.TrimSpace(s[n:]) != "" {
+			return nil, errors.New("unexpected text after quoted scalar")
+		}
+
+		return value, nil
+	case '[', '{':
+		f := &flowParser{s: s}
+
+		value, err := f.value(0)
+		if err != nil {
+			return nil, err
+		}
+
+		if f.skipSpace(); f.pos != len(f.s) {
+			return nil, errors.New("unexpected text after flow collection")
+		}
+
+		return value, nil
+	case '&', '*', '!', '|', '>', '@', '`':
+		return nil, fmt.Errorf("unsupported scalar %q", s)
+	default:
+		return parseYAMLPlain(s), nil
+	}
+}
+
+// parseYAMLPlain resolves a plain scalar using the YAML 1.2 core schema.
+func parseYAMLPlain(s string) any {
+	switch s {
+	case "", "~", "null", "Null", "NULL":
+		return nil
+	case "true", "True", "TRUE":
+		return true
+	case "false", "False", "FALSE":
+		return false
+	}
+
+	if yamlInt.MatchString(s) {
+		if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return json.Number(strconv.FormatInt(i, 10))
+		}
+	}
+
+	if strings.HasPrefix(s, "0x") || strings.HasPrefix(s, "0o") {
+		if i, err := strconv.ParseInt(s, 0, 64); err == nil {
+			return json.Number(strconv.FormatInt(i, 10))
+		}
+	}
+
+	if yamlFloat.MatchString(s) {
+		if f, err := strconv.ParseFloat(s, 64); err == nil && !math.IsInf(f, 0) {
+			return json.Number(strconv.FormatFloat(f, 'g', -1, 64))
+		}
+	}
+
+	return s
+}
+
+// parseQuoted parses a single or double quoted scalar at the start of s
+// and returns its value and the number of bytes consumed.
+func parseQuoted(s string) (string, int, error) {
+	quote := s[0]
+
+	var b strings.Builder
+
+	for i := 1; i < len(s); i++ {
+		c := s[i]
+
+		switch {
+		case c == quote && quote == '\'' && i+1 < len(s) && s[i+1] == '\'':
+			b.WriteByte('\'')
+			i++
+		case c == quote:
+			return b.String(), i + 1, nil
+		case c == '\\' && quote == '"':
+			n, err := unescapeYAML(&b, s[i+1:])
+			if err != nil {
+				return "", 0, err
+			}
+
+			i += n
+		default:
+			b.WriteByte(c)
+		}
+	}
+
+	return "", 0, errors.New("unterminated quoted scalar")
+}
+
+// unescapeYAML writes the character for the escape sequence at the start of s
+// and returns the number of bytes consumed.
+//
+//nolint:cyclop
+func unescapeYAML(b *strings.Builder, s string) (int, error) {
+	if s == "" {
+		return 0, errors.New("invalid escape sequence")
+	}
+
+	simple := map[byte]string{
+		'0': "\x00", 'a': "\a", 'b': "\b", 't': "\t", '\t': "\t", 'n': "\n", 'v': "\v",
+		'f': "\f", 'r': "\r", 'e': "\x1b", ' ': " ", '"': `"`, '/': "/", '\\': `\`,
+		'N': "\u0085", '_': " ", 'L': " ", 'P': " ",
+	}
+
+	if v, ok := simple[s[0]]; ok {
+		b.WriteString(v)
+
+		return 1, nil
+	}
+
+	size := map[byte]int{'x': 2, 'u': 4, 'U': 8}[s[0]]
+	if size == 0 || len(s) < size+1 {
+		return 0, fmt.Errorf("invalid escape sequence \\%c", s[0])
+	}
+
+	code, err := strconv.ParseUint(s[1:size+1], 16, 32)
+	if err != nil || !utf8.ValidRune(rune(code)) {
+		return 0, fmt.Errorf("invalid escape sequence \\%s", s[:size+1])
+	}
+
+	b.WriteRune(rune(code))
+
+	return size + 1, nil
+}
+
+// flowParser parses flow collections such as [a, b] and {key: value}.
+type flowParser struct {
+	s   string
+	pos int
+}
+
+func (f *flowParser) skipSpace() {
+	for f.pos < len(f.s) && f.s[f.pos] == ' ' {
+		f.pos++
+	}
+}
+
+func (f *flowParser) value(depth int) (any, error) {
+	if depth > maxDepth {
+		return nil, errTooDeep
+	}
+
+	f.skipSpace()
+
+	if f.pos >= len(f.s) {
+		return nil, errors.New("unterminated flow collection")
+	}
+
+	switch f.s[f.pos] {
+	case '[':
+		return f.sequence(depth)
+	case '{':
+		return f.mapping(depth)
+	case '"', '\'':
+		value, n, err := parseQuoted(f.s[f.pos:])
+		f.pos += n
+
+		return value, err
+	default:
+		start := f.pos
+		for f.pos < len(f.s) && !strings.ContainsRune(",]}", rune(f.s[f.pos])) &&
+			(f.s[f.pos] != ':' || f.pos+1 < len(f.s) && f.s[f.pos+1] != ' ') {
+			f.pos++
+		}
+
+		return parseYAMLPlain(strings.TrimSpace(f.s[start:f.pos])), nil
+	}
+}
+
+func (f *flowParser) sequence(depth int) (any, error) {
+	f.pos++
+
+	items := make([]any, 0)
+
+	for {
+		f.skipSpace()
+
+		if f.pos < len(f.s) && f.s[f.pos] == ']' {
+			f.pos++
+
+			return items, nil
+		}
+
+		item, err := f.value(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+
+		items = append(items, item)
+
+		if err := f.separator(']'); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (f *flowParser) mapping(depth int) (any, error) {
+	f.pos++
+
+	m := make(map[string]any)
+
+	for {
+		f.skipSpace()
+
+		if f.pos < len(f.s) && f.s[f.pos] == '}' {
+			f.pos++
+
+			return m, nil
+		}
+
+		key, err := f.value(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+
+		f.skipSpace()
+
+		if f.pos >= len(f.s) || f.s[f.pos] != ':' {
+			return nil, errors.New("expected ':' in flow mapping")
+		}
+
+		f.pos++
+
+		value, err := f.value(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+
+		m[fmt.Sprint(key)] = value
+
+		if err := f.separator('}'); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// separator consumes a comma, leaving the closing bracket for the caller.
+func (f *flowParser) separator(closing byte) error {
+	f.skipSpace()
+
+	switch {
+	case f.pos < len(f.s) && f.s[f.pos] == ',':
+		f.pos++
+
+		return nil
+	case f.pos < len(f.s) && f.s[f.pos] == closing:
+		return nil
+	default:
+		return errors.New("expected ',' in flow collection")
+	}
+}
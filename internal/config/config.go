@@ -1,25 +1,119 @@
-// Package config provides application configuration loading.
+// Package config provides application configuration loading from defaults,
+// a JSON config file, environment variables and command-line flags.
 package config
 
 import (
 	"errors"
+	"fmt"
+	"maps"
+	"math"
+	"net/netip"
+	"net/url"
 	"os"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 )
 
 // Config contains all application configuration.
 type Config struct {
-	Server  *ServerConfig
-	Storage *StorageConfig
-	Logger  *LoggerConfig
+	Server      *ServerConfig
+	Storage     *StorageConfig
+	Logger      *LoggerConfig
+	Calendar    *CalendarConfig
+	Validation  *ValidationConfig
+	Tracing     *TracingConfig
+	RateLimit   *RateLimitConfig
+	CORS        *CORSConfig
+	Compression *CompressionConfig
+	AccessLog   *AccessLogConfig
+	GraphQL     *GraphQLConfig
+
+	// values holds the effective setting values by environment variable name.
+	values map[string]string
 }
 
 // ServerConfig contains HTTP server settings.
 type ServerConfig struct {
-	Port         string
+	// Port is the TCP port of the API; empty disables the TCP listener when
+	// the API is served on a Unix socket or on activated sockets instead.
+	Port      string
+	AdminPort string // empty disables the admin listener
+	// AdminAddress is the host the admin listener binds to; it exposes
+	// profiling and the configuration, so it defaults to loopback. Other
+	// addresses require AdminToken or client certificates.
+	AdminAddress string
+	// AdminToken is the bearer token required by the admin listener; empty
+	// leaves it unauthenticated.
+	AdminToken   string
 	ReadTimeout  time.Duration
 	WriteTimeout time.Duration
 	IdleTimeout  time.Duration
+	// RouteWriteTimeouts overrides WriteTimeout by route pattern; zero lets
+	// streamed responses, such as GET /export, take as long as they need.
+	RouteWriteTimeouts map[string]time.Duration
+
+	// UnixSocket is the path of a Unix domain socket the API also listens on;
+	// a stale socket left at the path is removed. UnixSocketMode sets its
+	// permissions, e.g. 0660 for access by the group of a reverse proxy.
+	UnixSocket     string
+	UnixSocketMode os.FileMode
+	// SocketActivation serves the sockets passed by systemd through
+	// LISTEN_FDS in addition to the other listeners.
+	SocketActivation bool
+
+	// HTTP2 negotiates HTTP/2 over TLS; HTTP2Cleartext additionally accepts
+	// HTTP/2 with prior knowledge (h2c) on plain listeners of internal networks.
+	HTTP2          bool
+	HTTP2Cleartext bool
+	// HTTP2MaxConcurrentStreams limits the streams a client may open on one
+	// connection; HTTP2MaxReadFrameSize is the largest frame read, in bytes.
+	HTTP2MaxConcurrentStreams int
+	HTTP2MaxReadFrameSize     int
+	// HTTP2StreamWindow and HTTP2ConnectionWindow are the flow control windows
+	// for request data in bytes: how much a client may send on a stream and on
+	// the whole connection before the server has read it. They are receive
+	// windows only; responses are sent within the windows the client grants.
+	HTTP2StreamWindow     int
+	HTTP2ConnectionWindow int
+
+	// HandlerTimeout bounds the time a handler has to serve a request; its
+	// deadline is propagated through the request context to the storage.
+	// RouteTimeouts overrides it by route pattern, e.g. "POST /import". Zero
+	// disables the deadline.
+	HandlerTimeout time.Duration
+	RouteTimeouts  map[string]time.Duration
+	// MaxBodySize limits request bodies in bytes; larger ones are rejected
+	// with 413. RouteMaxBodySizes overrides it by route pattern. Zero
+	// disables the limit.
+	MaxBodySize       int64
+	RouteMaxBodySizes map[string]int64
+
+	// ShutdownDelay is the time between failing readiness and closing the
+	// listeners, letting load balancers stop routing new requests.
+	ShutdownDelay time.Duration
+	// ShutdownTimeout bounds draining in-flight requests; connections still
+	// open after it are closed and the process exits with a non-zero code.
+	// Zero waits for all requests to finish.
+	ShutdownTimeout time.Duration
+
+	// TLSCertFile and TLSKeyFile enable HTTPS on Port; both files are reloaded when rotated.
+	TLSCertFile string
+	TLSKeyFile  string
+	// TLSClientCAFile is the CA bundle used to verify client certificates.
+	TLSClientCAFile string
+	// TLSClientAuth is "none", "optional" or "require".
+	TLSClientAuth     string
+	TLSReloadInterval time.Duration
+	// RedirectPort serves redirects from HTTP to HTTPS; empty disables it.
+	RedirectPort string
+
+	// GRPCPort serves the gRPC API, over TLS when a certificate is
+	// configured and over h2c otherwise; empty disables it.
+	GRPCPort string
+	// GRPCMaxMessageSize limits request messages in bytes.
+	GRPCMaxMessageSize int
 }
 
 // StorageConfig contains data storage settings.
@@ -31,84 +125,650 @@ type StorageConfig struct {
 type LoggerConfig struct {
 	Type  string
 	Level string
+	// ComponentLevels overrides Level for loggers of a component, e.g. database:debug.
+	ComponentLevels map[string]string
+	// Format is "json" or "text".
+	Format string
+	// Outputs lists the sinks: "stdout", "stderr", "file" and "syslog".
+	Outputs []string
+
+	// File is the path of the log file; it is rotated once it exceeds FileMaxSize megabytes.
+	File           string
+	FileMaxSize    int
+	FileMaxAge     time.Duration // zero keeps rotated files regardless of age
+	FileMaxBackups int           // zero keeps any number of rotated files
+	// SyslogAddress is the local syslog socket.
+	SyslogAddress string
+
+	// SamplingInitial records with the same level and message are logged each
+	// second, then every SamplingThereafter-th; zero SamplingInitial disables sampling.
+	SamplingInitial    int
+	SamplingThereafter int
+	// RedactKeys lists attribute keys whose values are masked, matched
+	// case-insensitively with dashes and underscores treated alike.
+	RedactKeys []string
+}
+
+// CalendarConfig contains iCalendar feed settings.
+type CalendarConfig struct {
+	// FeedTokens maps a user name to the secret token of their feed URL.
+	FeedTokens map[string]string
+	// UIDDomain identifies this instance in the UIDs of exported items, so
+	// imports tell its items from those of other instances.
+	UIDDomain string
+}
+
+// ValidationConfig contains limits for ToDo payloads.
+type ValidationConfig struct {
+	MaxCaptionLength   int
+	MaxDescriptionSize int
+	MaxDueIn           time.Duration
+}
+
+// TracingConfig contains distributed tracing settings.
+type TracingConfig struct {
+	// Endpoint is the OTLP/HTTP traces URL; empty disables export but keeps propagation.
+	Endpoint       string
+	ServiceName    string
+	ExportInterval time.Duration
+}
+
+// RateLimitConfig contains per-client request limits.
+type RateLimitConfig struct {
+	// Key selects how clients are identified: "ip", "api_key" or "user".
+	Key string
+	// ReadRate and WriteRate are requests per second; zero disables the limit.
+	ReadRate       float64
+	ReadBurst      int
+	WriteRate      float64
+	WriteBurst     int
+	IdleTTL        time.Duration
+	MaxClients     int
+	TrustedProxies []netip.Prefix
+}
+
+// CORSConfig contains the cross-origin resource sharing policy.
+type CORSConfig struct {
+	// AllowedOrigins lists origins like https://app.example.com, https://*.example.com or *.
+	AllowedOrigins   []string
+	AllowedMethods   []string
+	AllowedHeaders   []string
+	ExposedHeaders   []string
+	AllowCredentials bool
+	MaxAge           time.Duration
+}
+
+// CompressionConfig contains response compression settings.
+type CompressionConfig struct {
+	// MinSize is the minimal response size in bytes worth compressing.
+	MinSize int
+	// Level is the gzip/deflate level from -2 (Huffman only) to 9; -1 is the default.
+	Level int
+}
+
+// AccessLogConfig contains request log settings.
+type AccessLogConfig struct {
+	// Format is "json", "common" or "combined" (Apache log formats).
+	Format string
+	// Output is "stdout", "stderr" or a file path rotated like the log file.
+	// Empty Output writes JSON records through the application logger.
+	Output string
 }
 
+// GraphQLConfig contains the limits of GraphQL queries, checked before they run.
+type GraphQLConfig struct {
+	// MaxDepth is the deepest nesting of selected fields.
+	MaxDepth int
+	// MaxComplexity bounds the number of fields a query may resolve, counting
+	// the fields of every requested page item.
+	MaxComplexity int
+}
+
+// minFeedTokenLength is the minimal length of a calendar feed token.
+const minFeedTokenLength = 16
+
+// streamingRoutes are the default route timeouts of the routes streaming
+// their responses: neither the write nor the handler deadline applies.
+const streamingRoutes = "GET /export:0s,GET /todos.ics:0s"
+
 // Configuration validation errors.
 var (
-	ErrEmptyPort           = errors.New("port cannot be empty")
+	ErrEmptyPort           = errors.New("port cannot be empty without a unix socket or socket activation")
+	ErrInvalidUnixSocket   = errors.New("unix socket mode must be a permission between 0000 and 0777")
+	ErrInvalidAdminPort    = errors.New("admin port must differ from port")
+	ErrInsecureAdmin       = errors.New("admin address must be loopback unless an admin token or tls client certificates are required")
+	ErrIncompleteTLS       = errors.New("tls cert and key files must be set together")
+	ErrInvalidClientAuth   = errors.New("tls client auth must be none, optional or require")
+	ErrMissingClientCA     = errors.New("tls client auth requires a client CA file")
+	ErrInvalidRedirectPort = errors.New("redirect port requires tls on a tcp port and must differ from other ports")
+	ErrInvalidGRPC         = errors.New("grpc port must differ from other ports and max message size be positive")
+	ErrInvalidTLSReload    = errors.New("tls reload interval must be positive")
 	ErrInvalidReadTimeout  = errors.New("read_timeout must be positive")
 	ErrInvalidWriteTimeout = errors.New("write_timeout must be positive")
 	ErrInvalidIdleTimeout  = errors.New("idle_timeout must be positive")
+	ErrInvalidHTTP2        = errors.New("http2 settings out of range or h2c combined with tls")
+	ErrInvalidRouteLimit   = errors.New("route limits must be METHOD /path pairs with non-negative values")
+	ErrInvalidShutdown     = errors.New("shutdown delay and timeout must not be negative")
 	ErrInvalidLogLevel     = errors.New("invalid log level")
+	ErrInvalidLogFormat    = errors.New("log format must be json or text")
+	ErrInvalidLogOutput    = errors.New("log output must be stdout, stderr, file or syslog")
+	ErrInvalidLogFile      = errors.New("log file output requires a path and non-negative limits")
+	ErrInvalidLogSampling  = errors.New("log sampling values must not be negative")
+	ErrInvalidFeedTokens   = errors.New("calendar feed tokens must be user:token pairs")
+	ErrWeakFeedToken       = errors.New("calendar feed token is too short")
+	ErrInvalidUIDDomain    = errors.New("calendar uid domain must be non-empty and contain no @ or spaces")
+	ErrInvalidValidation   = errors.New("validation limits must be positive")
+	ErrInvalidTracing      = errors.New("tracing endpoint must be an http(s) URL and export interval positive")
+	ErrInvalidRateLimit    = errors.New("rate limits must not be negative and bursts must be positive")
+	ErrInvalidRateLimitKey = errors.New("rate limit key must be ip, api_key or user")
+	ErrInvalidCORSOrigin   = errors.New("cors origin must be *, scheme://host[:port] or scheme://*.domain")
+	ErrCORSCredentials     = errors.New("cors credentials cannot be allowed for any origin")
+	ErrInvalidCompression  = errors.New("compression min size must not be negative and level within [-2, 9]")
+	ErrInvalidGraphQL      = errors.New("graphql max depth and max complexity must be positive")
+	ErrInvalidAccessLog    = errors.New("access log format must be json, common or combined; common and combined require an output")
 )
 
-// Load loads configuration from environment variables.
+// Load loads configuration from the file in CONFIG_FILE and environment variables.
 func Load() (*Config, error) {
-	server, err := loadServerConfig()
+	cfg, _, err := Parse(os.Args[0], nil)
+
+	return cfg, err
+}
+
+func load(l *loader) (*Config, error) {
+	server, err := loadServerConfig(l)
+	if err != nil {
+		return nil, err
+	}
+
+	storage, err := loadStorageConfig(l)
+	if err != nil {
+		return nil, err
+	}
+
+	logger, err := loadLoggerConfig(l)
+	if err != nil {
+		return nil, err
+	}
+
+	calendar, err := loadCalendarConfig(l)
+	if err != nil {
+		return nil, err
+	}
+
+	validation, err := loadValidationConfig(l)
+	if err != nil {
+		return nil, err
+	}
+
+	tracing, err := loadTracingConfig(l)
+	if err != nil {
+		return nil, err
+	}
+
+	rateLimit, err := loadRateLimitConfig(l)
 	if err != nil {
 		return nil, err
 	}
 
-	storage, err := loadStorageConfig()
+	cors, err := loadCORSConfig(l)
 	if err != nil {
 		return nil, err
 	}
 
-	logger, err := loadLoggerConfig()
+	compression, err := loadCompressionConfig(l)
+	if err != nil {
+		return nil, err
+	}
+
+	accessLog, err := loadAccessLogConfig(l)
+	if err != nil {
+		return nil, err
+	}
+
+	graphQL, err := loadGraphQLConfig(l)
 	if err != nil {
 		return nil, err
 	}
 
 	cfg := &Config{
-		Server:  server,
-		Storage: storage,
-		Logger:  logger,
+		Server:      server,
+		Storage:     storage,
+		Logger:      logger,
+		Calendar:    calendar,
+		Validation:  validation,
+		Tracing:     tracing,
+		RateLimit:   rateLimit,
+		CORS:        cors,
+		Compression: compression,
+		AccessLog:   accessLog,
+		GraphQL:     graphQL,
+		values:      l.effective,
 	}
 
 	return cfg, nil
 }
 
-func loadServerConfig() (*ServerConfig, error) {
-	port := getEnv("PORT", "8080")
+func loadServerConfig(l *loader) (*ServerConfig, error) {
+	readTimeout, err := l.duration("READ_TIMEOUT", "10s")
+	if err != nil {
+		return nil, err
+	}
 
-	readTimeout, err := time.ParseDuration(getEnv("READ_TIMEOUT", "10s"))
+	writeTimeout, err := l.duration("WRITE_TIMEOUT", "10s")
 	if err != nil {
 		return nil, err
 	}
 
-	writeTimeout, err := time.ParseDuration(getEnv("WRITE_TIMEOUT", "10s"))
+	idleTimeout, err := l.duration("IDLE_TIMEOUT", "60s")
 	if err != nil {
 		return nil, err
 	}
 
-	idleTimeout, err := time.ParseDuration(getEnv("IDLE_TIMEOUT", "60s"))
+	tlsReloadInterval, err := l.duration("TLS_RELOAD_INTERVAL", "30s")
+	if err != nil {
+		return nil, err
+	}
+
+	shutdownDelay, err := l.duration("SHUTDOWN_DELAY", "0s")
+	if err != nil {
+		return nil, err
+	}
+
+	shutdownTimeout, err := l.duration("SHUTDOWN_TIMEOUT", "30s")
+	if err != nil {
+		return nil, err
+	}
+
+	unixSocketMode, err := strconv.ParseUint(l.get("UNIX_SOCKET_MODE", "0660"), 8, 32)
+	if err != nil {
+		return nil, fmt.Errorf("UNIX_SOCKET_MODE: %w", err)
+	}
+
+	socketActivation, err := l.bool("SOCKET_ACTIVATION", "false")
+	if err != nil {
+		return nil, err
+	}
+
+	http2, err := l.bool("HTTP2", "true")
+	if err != nil {
+		return nil, err
+	}
+
+	http2Cleartext, err := l.bool("HTTP2_CLEARTEXT", "false")
+	if err != nil {
+		return nil, err
+	}
+
+	http2MaxConcurrentStreams, err := l.int("HTTP2_MAX_CONCURRENT_STREAMS", "250")
+	if err != nil {
+		return nil, err
+	}
+
+	http2MaxReadFrameSize, err := l.int("HTTP2_MAX_READ_FRAME_SIZE", "1048576")
+	if err != nil {
+		return nil, err
+	}
+
+	http2StreamWindow, err := l.int("HTTP2_STREAM_WINDOW", "1048576")
+	if err != nil {
+		return nil, err
+	}
+
+	http2ConnectionWindow, err := l.int("HTTP2_CONNECTION_WINDOW", "1048576")
+	if err != nil {
+		return nil, err
+	}
+
+	grpcMaxMessageSize, err := l.int("GRPC_MAX_MESSAGE_SIZE", "4194304")
+	if err != nil {
+		return nil, err
+	}
+
+	handlerTimeout, err := l.duration("HANDLER_TIMEOUT", "10s")
+	if err != nil {
+		return nil, err
+	}
+
+	routeTimeouts, err := routeValues(l, "ROUTE_TIMEOUTS", streamingRoutes, time.ParseDuration)
+	if err != nil {
+		return nil, err
+	}
+
+	routeWriteTimeouts, err := routeValues(l, "ROUTE_WRITE_TIMEOUTS", streamingRoutes, time.ParseDuration)
+	if err != nil {
+		return nil, err
+	}
+
+	maxBodySize, err := l.int("MAX_BODY_SIZE", "1048576")
+	if err != nil {
+		return nil, err
+	}
+
+	routeMaxBodySizes, err := routeValues(l, "ROUTE_MAX_BODY_SIZES",
+		"POST /import:33554432,POST /import/ics:33554432", func(v string) (int64, error) {
+			return strconv.ParseInt(v, 10, 64)
+		})
 	if err != nil {
 		return nil, err
 	}
 
 	return &ServerConfig{
-		Port:         port,
-		ReadTimeout:  readTimeout,
-		WriteTimeout: writeTimeout,
-		IdleTimeout:  idleTimeout,
+		Port:                      l.get("PORT", "8080"),
+		AdminPort:                 l.get("ADMIN_PORT", "9090"),
+		AdminAddress:              l.get("ADMIN_ADDRESS", "127.0.0.1"),
+		AdminToken:                l.get("ADMIN_TOKEN", ""),
+		ReadTimeout:               readTimeout,
+		WriteTimeout:              writeTimeout,
+		IdleTimeout:               idleTimeout,
+		RouteWriteTimeouts:        routeWriteTimeouts,
+		UnixSocket:                l.get("UNIX_SOCKET", ""),
+		UnixSocketMode:            os.FileMode(unixSocketMode),
+		SocketActivation:          socketActivation,
+		HTTP2:                     http2,
+		HTTP2Cleartext:            http2Cleartext,
+		HTTP2MaxConcurrentStreams: http2MaxConcurrentStreams,
+		HTTP2MaxReadFrameSize:     http2MaxReadFrameSize,
+		HTTP2StreamWindow:         http2StreamWindow,
+		HTTP2ConnectionWindow:     http2ConnectionWindow,
+		HandlerTimeout:            handlerTimeout,
+		RouteTimeouts:             routeTimeouts,
+		MaxBodySize:               int64(maxBodySize),
+		RouteMaxBodySizes:         routeMaxBodySizes,
+		ShutdownDelay:             shutdownDelay,
+		ShutdownTimeout:           shutdownTimeout,
+		TLSCertFile:               l.get("TLS_CERT_FILE", ""),
+		TLSKeyFile:                l.get("TLS_KEY_FILE", ""),
+		TLSClientCAFile:           l.get("TLS_CLIENT_CA_FILE", ""),
+		TLSClientAuth:             l.get("TLS_CLIENT_AUTH", "none"),
+		TLSReloadInterval:         tlsReloadInterval,
+		RedirectPort:              l.get("REDIRECT_PORT", ""),
+		GRPCPort:                  l.get("GRPC_PORT", ""),
+		GRPCMaxMessageSize:        grpcMaxMessageSize,
 	}, nil
 }
 
 //nolint:unparam
-func loadStorageConfig() (*StorageConfig, error) {
+func loadStorageConfig(l *loader) (*StorageConfig, error) {
 	return &StorageConfig{
-		Type: getEnv("STORAGE_TYPE", "mem"),
+		Type: l.get("STORAGE_TYPE", "mem"),
 	}, nil
 }
 
-//nolint:unparam
-func loadLoggerConfig() (*LoggerConfig, error) {
+func loadLoggerConfig(l *loader) (*LoggerConfig, error) {
+	componentLevels := make(map[string]string)
+
+	for _, pair := range splitList(l.get("LOG_COMPONENT_LEVELS", "")) {
+		component, level, ok := strings.Cut(pair, ":")
+		if !ok || component == "" {
+			return nil, fmt.Errorf("LOG_COMPONENT_LEVELS: %w: %q", ErrInvalidLogLevel, pair)
+		}
+
+		componentLevels[strings.TrimSpace(component)] = strings.TrimSpace(level)
+	}
+
+	fileMaxSize, err := l.int("LOG_FILE_MAX_SIZE", "100")
+	if err != nil {
+		return nil, err
+	}
+
+	fileMaxAge, err := l.duration("LOG_FILE_MAX_AGE", "168h")
+	if err != nil {
+		return nil, err
+	}
+
+	fileMaxBackups, err := l.int("LOG_FILE_MAX_BACKUPS", "5")
+	if err != nil {
+		return nil, err
+	}
+
+	samplingInitial, err := l.int("LOG_SAMPLING_INITIAL", "0")
+	if err != nil {
+		return nil, err
+	}
+
+	samplingThereafter, err := l.int("LOG_SAMPLING_THEREAFTER", "100")
+	if err != nil {
+		return nil, err
+	}
+
 	return &LoggerConfig{
-		Type:  getEnv("LOGGER_TYPE", "std"),
-		Level: getEnv("LOG_LEVEL", "info"),
+		Type:            l.get("LOGGER_TYPE", "std"),
+		Level:           l.get("LOG_LEVEL", "info"),
+		ComponentLevels: componentLevels,
+		Format:          l.get("LOG_FORMAT", "json"),
+		Outputs:         splitList(l.get("LOG_OUTPUTS", "stdout")),
+		File:            l.get("LOG_FILE", ""),
+		FileMaxSize:     fileMaxSize,
+		FileMaxAge:      fileMaxAge,
+		FileMaxBackups:  fileMaxBackups,
+		SyslogAddress:   l.get("LOG_SYSLOG_ADDRESS", "/dev/log"),
+
+		SamplingInitial:    samplingInitial,
+		SamplingThereafter: samplingThereafter,
+		RedactKeys: splitList(l.get("LOG_REDACT_KEYS",
+			"authorization,cookie,set-cookie,x-api-key,api_key,token,password")),
+	}, nil
+}
+
+func loadCalendarConfig(l *loader) (*CalendarConfig, error) {
+	tokens := make(map[string]string)
+
+	for pair := range strings.SplitSeq(l.get("CALENDAR_FEED_TOKENS", ""), ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		user, token, ok := strings.Cut(pair, ":")
+		if !ok || user == "" || token == "" {
+			return nil, ErrInvalidFeedTokens
+		}
+
+		tokens[user] = token
+	}
+
+	return &CalendarConfig{
+		FeedTokens: tokens,
+		UIDDomain:  l.get("CALENDAR_UID_DOMAIN", "ecom-internship"),
+	}, nil
+}
+
+func loadValidationConfig(l *loader) (*ValidationConfig, error) {
+	maxCaptionLength, err := l.int("MAX_CAPTION_LENGTH", "200")
+	if err != nil {
+		return nil, err
+	}
+
+	maxDescriptionSize, err := l.int("MAX_DESCRIPTION_SIZE", "4096")
+	if err != nil {
+		return nil, err
+	}
+
+	maxDueIn, err := l.duration("MAX_DUE_IN", "87600h")
+	if err != nil {
+		return nil, err
+	}
+
+	return &ValidationConfig{
+		MaxCaptionLength:   maxCaptionLength,
+		MaxDescriptionSize: maxDescriptionSize,
+		MaxDueIn:           maxDueIn,
 	}, nil
 }
 
+func loadTracingConfig(l *loader) (*TracingConfig, error) {
+	exportInterval, err := l.duration("TRACING_EXPORT_INTERVAL", "5s")
+	if err != nil {
+		return nil, err
+	}
+
+	return &TracingConfig{
+		Endpoint:       l.get("TRACING_ENDPOINT", ""),
+		ServiceName:    l.get("TRACING_SERVICE_NAME", "ecom-internship"),
+		ExportInterval: exportInterval,
+	}, nil
+}
+
+//nolint:cyclop
+func loadRateLimitConfig(l *loader) (*RateLimitConfig, error) {
+	readRate, err := l.float("RATE_LIMIT_READ_RPS", "50")
+	if err != nil {
+		return nil, err
+	}
+
+	readBurst, err := l.int("RATE_LIMIT_READ_BURST", "100")
+	if err != nil {
+		return nil, err
+	}
+
+	writeRate, err := l.float("RATE_LIMIT_WRITE_RPS", "10")
+	if err != nil {
+		return nil, err
+	}
+
+	writeBurst, err := l.int("RATE_LIMIT_WRITE_BURST", "20")
+	if err != nil {
+		return nil, err
+	}
+
+	idleTTL, err := l.duration("RATE_LIMIT_IDLE_TTL", "10m")
+	if err != nil {
+		return nil, err
+	}
+
+	maxClients, err := l.int("RATE_LIMIT_MAX_CLIENTS", "10000")
+	if err != nil {
+		return nil, err
+	}
+
+	var proxies []netip.Prefix
+
+	for _, cidr := range splitList(l.get("TRUSTED_PROXIES", "")) {
+		prefix, err := parsePrefix(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("TRUSTED_PROXIES: %w", err)
+		}
+
+		proxies = append(proxies, prefix)
+	}
+
+	return &RateLimitConfig{
+		Key:            l.get("RATE_LIMIT_KEY", "ip"),
+		ReadRate:       readRate,
+		ReadBurst:      readBurst,
+		WriteRate:      writeRate,
+		WriteBurst:     writeBurst,
+		IdleTTL:        idleTTL,
+		MaxClients:     maxClients,
+		TrustedProxies: proxies,
+	}, nil
+}
+
+func loadCORSConfig(l *loader) (*CORSConfig, error) {
+	allowCredentials, err := l.bool("CORS_ALLOW_CREDENTIALS", "false")
+	if err != nil {
+		return nil, err
+	}
+
+	maxAge, err := l.duration("CORS_MAX_AGE", "10m")
+	if err != nil {
+		return nil, err
+	}
+
+	return &CORSConfig{
+		AllowedOrigins: splitList(l.get("CORS_ALLOWED_ORIGINS", "")),
+		AllowedMethods: splitList(l.get("CORS_ALLOWED_METHODS", "GET,POST,PUT,DELETE")),
+		AllowedHeaders: splitList(l.get("CORS_ALLOWED_HEADERS",
+			"Accept,Content-Type,X-Request-ID,X-API-Key,traceparent,tracestate")),
+		ExposedHeaders: splitList(l.get("CORS_EXPOSED_HEADERS",
+			"Location,X-Request-ID,RateLimit-Limit,RateLimit-Remaining,RateLimit-Reset,Retry-After")),
+		AllowCredentials: allowCredentials,
+		MaxAge:           maxAge,
+	}, nil
+}
+
+func loadCompressionConfig(l *loader) (*CompressionConfig, error) {
+	minSize, err := l.int("COMPRESSION_MIN_SIZE", "1024")
+	if err != nil {
+		return nil, err
+	}
+
+	level, err := l.int("COMPRESSION_LEVEL", "-1")
+	if err != nil {
+		return nil, err
+	}
+
+	return &CompressionConfig{
+		MinSize: minSize,
+		Level:   level,
+	}, nil
+}
+
+//nolint:unparam
+func loadAccessLogConfig(l *loader) (*AccessLogConfig, error) {
+	return &AccessLogConfig{
+		Format: l.get("ACCESS_LOG_FORMAT", "json"),
+		Output: l.get("ACCESS_LOG_OUTPUT", ""),
+	}, nil
+}
+
+func loadGraphQLConfig(l *loader) (*GraphQLConfig, error) {
+	maxDepth, err := l.int("GRAPHQL_MAX_DEPTH", "10")
+	if err != nil {
+		return nil, err
+	}
+
+	maxComplexity, err := l.int("GRAPHQL_MAX_COMPLEXITY", "1000")
+	if err != nil {
+		return nil, err
+	}
+
+	return &GraphQLConfig{
+		MaxDepth:      maxDepth,
+		MaxComplexity: maxComplexity,
+	}, nil
+}
+
+// splitList splits a comma-separated value dropping empty items.
+func splitList(value string) []string {
+	var res []string
+
+	for item := range strings.SplitSeq(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			res = append(res, item)
+		}
+	}
+
+	return res
+}
+
+// validOrigin reports whether origin is * or an absolute origin without a path.
+func validOrigin(origin string) bool {
+	if origin == "*" {
+		return true
+	}
+
+	u, err := url.Parse(origin)
+
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != "" &&
+		u.Path == "" && u.RawQuery == "" && u.Fragment == ""
+}
+
+// parsePrefix accepts a CIDR or a single address.
+func parsePrefix(s string) (netip.Prefix, error) {
+	if strings.Contains(s, "/") {
+		return netip.ParsePrefix(s)
+	}
+
+	addr, err := netip.ParseAddr(s)
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+
+	return netip.PrefixFrom(addr, addr.BitLen()), nil
+}
+
 func getEnv(key, defaultValue string) string {
 	if value, exists := os.LookupEnv(key); exists {
 		return value
@@ -117,28 +777,272 @@ func getEnv(key, defaultValue string) string {
 	return defaultValue
 }
 
-// Validate provides basic config validation.
+// Validate checks the configuration and reports all problems at once.
+//
+//nolint:cyclop,gocognit
 func (c *Config) Validate() error {
-	if c.Server.Port == "" {
-		return ErrEmptyPort
+	var errs []error
+
+	if c.Server.Port == "" && c.Server.UnixSocket == "" && !c.Server.SocketActivation {
+		errs = append(errs, ErrEmptyPort)
+	}
+
+	if c.Server.UnixSocketMode > os.ModePerm {
+		errs = append(errs, ErrInvalidUnixSocket)
+	}
+
+	if c.Server.AdminPort != "" && c.Server.AdminPort == c.Server.Port {
+		errs = append(errs, ErrInvalidAdminPort)
+	}
+
+	if c.Server.AdminPort != "" && !isLoopback(c.Server.AdminAddress) &&
+		c.Server.AdminToken == "" && !c.Server.AdminMutualTLS() {
+		errs = append(errs, ErrInsecureAdmin)
 	}
 
 	if c.Server.ReadTimeout <= 0 {
-		return ErrInvalidReadTimeout
+		errs = append(errs, ErrInvalidReadTimeout)
 	}
 
 	if c.Server.WriteTimeout <= 0 {
-		return ErrInvalidWriteTimeout
+		errs = append(errs, ErrInvalidWriteTimeout)
 	}
 
 	if c.Server.IdleTimeout <= 0 {
-		return ErrInvalidIdleTimeout
+		errs = append(errs, ErrInvalidIdleTimeout)
+	}
+
+	if !validRouteLimits(c.Server) {
+		errs = append(errs, ErrInvalidRouteLimit)
+	}
+
+	if c.Server.ShutdownDelay < 0 || c.Server.ShutdownTimeout < 0 {
+		errs = append(errs, ErrInvalidShutdown)
 	}
 
+	errs = append(errs, c.Server.validateTLS()...)
+
+	if g := c.Server.GRPCPort; g != "" && (g == c.Server.Port || g == c.Server.AdminPort ||
+		g == c.Server.RedirectPort || c.Server.GRPCMaxMessageSize <= 0) {
+		errs = append(errs, ErrInvalidGRPC)
+	}
+
+	if !c.Server.validHTTP2() {
+		errs = append(errs, ErrInvalidHTTP2)
+	}
+
+	errs = append(errs, c.Logger.validate()...)
+
+	if v := c.Validation; v != nil && (v.MaxCaptionLength <= 0 || v.MaxDescriptionSize <= 0 || v.MaxDueIn <= 0) {
+		errs = append(errs, ErrInvalidValidation)
+	}
+
+	if t := c.Tracing; t != nil && (t.ExportInterval <= 0 || !validEndpoint(t.Endpoint)) {
+		errs = append(errs, ErrInvalidTracing)
+	}
+
+	if rl := c.RateLimit; rl != nil {
+		if rl.ReadRate < 0 || rl.WriteRate < 0 || rl.ReadBurst <= 0 || rl.WriteBurst <= 0 ||
+			rl.IdleTTL <= 0 || rl.MaxClients <= 0 {
+			errs = append(errs, ErrInvalidRateLimit)
+		}
+
+		if rl.Key != "ip" && rl.Key != "api_key" && rl.Key != "user" {
+			errs = append(errs, ErrInvalidRateLimitKey)
+		}
+	}
+
+	if c.CORS != nil {
+		for _, origin := range c.CORS.AllowedOrigins {
+			if !validOrigin(origin) {
+				errs = append(errs, fmt.Errorf("%w: %q", ErrInvalidCORSOrigin, origin))
+			}
+
+			if origin == "*" && c.CORS.AllowCredentials {
+				errs = append(errs, ErrCORSCredentials)
+			}
+		}
+	}
+
+	if cc := c.Compression; cc != nil && (cc.MinSize < 0 || cc.Level < -2 || cc.Level > 9) {
+		errs = append(errs, ErrInvalidCompression)
+	}
+
+	if gc := c.GraphQL; gc != nil && (gc.MaxDepth <= 0 || gc.MaxComplexity <= 0) {
+		errs = append(errs, ErrInvalidGraphQL)
+	}
+
+	if a := c.AccessLog; a != nil {
+		switch a.Format {
+		case "json":
+		case "common", "combined":
+			if a.Output == "" {
+				errs = append(errs, ErrInvalidAccessLog)
+			}
+		default:
+			errs = append(errs, ErrInvalidAccessLog)
+		}
+	}
+
+	if c.Calendar != nil {
+		for _, user := range slices.Sorted(maps.Keys(c.Calendar.FeedTokens)) {
+			if len(c.Calendar.FeedTokens[user]) < minFeedTokenLength {
+				errs = append(errs, fmt.Errorf("%w: user %q", ErrWeakFeedToken, user))
+			}
+		}
+
+		if d := c.Calendar.UIDDomain; d == "" || strings.ContainsAny(d, "@ \t\r\n") {
+			errs = append(errs, ErrInvalidUIDDomain)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (s *ServerConfig) validateTLS() []error {
+	var errs []error
+
+	if (s.TLSCertFile == "") != (s.TLSKeyFile == "") {
+		errs = append(errs, ErrIncompleteTLS)
+	}
+
+	switch s.TLSClientAuth {
+	case "", "none":
+	case "optional", "require":
+		if s.TLSClientCAFile == "" {
+			errs = append(errs, ErrMissingClientCA)
+		}
+	default:
+		errs = append(errs, ErrInvalidClientAuth)
+	}
+
+	if s.TLSCertFile != "" && s.TLSReloadInterval <= 0 {
+		errs = append(errs, ErrInvalidTLSReload)
+	}
+
+	if s.RedirectPort != "" && (s.TLSCertFile == "" || s.Port == "" || s.RedirectPort == s.Port || s.RedirectPort == s.AdminPort) {
+		errs = append(errs, ErrInvalidRedirectPort)
+	}
+
+	return errs
+}
+
+// AdminMutualTLS reports whether the admin listener is served over TLS with
+// required client certificates, as it is when the API requires them.
+func (s *ServerConfig) AdminMutualTLS() bool {
+	return s.TLSCertFile != "" && s.TLSClientAuth == "require"
+}
+
+// isLoopback reports whether host only accepts local connections; empty
+// hosts bind all interfaces.
+func isLoopback(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+
+	addr, err := netip.ParseAddr(host)
+
+	return err == nil && addr.IsLoopback()
+}
+
+// HTTP/2 limits; zero values select the defaults of net/http.
+const (
+	minHTTP2FrameSize  = 16 << 10
+	maxHTTP2FrameSize  = 16 << 20
+	minHTTP2ConnWindow = 64 << 10
+	maxHTTP2Window     = math.MaxInt32
+)
+
+// validHTTP2 checks the HTTP/2 limits against the ranges net/http accepts,
+// which would otherwise silently replace invalid values. Windows may grow to
+// 2^31-1 bytes as the protocol allows.
+func (s *ServerConfig) validHTTP2() bool {
+	inRange := func(v, lo, hi int) bool { return v == 0 || v >= lo && v <= hi }
+
+	if s.HTTP2Cleartext && s.TLSCertFile != "" {
+		return false
+	}
+
+	return s.HTTP2MaxConcurrentStreams >= 0 &&
+		inRange(s.HTTP2MaxReadFrameSize, minHTTP2FrameSize, maxHTTP2FrameSize) &&
+		inRange(s.HTTP2StreamWindow, 1, maxHTTP2Window) &&
+		inRange(s.HTTP2ConnectionWindow, minHTTP2ConnWindow, maxHTTP2Window)
+}
+
+func (l *LoggerConfig) validate() []error {
+	var errs []error
+
 	validLevels := map[string]bool{"debug": true, "info": true, "warn": true, "error": true}
-	if !validLevels[c.Logger.Level] {
-		return ErrInvalidLogLevel
+	if !validLevels[l.Level] {
+		errs = append(errs, fmt.Errorf("%w: %q", ErrInvalidLogLevel, l.Level))
+	}
+
+	for _, component := range slices.Sorted(maps.Keys(l.ComponentLevels)) {
+		if level := l.ComponentLevels[component]; !validLevels[level] {
+			errs = append(errs, fmt.Errorf("%w: %q for component %q", ErrInvalidLogLevel, level, component))
+		}
 	}
 
-	return nil
+	if l.Format != "" && l.Format != "json" && l.Format != "text" {
+		errs = append(errs, ErrInvalidLogFormat)
+	}
+
+	for _, output := range l.Outputs {
+		switch output {
+		case "stdout", "stderr", "syslog":
+		case "file":
+			if l.File == "" || l.FileMaxSize <= 0 || l.FileMaxAge < 0 || l.FileMaxBackups < 0 {
+				errs = append(errs, ErrInvalidLogFile)
+			}
+		default:
+			errs = append(errs, fmt.Errorf("%w: %q", ErrInvalidLogOutput, output))
+		}
+	}
+
+	if l.SamplingInitial < 0 || l.SamplingThereafter < 0 {
+		errs = append(errs, ErrInvalidLogSampling)
+	}
+
+	return errs
+}
+
+// validRouteLimits reports whether the handler and write timeouts and body size
+// limits are non-negative and keyed by "METHOD /path" patterns.
+func validRouteLimits(s *ServerConfig) bool {
+	if s.HandlerTimeout < 0 || s.MaxBodySize < 0 {
+		return false
+	}
+
+	for _, timeouts := range []map[string]time.Duration{s.RouteTimeouts, s.RouteWriteTimeouts} {
+		for route, d := range timeouts {
+			if !validRoute(route) || d < 0 {
+				return false
+			}
+		}
+	}
+
+	for route, n := range s.RouteMaxBodySizes {
+		if !validRoute(route) || n < 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+func validRoute(route string) bool {
+	method, path, ok := strings.Cut(route, " ")
+
+	return ok && method != "" && strings.ToUpper(method) == method && strings.HasPrefix(path, "/")
+}
+
+// validEndpoint reports whether endpoint is empty or an absolute http(s) URL.
+func validEndpoint(endpoint string) bool {
+	if endpoint == "" {
+		return true
+	}
+
+	u, err := url.Parse(endpoint)
+
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
 }
@@ -2,6 +2,7 @@ package config
 
 import (
 	"errors"
+	"math"
 	"testing"
 	"time"
 )
@@ -113,7 +114,7 @@ func TestLoadServerConfig_Valid(t *testing.T) {
 	t.Setenv("WRITE_TIMEOUT", "20s")
 	t.Setenv("IDLE_TIMEOUT", "90s")
 
-	cfg, err := loadServerConfig()
+	cfg, err := loadServerConfig(newLoader(nil, nil))
 	if err != nil {
 		t.Fatalf("loadServerConfig failed: %v", err)
 	}
@@ -135,7 +136,7 @@ func TestLoadServerConfig_Valid(t *testing.T) {
 func TestLoadServerConfig_InvalidDuration(t *testing.T) {
 	t.Setenv("READ_TIMEOUT", "not-a-duration")
 
-	_, err := loadServerConfig()
+	_, err := loadServerConfig(newLoader(nil, nil))
 	if err == nil {
 		t.Error("Expected error for invalid duration")
 	}
@@ -144,7 +145,7 @@ func TestLoadServerConfig_InvalidDuration(t *testing.T) {
 func TestLoadStorageConfig_Default(t *testing.T) {
 	t.Setenv("STORAGE_TYPE", "mem")
 
-	cfg, err := loadStorageConfig()
+	cfg, err := loadStorageConfig(newLoader(nil, nil))
 	if err != nil {
 		t.Fatalf("loadStorageConfig failed: %v", err)
 	}
@@ -157,7 +158,7 @@ func TestLoadStorageConfig_Default(t *testing.T) {
 func TestLoadStorageConfig_WithEnv(t *testing.T) {
 	t.Setenv("STORAGE_TYPE", "postgres")
 
-	cfg, err := loadStorageConfig()
+	cfg, err := loadStorageConfig(newLoader(nil, nil))
 	if err != nil {
 		t.Fatalf("loadStorageConfig failed: %v", err)
 	}
@@ -171,7 +172,7 @@ func TestLoadLoggerConfig_Default(t *testing.T) {
 	t.Setenv("LOGGER_TYPE", "std")
 	t.Setenv("LOG_LEVEL", "info")
 
-	cfg, err := loadLoggerConfig()
+	cfg, err := loadLoggerConfig(newLoader(nil, nil))
 	if err != nil {
 		t.Fatalf("loadLoggerConfig failed: %v", err)
 	}
@@ -188,7 +189,7 @@ func TestLoadLoggerConfig_WithEnv(t *testing.T) {
 	t.Setenv("LOGGER_TYPE", "json")
 	t.Setenv("LOG_LEVEL", "warn")
 
-	cfg, err := loadLoggerConfig()
+	cfg, err := loadLoggerConfig(newLoader(nil, nil))
 	if err != nil {
 		t.Fatalf("loadLoggerConfig failed: %v", err)
 	}
@@ -294,6 +295,254 @@ func TestValidate_EmptyPort(t *testing.T) {
 	}
 }
 
+func TestValidate_AdminPort(t *testing.T) {
+	cfg := &Config{
+		Server: &ServerConfig{
+			Port:         "8080",
+			AdminPort:    "8080",
+			ReadTimeout:  10 * time.Second,
+			WriteTimeout: 10 * time.Second,
+			IdleTimeout:  60 * time.Second,
+		},
+		Logger: &LoggerConfig{
+			Level: "info",
+		},
+	}
+
+	if err := cfg.Validate(); !errors.Is(err, ErrInvalidAdminPort) {
+		t.Errorf("Expected ErrInvalidAdminPort, got %v", err)
+	}
+
+	cfg.Server.AdminPort = ""
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Expected disabled admin port to be valid, got %v", err)
+	}
+}
+
+func TestValidate_AdminAddress(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*ServerConfig)
+		valid  bool
+	}{
+		{"ipv4 loopback", func(*ServerConfig) {}, true},
+		{"ipv6 loopback", func(s *ServerConfig) { s.AdminAddress = "::1" }, true},
+		{"localhost", func(s *ServerConfig) { s.AdminAddress = "localhost" }, true},
+		{"all interfaces", func(s *ServerConfig) { s.AdminAddress = "0.0.0.0" }, false},
+		{"empty address", func(s *ServerConfig) { s.AdminAddress = "" }, false},
+		{"with token", func(s *ServerConfig) {
+			s.AdminAddress = "0.0.0.0"
+			s.AdminToken = "secret-admin-token"
+		}, true},
+		{"with client certificates", func(s *ServerConfig) {
+			s.AdminAddress = "10.0.0.5"
+			s.TLSCertFile, s.TLSKeyFile, s.TLSClientCAFile = "tls.crt", "tls.key", "ca.crt"
+			s.TLSClientAuth = "require"
+		}, true},
+		{"with optional client certificates", func(s *ServerConfig) {
+			s.AdminAddress = "10.0.0.5"
+			s.TLSCertFile, s.TLSKeyFile, s.TLSClientCAFile = "tls.crt", "tls.key", "ca.crt"
+			s.TLSClientAuth = "optional"
+		}, false},
+		{"disabled", func(s *ServerConfig) {
+			s.AdminAddress = "0.0.0.0"
+			s.AdminPort = ""
+		}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := Load()
+			if err != nil {
+				t.Fatalf("Load failed: %v", err)
+			}
+
+			tt.modify(cfg.Server)
+
+			if err := cfg.Validate(); errors.Is(err, ErrInsecureAdmin) == tt.valid {
+				t.Errorf("Expected valid = %v, got %v", tt.valid, err)
+			}
+		})
+	}
+}
+
+func TestValidate_TLS(t *testing.T) {
+	valid := ServerConfig{
+		Port:              "8443",
+		ReadTimeout:       10 * time.Second,
+		WriteTimeout:      10 * time.Second,
+		IdleTimeout:       60 * time.Second,
+		TLSCertFile:       "tls.crt",
+		TLSKeyFile:        "tls.key",
+		TLSClientAuth:     "none",
+		TLSReloadInterval: 30 * time.Second,
+	}
+
+	tests := []struct {
+		name   string
+		modify func(*ServerConfig)
+		want   error
+	}{
+		{"valid", func(*ServerConfig) {}, nil},
+		{"plain HTTP", func(s *ServerConfig) { s.TLSCertFile, s.TLSKeyFile = "", "" }, nil},
+		{"missing key", func(s *ServerConfig) { s.TLSKeyFile = "" }, ErrIncompleteTLS},
+		{"unknown client auth", func(s *ServerConfig) { s.TLSClientAuth = "verify" }, ErrInvalidClientAuth},
+		{"require without CA", func(s *ServerConfig) { s.TLSClientAuth = "require" }, ErrMissingClientCA},
+		{"require with CA", func(s *ServerConfig) {
+			s.TLSClientAuth, s.TLSClientCAFile = "require", "ca.crt"
+		}, nil},
+		{"zero reload interval", func(s *ServerConfig) { s.TLSReloadInterval = 0 }, ErrInvalidTLSReload},
+		{"redirect", func(s *ServerConfig) { s.RedirectPort = "8080" }, nil},
+		{"redirect to itself", func(s *ServerConfig) { s.RedirectPort = "8443" }, ErrInvalidRedirectPort},
+		{"redirect without TLS", func(s *ServerConfig) {
+			s.TLSCertFile, s.TLSKeyFile, s.RedirectPort = "", "", "8080"
+		}, ErrInvalidRedirectPort},
+		{"redirect without TCP port", func(s *ServerConfig) {
+			s.Port, s.UnixSocket, s.RedirectPort = "", "/run/todo.sock", "8080"
+		}, ErrInvalidRedirectPort},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := valid
+			tt.modify(&server)
+
+			cfg := &Config{Server: &server, Logger: &LoggerConfig{Level: "info"}}
+			if err := cfg.Validate(); !errors.Is(err, tt.want) {
+				t.Errorf("Expected %v, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestValidate_ReportsAllProblems(t *testing.T) {
+	cfg := &Config{
+		Server: &ServerConfig{
+			Port:         "",
+			ReadTimeout:  0,
+			WriteTimeout: 10 * time.Second,
+			IdleTimeout:  60 * time.Second,
+		},
+		Logger: &LoggerConfig{
+			Level: "verbose",
+		},
+	}
+
+	err := cfg.Validate()
+	for _, want := range []error{ErrEmptyPort, ErrInvalidReadTimeout, ErrInvalidLogLevel} {
+		if !errors.Is(err, want) {
+			t.Errorf("Expected %v in %v", want, err)
+		}
+	}
+}
+
+func TestValidate_Tracing(t *testing.T) {
+	tests := []struct {
+		name     string
+		endpoint string
+		interval time.Duration
+		wantErr  bool
+	}{
+		{"disabled", "", 5 * time.Second, false},
+		{"collector", "http://localhost:4318/v1/traces", 5 * time.Second, false},
+		{"no scheme", "localhost:4318", 5 * time.Second, true},
+		{"zero interval", "http://localhost:4318/v1/traces", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{
+				Server: &ServerConfig{
+					Port:         "8080",
+					ReadTimeout:  10 * time.Second,
+					WriteTimeout: 10 * time.Second,
+					IdleTimeout:  60 * time.Second,
+				},
+				Logger:  &LoggerConfig{Level: "info"},
+				Tracing: &TracingConfig{Endpoint: tt.endpoint, ExportInterval: tt.interval},
+			}
+
+			err := cfg.Validate()
+			if tt.wantErr != errors.Is(err, ErrInvalidTracing) {
+				t.Errorf("Validate() = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoad_RateLimit(t *testing.T) {
+	t.Setenv("RATE_LIMIT_KEY", "api_key")
+	t.Setenv("RATE_LIMIT_WRITE_RPS", "0.5")
+	t.Setenv("TRUSTED_PROXIES", "10.0.0.0/8, 192.168.1.1")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate failed: %v", err)
+	}
+
+	rl := cfg.RateLimit
+	if rl.Key != "api_key" || rl.WriteRate != 0.5 || rl.ReadBurst != 100 {
+		t.Errorf("Unexpected rate limit config: %+v", rl)
+	}
+	if len(rl.TrustedProxies) != 2 || rl.TrustedProxies[1].String() != "192.168.1.1/32" {
+		t.Errorf("Unexpected trusted proxies: %v", rl.TrustedProxies)
+	}
+
+	rl.Key = "cookie"
+	if err := cfg.Validate(); !errors.Is(err, ErrInvalidRateLimitKey) {
+		t.Errorf("Expected ErrInvalidRateLimitKey, got %v", err)
+	}
+
+	rl.Key = "ip"
+	rl.WriteBurst = 0
+	if err := cfg.Validate(); !errors.Is(err, ErrInvalidRateLimit) {
+		t.Errorf("Expected ErrInvalidRateLimit, got %v", err)
+	}
+
+	t.Setenv("TRUSTED_PROXIES", "not-an-ip")
+	if _, err := Load(); err == nil {
+		t.Error("Expected error for invalid trusted proxy")
+	}
+}
+
+func TestValidate_CORS(t *testing.T) {
+	tests := []struct {
+		name        string
+		origins     []string
+		credentials bool
+		wantErr     error
+	}{
+		{"exact and wildcard subdomain", []string{"https://app.example.com", "https://*.example.org:8443"}, true, nil},
+		{"any origin", []string{"*"}, false, nil},
+		{"any origin with credentials", []string{"*"}, true, ErrCORSCredentials},
+		{"origin with path", []string{"https://app.example.com/"}, false, ErrInvalidCORSOrigin},
+		{"bare host", []string{"app.example.com"}, false, ErrInvalidCORSOrigin},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{
+				Server: &ServerConfig{
+					Port:         "8080",
+					ReadTimeout:  10 * time.Second,
+					WriteTimeout: 10 * time.Second,
+					IdleTimeout:  60 * time.Second,
+				},
+				Logger: &LoggerConfig{Level: "info"},
+				CORS:   &CORSConfig{AllowedOrigins: tt.origins, AllowCredentials: tt.credentials},
+			}
+
+			if err := cfg.Validate(); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Validate() = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
 func TestValidate_InvalidReadTimeout(t *testing.T) {
 	cfg := &Config{
 		Server: &ServerConfig{
@@ -470,3 +719,430 @@ func TestValidate_CompleteValidConfig(t *testing.T) {
 		t.Errorf("Expected no error for complete valid config, got %v", err)
 	}
 }
+
+func TestLoadCalendarConfig(t *testing.T) {
+	t.Setenv("CALENDAR_FEED_TOKENS", "alice:0123456789abcdef, bob:fedcba9876543210")
+
+	cfg, err := loadCalendarConfig(newLoader(nil, nil))
+	if err != nil {
+		t.Fatalf("loadCalendarConfig failed: %v", err)
+	}
+
+	if len(cfg.FeedTokens) != 2 || cfg.FeedTokens["bob"] != "fedcba9876543210" {
+		t.Errorf("Unexpected feed tokens: %v", cfg.FeedTokens)
+	}
+
+	if cfg.UIDDomain != "ecom-internship" {
+		t.Errorf("Expected the default UID domain, got %q", cfg.UIDDomain)
+	}
+
+	t.Setenv("CALENDAR_FEED_TOKENS", "alice")
+
+	if _, err := loadCalendarConfig(newLoader(nil, nil)); !errors.Is(err, ErrInvalidFeedTokens) {
+		t.Errorf("Expected ErrInvalidFeedTokens, got %v", err)
+	}
+}
+
+func TestValidate_WeakFeedToken(t *testing.T) {
+	cfg := &Config{
+		Server: &ServerConfig{
+			Port:         "8080",
+			ReadTimeout:  10 * time.Second,
+			WriteTimeout: 10 * time.Second,
+			IdleTimeout:  60 * time.Second,
+		},
+		Logger: &LoggerConfig{
+			Level: "info",
+		},
+		Calendar: &CalendarConfig{
+			FeedTokens: map[string]string{"alice": "short"},
+			UIDDomain:  "todo@example.com",
+		},
+	}
+
+	err := cfg.Validate()
+	if !errors.Is(err, ErrWeakFeedToken) {
+		t.Errorf("Expected ErrWeakFeedToken, got %v", err)
+	}
+
+	if !errors.Is(err, ErrInvalidUIDDomain) {
+		t.Errorf("Expected ErrInvalidUIDDomain, got %v", err)
+	}
+}
+
+func TestLoadValidationConfig(t *testing.T) {
+	t.Setenv("MAX_CAPTION_LENGTH", "50")
+	t.Setenv("MAX_DESCRIPTION_SIZE", "1024")
+	t.Setenv("MAX_DUE_IN", "720h")
+
+	cfg, err := loadValidationConfig(newLoader(nil, nil))
+	if err != nil {
+		t.Fatalf("loadValidationConfig failed: %v", err)
+	}
+
+	if cfg.MaxCaptionLength != 50 || cfg.MaxDescriptionSize != 1024 || cfg.MaxDueIn != 720*time.Hour {
+		t.Errorf("Unexpected validation config: %+v", cfg)
+	}
+
+	t.Setenv("MAX_CAPTION_LENGTH", "many")
+
+	if _, err := loadValidationConfig(newLoader(nil, nil)); err == nil {
+		t.Error("Expected error for invalid MAX_CAPTION_LENGTH")
+	}
+}
+
+func TestValidate_InvalidValidationLimits(t *testing.T) {
+	cfg := &Config{
+		Server: &ServerConfig{
+			Port:         "8080",
+			ReadTimeout:  10 * time.Second,
+			WriteTimeout: 10 * time.Second,
+			IdleTimeout:  60 * time.Second,
+		},
+		Logger: &LoggerConfig{
+			Level: "info",
+		},
+		Validation: &ValidationConfig{
+			MaxCaptionLength:   0,
+			MaxDescriptionSize: 4096,
+			MaxDueIn:           time.Hour,
+		},
+	}
+
+	if err := cfg.Validate(); !errors.Is(err, ErrInvalidValidation) {
+		t.Errorf("Expected ErrInvalidValidation, got %v", err)
+	}
+}
+
+func TestLoadLoggerConfig_Sinks(t *testing.T) {
+	t.Setenv("LOG_COMPONENT_LEVELS", "database:debug, server:warn")
+	t.Setenv("LOG_FORMAT", "text")
+	t.Setenv("LOG_OUTPUTS", "stdout,file")
+	t.Setenv("LOG_FILE", "/var/log/todo/app.log")
+	t.Setenv("LOG_FILE_MAX_SIZE", "10")
+
+	cfg, err := loadLoggerConfig(newLoader(nil, nil))
+	if err != nil {
+		t.Fatalf("loadLoggerConfig failed: %v", err)
+	}
+
+	if cfg.ComponentLevels["database"] != "debug" || cfg.ComponentLevels["server"] != "warn" {
+		t.Errorf("Unexpected component levels: %v", cfg.ComponentLevels)
+	}
+	if cfg.Format != "text" || len(cfg.Outputs) != 2 || cfg.Outputs[1] != "file" {
+		t.Errorf("Unexpected format or outputs: %q %v", cfg.Format, cfg.Outputs)
+	}
+	if cfg.FileMaxSize != 10 || cfg.FileMaxAge != 168*time.Hour || cfg.FileMaxBackups != 5 {
+		t.Errorf("Unexpected rotation settings: %+v", cfg)
+	}
+
+	t.Setenv("LOG_COMPONENT_LEVELS", "database")
+
+	if _, err := loadLoggerConfig(newLoader(nil, nil)); !errors.Is(err, ErrInvalidLogLevel) {
+		t.Errorf("Expected ErrInvalidLogLevel, got %v", err)
+	}
+}
+
+func TestValidate_Logger(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  LoggerConfig
+		want error
+	}{
+		{"valid", LoggerConfig{Level: "info", Format: "text", Outputs: []string{"stderr", "syslog"}}, nil},
+		{"component level", LoggerConfig{Level: "info", ComponentLevels: map[string]string{"db": "trace"}}, ErrInvalidLogLevel},
+		{"format", LoggerConfig{Level: "info", Format: "xml"}, ErrInvalidLogFormat},
+		{"output", LoggerConfig{Level: "info", Outputs: []string{"kafka"}}, ErrInvalidLogOutput},
+		{"file without path", LoggerConfig{Level: "info", Outputs: []string{"file"}, FileMaxSize: 1}, ErrInvalidLogFile},
+		{"file without size", LoggerConfig{Level: "info", Outputs: []string{"file"}, File: "app.log"}, ErrInvalidLogFile},
+		{"sampling", LoggerConfig{Level: "info", SamplingInitial: 10, SamplingThereafter: -1}, ErrInvalidLogSampling},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := tt.cfg.validate()
+
+			if tt.want == nil {
+				if len(errs) != 0 {
+					t.Errorf("Expected no errors, got %v", errs)
+				}
+
+				return
+			}
+
+			if !errors.Is(errors.Join(errs...), tt.want) {
+				t.Errorf("Expected %v, got %v", tt.want, errs)
+			}
+		})
+	}
+}
+
+func TestValidate_AccessLog(t *testing.T) {
+	tests := []struct {
+		name  string
+		cfg   AccessLogConfig
+		valid bool
+	}{
+		{"json to logger", AccessLogConfig{Format: "json"}, true},
+		{"combined to file", AccessLogConfig{Format: "combined", Output: "/var/log/todo/access.log"}, true},
+		{"common to logger", AccessLogConfig{Format: "common"}, false},
+		{"unknown format", AccessLogConfig{Format: "nginx", Output: "stdout"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := Load()
+			if err != nil {
+				t.Fatalf("Load failed: %v", err)
+			}
+
+			cfg.AccessLog = &tt.cfg
+
+			err = cfg.Validate()
+			if tt.valid && err != nil {
+				t.Errorf("Expected valid config, got %v", err)
+			}
+			if !tt.valid && !errors.Is(err, ErrInvalidAccessLog) {
+				t.Errorf("Expected ErrInvalidAccessLog, got %v", err)
+			}
+		})
+	}
+}
+
+func TestLoadServerConfig_RouteLimits(t *testing.T) {
+	t.Setenv("ROUTE_TIMEOUTS", "POST /import:5m, GET /export:0s")
+	t.Setenv("MAX_BODY_SIZE", "2048")
+
+	cfg, err := loadServerConfig(newLoader(nil, nil))
+	if err != nil {
+		t.Fatalf("loadServerConfig failed: %v", err)
+	}
+
+	if cfg.HandlerTimeout != 10*time.Second || cfg.MaxBodySize != 2048 {
+		t.Errorf("Unexpected defaults: %v %d", cfg.HandlerTimeout, cfg.MaxBodySize)
+	}
+	if cfg.RouteTimeouts["POST /import"] != 5*time.Minute || cfg.RouteTimeouts["GET /export"] != 0 {
+		t.Errorf("Unexpected route timeouts: %v", cfg.RouteTimeouts)
+	}
+	if cfg.RouteMaxBodySizes["POST /import/ics"] != 32<<20 {
+		t.Errorf("Unexpected route body sizes: %v", cfg.RouteMaxBodySizes)
+	}
+
+	// Streamed responses have no write deadline by default.
+	for _, route := range []string{"GET /export", "GET /todos.ics"} {
+		if d, ok := cfg.RouteWriteTimeouts[route]; !ok || d != 0 {
+			t.Errorf("Expected no write timeout for %s, got %v", route, cfg.RouteWriteTimeouts)
+		}
+	}
+
+	t.Setenv("ROUTE_TIMEOUTS", "POST /import:soon")
+
+	if _, err := loadServerConfig(newLoader(nil, nil)); err == nil {
+		t.Error("Expected an error for an invalid route timeout")
+	}
+}
+
+func TestValidate_RouteLimits(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(*ServerConfig)
+	}{
+		{"negative timeout", func(s *ServerConfig) { s.HandlerTimeout = -time.Second }},
+		{"negative body size", func(s *ServerConfig) { s.RouteMaxBodySizes = map[string]int64{"POST /todos": -1} }},
+		{"route without method", func(s *ServerConfig) { s.RouteTimeouts = map[string]time.Duration{"/todos": time.Second} }},
+		{"lowercase method", func(s *ServerConfig) { s.RouteTimeouts = map[string]time.Duration{"get /todos": time.Second} }},
+		{"negative write timeout", func(s *ServerConfig) {
+			s.RouteWriteTimeouts = map[string]time.Duration{"GET /export": -time.Second}
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := Load()
+			if err != nil {
+				t.Fatalf("Load failed: %v", err)
+			}
+
+			tt.change(cfg.Server)
+
+			if err := cfg.Validate(); !errors.Is(err, ErrInvalidRouteLimit) {
+				t.Errorf("Expected ErrInvalidRouteLimit, got %v", err)
+			}
+		})
+	}
+}
+
+func TestValidate_Shutdown(t *testing.T) {
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	if cfg.Server.ShutdownDelay != 0 || cfg.Server.ShutdownTimeout != 30*time.Second {
+		t.Errorf("Unexpected defaults: %v %v", cfg.Server.ShutdownDelay, cfg.Server.ShutdownTimeout)
+	}
+
+	cfg.Server.ShutdownDelay = -time.Second
+
+	if err := cfg.Validate(); !errors.Is(err, ErrInvalidShutdown) {
+		t.Errorf("Expected ErrInvalidShutdown, got %v", err)
+	}
+}
+
+func TestLoadServerConfig_Listeners(t *testing.T) {
+	t.Setenv("PORT", "")
+	t.Setenv("UNIX_SOCKET", "/run/ecom/api.sock")
+	t.Setenv("UNIX_SOCKET_MODE", "0600")
+	t.Setenv("SOCKET_ACTIVATION", "true")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	if cfg.Server.UnixSocket != "/run/ecom/api.sock" || cfg.Server.UnixSocketMode != 0o600 || !cfg.Server.SocketActivation {
+		t.Errorf("Unexpected listeners: %+v", cfg.Server)
+	}
+
+	// The TCP listener may be disabled when the API is served on sockets.
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Validate failed: %v", err)
+	}
+
+	t.Setenv("UNIX_SOCKET_MODE", "rw")
+
+	if _, err := Load(); err == nil {
+		t.Error("Expected error for invalid unix socket mode")
+	}
+}
+
+func TestValidate_Listeners(t *testing.T) {
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	if cfg.Server.UnixSocketMode != 0o660 {
+		t.Errorf("Unexpected default unix socket mode: %o", cfg.Server.UnixSocketMode)
+	}
+
+	cfg.Server.UnixSocket = "/run/ecom/api.sock"
+	cfg.Server.UnixSocketMode = 0o1777
+
+	if err := cfg.Validate(); !errors.Is(err, ErrInvalidUnixSocket) {
+		t.Errorf("Expected ErrInvalidUnixSocket, got %v", err)
+	}
+
+	cfg.Server.UnixSocketMode = 0o660
+	cfg.Server.Port = ""
+	cfg.Server.TLSCertFile = "cert.pem"
+	cfg.Server.TLSKeyFile = "key.pem"
+	cfg.Server.RedirectPort = "8081"
+
+	// Redirects point to the HTTPS port, so they need one.
+	if err := cfg.Validate(); !errors.Is(err, ErrInvalidRedirectPort) {
+		t.Errorf("Expected ErrInvalidRedirectPort, got %v", err)
+	}
+}
+
+func TestValidate_HTTP2(t *testing.T) {
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	s := cfg.Server
+	if !s.HTTP2 || s.HTTP2Cleartext || s.HTTP2MaxConcurrentStreams != 250 || s.HTTP2MaxReadFrameSize != 1<<20 ||
+		s.HTTP2StreamWindow != 1<<20 || s.HTTP2ConnectionWindow != 1<<20 {
+		t.Errorf("Unexpected defaults: %+v", s)
+	}
+
+	// Windows of high-bandwidth links exceed the defaults of net/http.
+	s.HTTP2StreamWindow, s.HTTP2ConnectionWindow = 16<<20, 64<<20
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Expected large windows to be valid, got %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		modify func(*ServerConfig)
+	}{
+		{"negative streams", func(s *ServerConfig) { s.HTTP2MaxConcurrentStreams = -1 }},
+		{"small frame", func(s *ServerConfig) { s.HTTP2MaxReadFrameSize = 1024 }},
+		{"large frame", func(s *ServerConfig) { s.HTTP2MaxReadFrameSize = 32 << 20 }},
+		{"large stream window", func(s *ServerConfig) { s.HTTP2StreamWindow = math.MaxInt32 + 1 }},
+		{"large connection window", func(s *ServerConfig) { s.HTTP2ConnectionWindow = math.MaxInt32 + 1 }},
+		{"small connection window", func(s *ServerConfig) { s.HTTP2ConnectionWindow = 1024 }},
+		{"h2c with tls", func(s *ServerConfig) {
+			s.HTTP2Cleartext, s.TLSCertFile, s.TLSKeyFile = true, "cert.pem", "key.pem"
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := Load()
+			if err != nil {
+				t.Fatalf("Load failed: %v", err)
+			}
+
+			tt.modify(cfg.Server)
+
+			if err := cfg.Validate(); !errors.Is(err, ErrInvalidHTTP2) {
+				t.Errorf("Expected ErrInvalidHTTP2, got %v", err)
+			}
+		})
+	}
+}
+
+func TestValidate_GRPC(t *testing.T) {
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	if cfg.Server.GRPCPort != "" || cfg.Server.GRPCMaxMessageSize != 4<<20 {
+		t.Errorf("Unexpected defaults: port %q, max message size %d",
+			cfg.Server.GRPCPort, cfg.Server.GRPCMaxMessageSize)
+	}
+
+	cfg.Server.GRPCPort = "50051"
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	cfg.Server.GRPCPort = cfg.Server.Port
+	if err := cfg.Validate(); !errors.Is(err, ErrInvalidGRPC) {
+		t.Errorf("Expected ErrInvalidGRPC for the API port, got %v", err)
+	}
+
+	cfg.Server.GRPCPort = "50051"
+	cfg.Server.GRPCMaxMessageSize = 0
+
+	if err := cfg.Validate(); !errors.Is(err, ErrInvalidGRPC) {
+		t.Errorf("Expected ErrInvalidGRPC for zero max message size, got %v", err)
+	}
+}
+
+func TestValidate_GraphQL(t *testing.T) {
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	if cfg.GraphQL.MaxDepth != 10 || cfg.GraphQL.MaxComplexity != 1000 {
+		t.Errorf("Unexpected defaults: max depth %d, max complexity %d",
+			cfg.GraphQL.MaxDepth, cfg.GraphQL.MaxComplexity)
+	}
+
+	cfg.GraphQL.MaxComplexity = 0
+	if err := cfg.Validate(); !errors.Is(err, ErrInvalidGraphQL) {
+		t.Errorf("Expected ErrInvalidGraphQL for zero max complexity, got %v", err)
+	}
+
+	cfg.GraphQL.MaxComplexity = 1000
+	cfg.GraphQL.MaxDepth = -1
+
+	if err := cfg.Validate(); !errors.Is(err, ErrInvalidGraphQL) {
+		t.Errorf("Expected ErrInvalidGraphQL for negative max depth, got %v", err)
+	}
+}
@@ -0,0 +1,510 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"ecom-internship/internal/codec"
+)
+
+// redacted replaces secret values in the printed configuration.
+const redacted = "[REDACTED]"
+
+// Configuration file errors.
+var (
+	ErrUnknownKey        = errors.New("unknown config key")
+	ErrInvalidKeyValue   = errors.New("unsupported config value type")
+	ErrUnsupportedFormat = errors.New("config file must have a .json, .yaml or .yml extension")
+)
+
+// kind describes how a setting is written in the config file.
+type kind int
+
+const (
+	kindString kind = iota
+	kindNumber
+	kindBool
+	// kindList is a comma-separated list in env and flags and an array in the file.
+	kindList
+	// kindMap is a comma-separated list of key:value pairs in env and flags and an object in the file.
+	kindMap
+)
+
+// setting describes a configuration value available in all sources.
+type setting struct {
+	// key is the environment variable name; flags use it in lower case with dashes.
+	key string
+	// path is the section and field in the config file.
+	path   string
+	kind   kind
+	secret bool
+	// reloadable settings are applied on a configuration reload; others need a restart.
+	reloadable bool
+}
+
+// settings lists every configuration value in the order of the printed configuration.
+var settings = []setting{
+	{key: "PORT", path: "server.port"},
+	{key: "ADMIN_PORT", path: "server.admin_port"},
+	{key: "ADMIN_ADDRESS", path: "server.admin_address"},
+	{key: "ADMIN_TOKEN", path: "server.admin_token", secret: true},
+	{key: "READ_TIMEOUT", path: "server.read_timeout", reloadable: true},
+	{key: "WRITE_TIMEOUT", path: "server.write_timeout", reloadable: true},
+	{key: "ROUTE_WRITE_TIMEOUTS", path: "server.route_write_timeouts", kind: kindMap, reloadable: true},
+	{key: "IDLE_TIMEOUT", path: "server.idle_timeout"},
+	{key: "UNIX_SOCKET", path: "server.unix_socket"},
+	{key: "UNIX_SOCKET_MODE", path: "server.unix_socket_mode"},
+	{key: "SOCKET_ACTIVATION", path: "server.socket_activation", kind: kindBool},
+	{key: "HTTP2", path: "server.http2", kind: kindBool},
+	{key: "HTTP2_CLEARTEXT", path: "server.http2_cleartext", kind: kindBool},
+	{key: "HTTP2_MAX_CONCURRENT_STREAMS", path: "server.http2_max_concurrent_streams", kind: kindNumber},
+	{key: "HTTP2_MAX_READ_FRAME_SIZE", path: "server.http2_max_read_frame_size", kind: kindNumber},
+	{key: "HTTP2_STREAM_WINDOW", path: "server.http2_stream_window", kind: kindNumber},
+	{key: "HTTP2_CONNECTION_WINDOW", path: "server.http2_connection_window", kind: kindNumber},
+	{key: "SHUTDOWN_DELAY", path: "server.shutdown_delay"},
+	{key: "SHUTDOWN_TIMEOUT", path: "server.shutdown_timeout"},
+	{key: "HANDLER_TIMEOUT", path: "server.handler_timeout", reloadable: true},
+	{key: "ROUTE_TIMEOUTS", path: "server.route_timeouts", kind: kindMap, reloadable: true},
+	{key: "MAX_BODY_SIZE", path: "server.max_body_size", kind: kindNumber, reloadable: true},
+	{key: "ROUTE_MAX_BODY_SIZES", path: "server.route_max_body_sizes", kind: kindMap, reloadable: true},
+	{key: "TLS_CERT_FILE", path: "server.tls_cert_file"},
+	{key: "TLS_KEY_FILE", path: "server.tls_key_file"},
+	{key: "TLS_CLIENT_CA_FILE", path: "server.tls_client_ca_file"},
+	{key: "TLS_CLIENT_AUTH", path: "server.tls_client_auth"},
+	{key: "TLS_RELOAD_INTERVAL", path: "server.tls_reload_interval"},
+	{key: "REDIRECT_PORT", path: "server.redirect_port"},
+	{key: "GRPC_PORT", path: "server.grpc_port"},
+	{key: "GRPC_MAX_MESSAGE_SIZE", path: "server.grpc_max_message_size", kind: kindNumber},
+	{key: "STORAGE_TYPE", path: "storage.type"},
+	{key: "LOGGER_TYPE", path: "logger.type"},
+	{key: "LOG_LEVEL", path: "logger.level", reloadable: true},
+	{key: "LOG_COMPONENT_LEVELS", path: "logger.component_levels", kind: kindMap, reloadable: true},
+	{key: "LOG_FORMAT", path: "logger.format"},
+	{key: "LOG_OUTPUTS", path: "logger.outputs", kind: kindList},
+	{key: "LOG_FILE", path: "logger.file"},
+	{key: "LOG_FILE_MAX_SIZE", path: "logger.file_max_size", kind: kindNumber},
+	{key: "LOG_FILE_MAX_AGE", path: "logger.file_max_age"},
+	{key: "LOG_FILE_MAX_BACKUPS", path: "logger.file_max_backups", kind: kindNumber},
+	{key: "LOG_SYSLOG_ADDRESS", path: "logger.syslog_address"},
+	{key: "LOG_SAMPLING_INITIAL", path: "logger.sampling_initial", kind: kindNumber},
+	{key: "LOG_SAMPLING_THEREAFTER", path: "logger.sampling_thereafter", kind: kindNumber},
+	{key: "LOG_REDACT_KEYS", path: "logger.redact_keys", kind: kindList},
+	{key: "CALENDAR_FEED_TOKENS", path: "calendar.feed_tokens", kind: kindMap, secret: true},
+	{key: "CALENDAR_UID_DOMAIN", path: "calendar.uid_domain"},
+	{key: "MAX_CAPTION_LENGTH", path: "validation.max_caption_length", kind: kindNumber},
+	{key: "MAX_DESCRIPTION_SIZE", path: "validation.max_description_size", kind: kindNumber},
+	{key: "MAX_DUE_IN", path: "validation.max_due_in"},
+	{key: "TRACING_ENDPOINT", path: "tracing.endpoint"},
+	{key: "TRACING_SERVICE_NAME", path: "tracing.service_name"},
+	{key: "TRACING_EXPORT_INTERVAL", path: "tracing.export_interval"},
+	{key: "RATE_LIMIT_KEY", path: "rate_limit.key", reloadable: true},
+	{key: "RATE_LIMIT_READ_RPS", path: "rate_limit.read_rps", kind: kindNumber, reloadable: true},
+	{key: "RATE_LIMIT_READ_BURST", path: "rate_limit.read_burst", kind: kindNumber, reloadable: true},
+	{key: "RATE_LIMIT_WRITE_RPS", path: "rate_limit.write_rps", kind: kindNumber, reloadable: true},
+	{key: "RATE_LIMIT_WRITE_BURST", path: "rate_limit.write_burst", kind: kindNumber, reloadable: true},
+	{key: "RATE_LIMIT_IDLE_TTL", path: "rate_limit.idle_ttl", reloadable: true},
+	{key: "RATE_LIMIT_MAX_CLIENTS", path: "rate_limit.max_clients", kind: kindNumber, reloadable: true},
+	{key: "TRUSTED_PROXIES", path: "rate_limit.trusted_proxies", kind: kindList, reloadable: true},
+	{key: "CORS_ALLOWED_ORIGINS", path: "cors.allowed_origins", kind: kindList, reloadable: true},
+	{key: "CORS_ALLOWED_METHODS", path: "cors.allowed_methods", kind: kindList, reloadable: true},
+	{key: "CORS_ALLOWED_HEADERS", path: "cors.allowed_headers", kind: kindList, reloadable: true},
+	{key: "CORS_EXPOSED_HEADERS", path: "cors.exposed_headers", kind: kindList, reloadable: true},
+	{key: "CORS_ALLOW_CREDENTIALS", path: "cors.allow_credentials", kind: kindBool, reloadable: true},
+	{key: "CORS_MAX_AGE", path: "cors.max_age", reloadable: true},
+	{key: "COMPRESSION_MIN_SIZE", path: "compression.min_size", kind: kindNumber},
+	{key: "COMPRESSION_LEVEL", path: "compression.level", kind: kindNumber},
+	{key: "ACCESS_LOG_FORMAT", path: "access_log.format"},
+	{key: "ACCESS_LOG_OUTPUT", path: "access_log.output"},
+	{key: "GRAPHQL_MAX_DEPTH", path: "graphql.max_depth", kind: kindNumber},
+	{key: "GRAPHQL_MAX_COMPLEXITY", path: "graphql.max_complexity", kind: kindNumber},
+}
+
+func (s setting) flagName() string {
+	return strings.ReplaceAll(strings.ToLower(s.key), "_", "-")
+}
+
+// loader resolves settings from the layers defaults < file < env < flags
+// and records the effective value of every setting it resolves.
+type loader struct {
+	file      map[string]string
+	flags     map[string]string
+	effective map[string]string
+}
+
+func newLoader(file, flags map[string]string) *loader {
+	return &loader{file: file, flags: flags, effective: make(map[string]string)}
+}
+
+func (l *loader) get(key, defaultValue string) string {
+	value := defaultValue
+
+	if v, ok := l.file[key]; ok {
+		value = v
+	}
+
+	if v, ok := os.LookupEnv(key); ok {
+		value = v
+	}
+
+	if v, ok := l.flags[key]; ok {
+		value = v
+	}
+
+	l.effective[key] = value
+
+	return value
+}
+
+func (l *loader) duration(key, defaultValue string) (time.Duration, error) {
+	d, err := time.ParseDuration(l.get(key, defaultValue))
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", key, err)
+	}
+
+	return d, nil
+}
+
+func (l *loader) int(key, defaultValue string) (int, error) {
+	n, err := strconv.Atoi(l.get(key, defaultValue))
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", key, err)
+	}
+
+	return n, nil
+}
+
+func (l *loader) float(key, defaultValue string) (float64, error) {
+	f, err := strconv.ParseFloat(l.get(key, defaultValue), 64)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", key, err)
+	}
+
+	return f, nil
+}
+
+func (l *loader) bool(key, defaultValue string) (bool, error) {
+	b, err := strconv.ParseBool(l.get(key, defaultValue))
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", key, err)
+	}
+
+	return b, nil
+}
+
+// routeValues parses a kindMap setting of route patterns, such as
+// "POST /import:5m", converting the values with parse.
+func routeValues[T any](l *loader, key, defaultValue string, parse func(string) (T, error)) (map[string]T, error) {
+	res := make(map[string]T)
+
+	for _, pair := range splitList(l.get(key, defaultValue)) {
+		route, value, ok := strings.Cut(pair, ":")
+		if !ok {
+			return nil, fmt.Errorf("%s: %w: %q", key, ErrInvalidRouteLimit, pair)
+		}
+
+		v, err := parse(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", key, err)
+		}
+
+		res[strings.TrimSpace(route)] = v
+	}
+
+	return res, nil
+}
+
+// Options are command-line switches that are not part of the configuration.
+type Options struct {
+	// ConfigFile is the path of the JSON or YAML config file, from --config or CONFIG_FILE.
+	ConfigFile string
+	// PrintConfig requests printing the effective configuration instead of starting.
+	PrintConfig bool
+}
+
+// Parse loads the configuration from defaults, the config file, environment
+// variables and command-line args, each layer overriding the previous one.
+// Every setting has a flag named after its environment variable, e.g. --log-level.
+func Parse(name string, args []string) (*Config, Options, error) {
+	var opts Options
+
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.StringVar(&opts.ConfigFile, "config", getEnv("CONFIG_FILE", ""), "path to the JSON or YAML config file")
+	fs.BoolVar(&opts.PrintConfig, "print-config", false, "print the effective configuration and exit")
+
+	for _, s := range settings {
+		fs.String(s.flagName(), "", "overrides "+s.key)
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return nil, opts, err
+	}
+
+	byFlag := make(map[string]string, len(settings))
+	for _, s := range settings {
+		byFlag[s.flagName()] = s.key
+	}
+
+	flags := make(map[string]string)
+
+	fs.Visit(func(f *flag.Flag) {
+		if key, ok := byFlag[f.Name]; ok {
+			flags[key] = f.Value.String()
+		}
+	})
+
+	var file map[string]string
+
+	if opts.ConfigFile != "" {
+		var err error
+		if file, err = readFile(opts.ConfigFile); err != nil {
+			return nil, opts, err
+		}
+	}
+
+	cfg, err := load(newLoader(file, flags))
+
+	return cfg, opts, err
+}
+
+// readFile reads a JSON or YAML config file, chosen by extension, into setting
+// values keyed by environment variable name. Keys that do not match a setting
+// are reported all at once.
+func readFile(path string) (map[string]string, error) {
+	var c codec.Codec
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		c = codec.JSON
+	case ".yaml", ".yml":
+		c = codec.YAML
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedFormat, path)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var doc map[string]any
+	if err := c.Decode(f, &doc); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("config file %s: %w", path, err)
+	}
+
+	values := make(map[string]string)
+
+	if err := flatten("", doc, values); err != nil {
+		return nil, fmt.Errorf("config file %s: %w", path, err)
+	}
+
+	return values, nil
+}
+
+func flatten(prefix string, obj map[string]any, values map[string]string) error {
+	var errs []error
+
+	for _, name := range slices.Sorted(maps.Keys(obj)) {
+		path := name
+		if prefix != "" {
+			path = prefix + "." + name
+		}
+
+		i := slices.IndexFunc(settings, func(s setting) bool { return s.path == path })
+		if i >= 0 {
+			value, err := fileValue(settings[i], obj[name])
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%w: %s", err, path))
+
+				continue
+			}
+
+			values[settings[i].key] = value
+
+			continue
+		}
+
+		section, ok := obj[name].(map[string]any)
+		if ok && isSection(path) {
+			errs = append(errs, flatten(path, section, values))
+
+			continue
+		}
+
+		errs = append(errs, fmt.Errorf("%w: %s", ErrUnknownKey, path))
+	}
+
+	return errors.Join(errs...)
+}
+
+func isSection(path string) bool {
+	return slices.ContainsFunc(settings, func(s setting) bool {
+		return strings.HasPrefix(s.path, path+".")
+	})
+}
+
+// fileValue converts a decoded JSON value to the string form used by env and flags.
+func fileValue(s setting, v any) (string, error) {
+	switch val := v.(type) {
+	case []any:
+		if s.kind != kindList {
+			return "", ErrInvalidKeyValue
+		}
+
+		items := make([]string, 0, len(val))
+
+		for _, item := range val {
+			str, err := scalar(item)
+			if err != nil {
+				return "", err
+			}
+
+			items = append(items, str)
+		}
+
+		return strings.Join(items, ","), nil
+	case map[string]any:
+		if s.kind != kindMap {
+			return "", ErrInvalidKeyValue
+		}
+
+		pairs := make([]string, 0, len(val))
+
+		for _, k := range slices.Sorted(maps.Keys(val)) {
+			str, err := scalar(val[k])
+			if err != nil {
+				return "", err
+			}
+
+			pairs = append(pairs, k+":"+str)
+		}
+
+		return strings.Join(pairs, ","), nil
+	default:
+		return scalar(v)
+	}
+}
+
+func scalar(v any) (string, error) {
+	switch val := v.(type) {
+	case string:
+		return val, nil
+	case json.Number:
+		return val.String(), nil
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64), nil
+	case bool:
+		return strconv.FormatBool(val), nil
+	default:
+		return "", ErrInvalidKeyValue
+	}
+}
+
+// Change is a setting whose effective value differs between two configurations.
+type Change struct {
+	Key string `json:"key"`
+	Old string `json:"old"`
+	New string `json:"new"`
+	// Reloadable reports whether the change is applied without a restart.
+	Reloadable bool `json:"-"`
+}
+
+// Diff lists the settings whose values differ in next, with secrets redacted.
+func (c *Config) Diff(next *Config) []Change {
+	var changes []Change
+
+	for _, s := range settings {
+		old, value := c.values[s.key], next.values[s.key]
+		if old == value {
+			continue
+		}
+
+		if s.secret {
+			old, value = redact(old), redact(value)
+		}
+
+		changes = append(changes, Change{Key: s.key, Old: old, New: value, Reloadable: s.reloadable})
+	}
+
+	return changes
+}
+
+func redact(value string) string {
+	if value == "" {
+		return ""
+	}
+
+	return redacted
+}
+
+// Print writes the effective configuration in the config file format with secrets redacted.
+func (c *Config) Print(w io.Writer) error {
+	doc := make(map[string]map[string]any)
+
+	for _, s := range settings {
+		value, ok := c.values[s.key]
+		if !ok {
+			continue
+		}
+
+		section, name, _ := strings.Cut(s.path, ".")
+		if doc[section] == nil {
+			doc[section] = make(map[string]any)
+		}
+
+		doc[section][name] = s.render(value)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+
+	return enc.Encode(doc)
+}
+
+// render converts a setting value to its config file form.
+func (s setting) render(value string) any {
+	switch s.kind {
+	case kindNumber:
+		if _, err := strconv.ParseFloat(value, 64); err == nil {
+			return json.Number(value)
+		}
+	case kindBool:
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b
+		}
+	case kindList:
+		return append([]string{}, splitList(value)...)
+	case kindMap:
+		pairs := make(map[string]string)
+
+		for pair := range strings.SplitSeq(value, ",") {
+			if k, v, ok := strings.Cut(strings.TrimSpace(pair), ":"); ok {
+				if s.secret {
+					v = redacted
+				}
+
+				pairs[k] = v
+			}
+		}
+
+		return pairs
+	case kindString:
+	}
+
+	if s.secret && value != "" {
+		return redacted
+	}
+
+	return value
+}
@@ -0,0 +1,228 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeConfigFile(t *testing.T, name, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+
+	return path
+}
+
+func TestParse_Precedence(t *testing.T) {
+	path := writeConfigFile(t, "config.json", `{
+		"server": {"port": 8081, "read_timeout": "3s", "write_timeout": "4s"},
+		"logger": {"level": "warn"},
+		"cors": {"allowed_origins": ["https://app.example.com", "https://*.example.com"]},
+		"calendar": {"feed_tokens": {"alice": "0123456789abcdef"}}
+	}`)
+
+	t.Setenv("CONFIG_FILE", path)
+	t.Setenv("READ_TIMEOUT", "5s")
+	t.Setenv("WRITE_TIMEOUT", "6s")
+
+	cfg, _, err := Parse("test", []string{"--write-timeout=7s"})
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	if cfg.Server.Port != "8081" {
+		t.Errorf("Expected port from file, got %s", cfg.Server.Port)
+	}
+	if cfg.Server.ReadTimeout != 5*time.Second {
+		t.Errorf("Expected env to override file, got %v", cfg.Server.ReadTimeout)
+	}
+	if cfg.Server.WriteTimeout != 7*time.Second {
+		t.Errorf("Expected flag to override env, got %v", cfg.Server.WriteTimeout)
+	}
+	if cfg.Server.IdleTimeout != 60*time.Second {
+		t.Errorf("Expected default idle timeout, got %v", cfg.Server.IdleTimeout)
+	}
+	if cfg.Logger.Level != "warn" || len(cfg.CORS.AllowedOrigins) != 2 ||
+		cfg.Calendar.FeedTokens["alice"] != "0123456789abcdef" {
+		t.Errorf("Unexpected config from file: %+v %+v %+v", cfg.Logger, cfg.CORS, cfg.Calendar)
+	}
+}
+
+func TestParse_YAMLFile(t *testing.T) {
+	path := writeConfigFile(t, "config.yaml", `
+# Local overrides
+server:
+  port: 8082
+  idle_timeout: 2m
+rate_limit:
+  read_rps: 2.5
+  trusted_proxies:
+    - 10.0.0.0/8
+    - 192.168.0.1
+cors:
+  allow_credentials: true
+`)
+
+	cfg, _, err := Parse("test", []string{"--config", path})
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	if cfg.Server.Port != "8082" || cfg.Server.IdleTimeout != 2*time.Minute || cfg.RateLimit.ReadRate != 2.5 ||
+		len(cfg.RateLimit.TrustedProxies) != 2 || !cfg.CORS.AllowCredentials {
+		t.Errorf("Unexpected config from YAML: %+v %+v %+v", cfg.Server, cfg.RateLimit, cfg.CORS)
+	}
+
+	if _, err := readFile(writeConfigFile(t, "config.toml", "")); !errors.Is(err, ErrUnsupportedFormat) {
+		t.Errorf("Expected ErrUnsupportedFormat, got %v", err)
+	}
+}
+
+func TestParse_Options(t *testing.T) {
+	path := writeConfigFile(t, "config.json", `{}`)
+
+	_, opts, err := Parse("test", []string{"--config", path, "--print-config"})
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	if opts.ConfigFile != path || !opts.PrintConfig {
+		t.Errorf("Unexpected options: %+v", opts)
+	}
+
+	if _, _, err := Parse("test", []string{"--no-such-flag"}); err == nil {
+		t.Error("Expected error for unknown flag")
+	}
+
+	if _, _, err := Parse("test", []string{"-h"}); !errors.Is(err, flag.ErrHelp) {
+		t.Errorf("Expected flag.ErrHelp, got %v", err)
+	}
+}
+
+func TestReadFile_UnknownKeys(t *testing.T) {
+	path := writeConfigFile(t, "config.json", `{
+		"server": {"port": "8080", "prot": "8081"},
+		"logging": {"level": "debug"},
+		"cors": {"allowed_origins": "https://a.example.com", "max_age": ["10m"]}
+	}`)
+
+	_, err := readFile(path)
+	if !errors.Is(err, ErrUnknownKey) || !errors.Is(err, ErrInvalidKeyValue) {
+		t.Fatalf("Expected unknown key and invalid value errors, got %v", err)
+	}
+
+	for _, key := range []string{"server.prot", "logging", "cors.max_age"} {
+		if !strings.Contains(err.Error(), key) {
+			t.Errorf("Expected error to mention %s, got %v", key, err)
+		}
+	}
+
+	if _, err := readFile(writeConfigFile(t, "config.json", `{"server": `)); err == nil {
+		t.Error("Expected error for malformed file")
+	}
+}
+
+func TestConfig_Print(t *testing.T) {
+	t.Setenv("CALENDAR_FEED_TOKENS", "alice:0123456789abcdef")
+	t.Setenv("TRUSTED_PROXIES", "10.0.0.0/8")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := cfg.Print(&buf); err != nil {
+		t.Fatalf("Print failed: %v", err)
+	}
+
+	if strings.Contains(buf.String(), "0123456789abcdef") {
+		t.Errorf("Expected feed token to be redacted:\n%s", buf.String())
+	}
+
+	var doc map[string]map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("Failed to unmarshal printed config: %v", err)
+	}
+
+	if doc["server"]["port"] != "8080" || doc["compression"]["min_size"] != float64(1024) ||
+		doc["cors"]["allow_credentials"] != false {
+		t.Errorf("Unexpected printed config: %v", doc)
+	}
+
+	// The printed configuration is a valid config file.
+	values, err := readFile(writeConfigFile(t, "config.json", buf.String()))
+	if err != nil {
+		t.Fatalf("Printed config cannot be read back: %v", err)
+	}
+
+	if values["TRUSTED_PROXIES"] != "10.0.0.0/8" || values["CALENDAR_FEED_TOKENS"] != "alice:"+redacted {
+		t.Errorf("Unexpected values read back: %v", values)
+	}
+}
+
+func TestSettings_AllLoaded(t *testing.T) {
+	l := newLoader(nil, nil)
+	if _, err := load(l); err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+
+	for _, s := range settings {
+		if _, ok := l.effective[s.key]; !ok {
+			t.Errorf("Setting %s is never loaded", s.key)
+		}
+	}
+
+	if len(l.effective) != len(settings) {
+		t.Errorf("Expected %d loaded settings, got %d", len(settings), len(l.effective))
+	}
+}
+
+func TestConfig_Diff(t *testing.T) {
+	t.Setenv("CALENDAR_FEED_TOKENS", "alice:0123456789abcdef")
+
+	old, err := Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	t.Setenv("LOG_LEVEL", "debug")
+	t.Setenv("PORT", "8081")
+	t.Setenv("CALENDAR_FEED_TOKENS", "alice:fedcba9876543210")
+
+	next, err := Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	want := []Change{
+		{Key: "PORT", Old: "8080", New: "8081"},
+		{Key: "LOG_LEVEL", Old: "info", New: "debug", Reloadable: true},
+		{Key: "CALENDAR_FEED_TOKENS", Old: redacted, New: redacted},
+	}
+
+	changes := old.Diff(next)
+	if len(changes) != len(want) {
+		t.Fatalf("Expected %d changes, got %+v", len(want), changes)
+	}
+
+	for i := range want {
+		if changes[i] != want[i] {
+			t.Errorf("Change %d: expected %+v, got %+v", i, want[i], changes[i])
+		}
+	}
+
+	if changes := next.Diff(next); len(changes) != 0 {
+		t.Errorf("Expected no changes, got %+v", changes)
+	}
+}
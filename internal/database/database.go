@@ -17,10 +17,87 @@ type Database interface {
 	DeleteToDo(ctx context.Context, id int) error
 }
 
+// Streamer is implemented by storages that can iterate over ToDo items
+// without copying the whole collection.
+type Streamer interface {
+	// StreamToDos calls fn for every stored ToDo item and stops on the first error.
+	StreamToDos(ctx context.Context, fn func(model.ToDo) error) error
+}
+
+// Pinger is implemented by storages that can verify they are able to serve requests.
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// Counter is implemented by storages that can report their size cheaply.
+type Counter interface {
+	CountToDos(ctx context.Context) (int, error)
+}
+
+// Stats describes the storage contents for introspection.
+type Stats struct {
+	Count int `json:"count"`
+	// MaxID is the largest stored ID; zero for an empty storage.
+	MaxID int `json:"max_id"`
+	// MemoryBytes estimates the memory held by the items; zero if unknown.
+	MemoryBytes int64 `json:"memory_bytes,omitempty"`
+}
+
+// StatsReporter is implemented by storages that can describe their contents.
+type StatsReporter interface {
+	Stats(ctx context.Context) (Stats, error)
+}
+
+// EventType is the kind of change of a ToDo item.
+type EventType int
+
+// Kinds of changes reported to watchers.
+const (
+	EventCreated EventType = iota + 1
+	EventUpdated
+	EventDeleted
+)
+
+// Event describes a committed change of a ToDo item. Events of deleted items
+// carry only the ID.
+type Event struct {
+	Type EventType
+	ToDo model.ToDo
+}
+
+// Watcher is implemented by storages that can notify about changes.
+type Watcher interface {
+	// WatchToDos calls fn for every change committed after the call, in commit
+	// order, until ctx is done or fn returns an error. A watcher falling too
+	// far behind the changes is stopped with ErrWatchLagged.
+	WatchToDos(ctx context.Context, fn func(Event) error) error
+}
+
+// Tx provides raw access to the storage inside a transaction.
+// Unlike Database methods it preserves IDs and timestamps as provided.
+type Tx interface {
+	Exists(id int) bool
+	Get(id int) (model.ToDo, bool)
+	// IDByUID returns the ID of the item imported with the given calendar UID.
+	IDByUID(uid string) (int, bool)
+	NextID() int
+	Put(todo model.ToDo)
+	Clear()
+}
+
+// Transactor is implemented by storages supporting atomic batch changes.
+type Transactor interface {
+	// InTx runs fn inside a transaction. Changes are committed only if fn returns nil.
+	InTx(ctx context.Context, fn func(tx Tx) error) error
+}
+
 var (
 	// ErrNotFound is returned when a ToDo is not found.
 	ErrNotFound = errors.New("todo not found")
 
 	// ErrIDAlreadyExists is returned when creating a ToDo with an existing ID.
 	ErrIDAlreadyExists = errors.New("todo with provided id already exists")
+
+	// ErrWatchLagged is returned to a watcher that did not keep up with the changes.
+	ErrWatchLagged = errors.New("watcher fell behind the changes")
 )
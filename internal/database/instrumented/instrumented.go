@@ -0,0 +1,418 @@
+// Package instrumented provides a database.Database decorator recording storage metrics and spans.
+package instrumented
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+
+	"ecom-internship/internal/database"
+	"ecom-internship/internal/metrics"
+	"ecom-internship/internal/model"
+	"ecom-internship/internal/tracing"
+)
+
+// countTimeout bounds the item count query made on every scrape.
+const countTimeout = time.Second
+
+type instrumentedDB struct {
+	db       database.Database
+	tracer   *tracing.Tracer
+	duration *metrics.HistogramVec
+	errors   *metrics.CounterVec
+}
+
+// The optional interfaces of the wrapped storage are exposed by embedding
+// the types below, so that callers can still detect them with a type
+// assertion and those the storage lacks stay absent.
+type (
+	pinger        struct{ i *instrumentedDB }
+	statsReporter struct{ i *instrumentedDB }
+	watcher       struct{ i *instrumentedDB }
+	streamer      struct{ i *instrumentedDB }
+	transactor    struct{ i *instrumentedDB }
+)
+
+// Optional interfaces of the wrapped storage.
+const (
+	capPing = 1 << iota
+	capStats
+	capWatch
+	capStream
+	capTx
+)
+
+// New wraps db recording operation latency, errors and item count in reg
+// and a child span of the request span for every operation. The result
+// implements the optional database interfaces that db implements.
+func New(db database.Database, reg *metrics.Registry, tracer *tracing.Tracer) database.Database {
+	i := &instrumentedDB{
+		db:     db,
+		tracer: tracer,
+		duration: reg.NewHistogramVec("storage_operation_duration_seconds",
+			"Latency of storage operations.", metrics.DefBuckets, "operation"),
+		errors: reg.NewCounterVec("storage_operation_errors_total",
+			"Number of failed storage operations.", "operation"),
+	}
+
+	reg.NewGaugeFunc("storage_items", "Number of stored ToDo items.", i.count)
+
+	return i.withCapabilities(capabilities(db))
+}
+
+func capabilities(db database.Database) int {
+	var caps int
+
+	if _, ok := db.(database.Pinger); ok {
+		caps |= capPing
+	}
+
+	if _, ok := db.(database.StatsReporter); ok {
+		caps |= capStats
+	}
+
+	if _, ok := db.(database.Watcher); ok {
+		caps |= capWatch
+	}
+
+	if _, ok := db.(database.Streamer); ok {
+		caps |= capStream
+	}
+
+	if _, ok := db.(database.Transactor); ok {
+		caps |= capTx
+	}
+
+	return caps
+}
+
+//nolint:cyclop,funlen,gocyclo,maintidx
+func (i *instrumentedDB) withCapabilities(caps int) database.Database {
+	switch caps {
+	case capPing:
+		return struct {
+			*instrumentedDB
+			pinger
+		}{i, pinger{i}}
+	case capStats:
+		return struct {
+			*instrumentedDB
+			statsReporter
+		}{i, statsReporter{i}}
+	case capPing | capStats:
+		return struct {
+			*instrumentedDB
+			pinger
+			statsReporter
+		}{i, pinger{i}, statsReporter{i}}
+	case capWatch:
+		return struct {
+			*instrumentedDB
+			watcher
+		}{i, watcher{i}}
+	case capPing | capWatch:
+		return struct {
+			*instrumentedDB
+			pinger
+			watcher
+		}{i, pinger{i}, watcher{i}}
+	case capStats | capWatch:
+		return struct {
+			*instrumentedDB
+			statsReporter
+			watcher
+		}{i, statsReporter{i}, watcher{i}}
+	case capPing | capStats | capWatch:
+		return struct {
+			*instrumentedDB
+			pinger
+			statsReporter
+			watcher
+		}{i, pinger{i}, statsReporter{i}, watcher{i}}
+	case capStream:
+		return struct {
+			*instrumentedDB
+			streamer
+		}{i, streamer{i}}
+	case capPing | capStream:
+		return struct {
+			*instrumentedDB
+			pinger
+			streamer
+		}{i, pinger{i}, streamer{i}}
+	case capStats | capStream:
+		return struct {
+			*instrumentedDB
+			statsReporter
+			streamer
+		}{i, statsReporter{i}, streamer{i}}
+	case capPing | capStats | capStream:
+		return struct {
+			*instrumentedDB
+			pinger
+			statsReporter
+			streamer
+		}{i, pinger{i}, statsReporter{i}, streamer{i}}
+	case capWatch | capStream:
+		return struct {
+			*instrumentedDB
+			watcher
+			streamer
+		}{i, watcher{i}, streamer{i}}
+	case capPing | capWatch | capStream:
+		return struct {
+			*instrumentedDB
+			pinger
+			watcher
+			streamer
+		}{i, pinger{i}, watcher{i}, streamer{i}}
+	case capStats | capWatch | capStream:
+		return struct {
+			*instrumentedDB
+			statsReporter
+			watcher
+			streamer
+		}{i, statsReporter{i}, watcher{i}, streamer{i}}
+	case capPing | capStats | capWatch | capStream:
+		return struct {
+			*instrumentedDB
+			pinger
+			statsReporter
+			watcher
+			streamer
+		}{i, pinger{i}, statsReporter{i}, watcher{i}, streamer{i}}
+	case capTx:
+		return struct {
+			*instrumentedDB
+			transactor
+		}{i, transactor{i}}
+	case capPing | capTx:
+		return struct {
+			*instrumentedDB
+			pinger
+			transactor
+		}{i, pinger{i}, transactor{i}}
+	case capStats | capTx:
+		return struct {
+			*instrumentedDB
+			statsReporter
+			transactor
+		}{i, statsReporter{i}, transactor{i}}
+	case capPing | capStats | capTx:
+		return struct {
+			*instrumentedDB
+			pinger
+			statsReporter
+			transactor
+		}{i, pinger{i}, statsReporter{i}, transactor{i}}
+	case capWatch | capTx:
+		return struct {
+			*instrumentedDB
+			watcher
+			transactor
+		}{i, watcher{i}, transactor{i}}
+	case capPing | capWatch | capTx:
+		return struct {
+			*instrumentedDB
+			pinger
+			watcher
+			transactor
+		}{i, pinger{i}, watcher{i}, transactor{i}}
+	case capStats | capWatch | capTx:
+		return struct {
+			*instrumentedDB
+			statsReporter
+			watcher
+			transactor
+		}{i, statsReporter{i}, watcher{i}, transactor{i}}
+	case capPing | capStats | capWatch | capTx:
+		return struct {
+			*instrumentedDB
+			pinger
+			statsReporter
+			watcher
+			transactor
+		}{i, pinger{i}, statsReporter{i}, watcher{i}, transactor{i}}
+	case capStream | capTx:
+		return struct {
+			*instrumentedDB
+			streamer
+			transactor
+		}{i, streamer{i}, transactor{i}}
+	case capPing | capStream | capTx:
+		return struct {
+			*instrumentedDB
+			pinger
+			streamer
+			transactor
+		}{i, pinger{i}, streamer{i}, transactor{i}}
+	case capStats | capStream | capTx:
+		return struct {
+			*instrumentedDB
+			statsReporter
+			streamer
+			transactor
+		}{i, statsReporter{i}, streamer{i}, transactor{i}}
+	case capPing | capStats | capStream | capTx:
+		return struct {
+			*instrumentedDB
+			pinger
+			statsReporter
+			streamer
+			transactor
+		}{i, pinger{i}, statsReporter{i}, streamer{i}, transactor{i}}
+	case capWatch | capStream | capTx:
+		return struct {
+			*instrumentedDB
+			watcher
+			streamer
+			transactor
+		}{i, watcher{i}, streamer{i}, transactor{i}}
+	case capPing | capWatch | capStream | capTx:
+		return struct {
+			*instrumentedDB
+			pinger
+			watcher
+			streamer
+			transactor
+		}{i, pinger{i}, watcher{i}, streamer{i}, transactor{i}}
+	case capStats | capWatch | capStream | capTx:
+		return struct {
+			*instrumentedDB
+			statsReporter
+			watcher
+			streamer
+			transactor
+		}{i, statsReporter{i}, watcher{i}, streamer{i}, transactor{i}}
+	case capPing | capStats | capWatch | capStream | capTx:
+		return struct {
+			*instrumentedDB
+			pinger
+			statsReporter
+			watcher
+			streamer
+			transactor
+		}{i, pinger{i}, statsReporter{i}, watcher{i}, streamer{i}, transactor{i}}
+	default:
+		return i
+	}
+}
+
+// start begins an operation; the returned function records its outcome.
+func (i *instrumentedDB) start(ctx context.Context, operation string) (context.Context, func(error)) {
+	start := time.Now()
+
+	ctx, span := i.tracer.Start(ctx, "storage."+operation, tracing.KindInternal)
+	span.SetAttributes("db.operation.name", operation)
+
+	return ctx, func(err error) {
+		i.duration.With(operation).Observe(time.Since(start).Seconds())
+
+		// A missing item is an answer of the storage, not its failure.
+		if err != nil && !errors.Is(err, database.ErrNotFound) {
+			i.errors.With(operation).Inc()
+			span.SetError(err)
+		}
+
+		span.End()
+	}
+}
+
+func (i *instrumentedDB) count() float64 {
+	ctx, cancel := context.WithTimeout(context.Background(), countTimeout)
+	defer cancel()
+
+	if c, ok := i.db.(database.Counter); ok {
+		n, err := c.CountToDos(ctx)
+		if err != nil {
+			return 0
+		}
+
+		return float64(n)
+	}
+
+	todos, err := i.db.GetAllToDos(ctx)
+	if err != nil {
+		return 0
+	}
+
+	return float64(len(todos))
+}
+
+func (i *instrumentedDB) GetAllToDos(ctx context.Context) ([]model.ToDo, error) {
+	ctx, done := i.start(ctx, "get_all")
+	todos, err := i.db.GetAllToDos(ctx)
+	done(err)
+
+	return todos, err
+}
+
+func (i *instrumentedDB) GetToDoByID(ctx context.Context, id int) (model.ToDo, error) {
+	ctx, done := i.start(ctx, "get")
+	todo, err := i.db.GetToDoByID(ctx, id)
+	done(err)
+
+	return todo, err
+}
+
+func (i *instrumentedDB) CreateToDo(ctx context.Context, todo model.ToDo) (int, error) {
+	ctx, done := i.start(ctx, "create")
+	id, err := i.db.CreateToDo(ctx, todo)
+	done(err)
+
+	return id, err
+}
+
+func (i *instrumentedDB) UpdateToDo(ctx context.Context, todo model.ToDo) error {
+	ctx, done := i.start(ctx, "update")
+	err := i.db.UpdateToDo(ctx, todo)
+	done(err)
+
+	return err
+}
+
+func (i *instrumentedDB) DeleteToDo(ctx context.Context, id int) error {
+	ctx, done := i.start(ctx, "delete")
+	err := i.db.DeleteToDo(ctx, id)
+	done(err)
+
+	return err
+}
+
+// Close closes the wrapped storage if it holds resources.
+func (i *instrumentedDB) Close() error {
+	if c, ok := i.db.(io.Closer); ok {
+		return c.Close()
+	}
+
+	return nil
+}
+
+func (p pinger) Ping(ctx context.Context) error {
+	return p.i.db.(database.Pinger).Ping(ctx) //nolint:forcetypeassert
+}
+
+func (s statsReporter) Stats(ctx context.Context) (database.Stats, error) {
+	return s.i.db.(database.StatsReporter).Stats(ctx) //nolint:forcetypeassert
+}
+
+func (w watcher) WatchToDos(ctx context.Context, fn func(database.Event) error) error {
+	return w.i.db.(database.Watcher).WatchToDos(ctx, fn) //nolint:forcetypeassert
+}
+
+func (s streamer) StreamToDos(ctx context.Context, fn func(model.ToDo) error) error {
+	ctx, done := s.i.start(ctx, "stream")
+	err := s.i.db.(database.Streamer).StreamToDos(ctx, fn) //nolint:forcetypeassert
+	done(err)
+
+	return err
+}
+
+func (t transactor) InTx(ctx context.Context, fn func(tx database.Tx) error) error {
+	ctx, done := t.i.start(ctx, "tx")
+	err := t.i.db.(database.Transactor).InTx(ctx, fn) //nolint:forcetypeassert
+	done(err)
+
+	return err
+}
@@ -0,0 +1,109 @@
+package instrumented
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"ecom-internship/internal/database"
+	"ecom-internship/internal/database/mem"
+	"ecom-internship/internal/logger/std"
+	"ecom-internship/internal/metrics"
+	"ecom-internship/internal/model"
+	"ecom-internship/internal/tracing"
+)
+
+func TestInstrumentedDB(t *testing.T) {
+	reg := metrics.NewRegistry()
+	db := New(mem.New(std.New("error")), reg, tracing.NewTracer(nil))
+	ctx := context.Background()
+
+	if _, ok := db.(database.Transactor); !ok {
+		t.Error("Expected Transactor to be preserved")
+	}
+	if _, ok := db.(database.Streamer); !ok {
+		t.Error("Expected Streamer to be preserved")
+	}
+
+	if _, err := db.CreateToDo(ctx, model.ToDo{Caption: "a"}); err != nil {
+		t.Fatalf("CreateToDo failed: %v", err)
+	}
+	if _, err := db.GetToDoByID(ctx, 42); !errors.Is(err, database.ErrNotFound) {
+		t.Fatalf("Expected ErrNotFound, got %v", err)
+	}
+
+	var b strings.Builder
+	if _, err := reg.WriteTo(&b); err != nil {
+		t.Fatalf("WriteTo failed: %v", err)
+	}
+
+	for _, want := range []string{
+		`storage_operation_duration_seconds_count{operation="create"} 1`,
+		`storage_operation_duration_seconds_count{operation="get"} 1`,
+		"storage_items 1\n",
+	} {
+		if !strings.Contains(b.String(), want) {
+			t.Errorf("Expected %q in:\n%s", want, b.String())
+		}
+	}
+
+	// A missing item is not a storage failure.
+	if strings.Contains(b.String(), `storage_operation_errors_total{operation="get"}`) {
+		t.Errorf("Expected no error for a missing item in:\n%s", b.String())
+	}
+}
+
+// plainDB hides the optional interfaces of the storage it wraps.
+type plainDB struct {
+	database.Database
+}
+
+// streamingDB exposes only database.Streamer of the optional interfaces.
+type streamingDB struct {
+	database.Database
+	database.Streamer
+}
+
+func TestNew_OptionalInterfaces(t *testing.T) {
+	inner := mem.New(std.New("error"))
+
+	tests := []struct {
+		name string
+		db   database.Database
+		want []string
+	}{
+		{"all", inner, []string{"Pinger", "StatsReporter", "Watcher", "Streamer", "Transactor"}},
+		{"none", plainDB{inner}, nil},
+		{"streamer", streamingDB{inner, inner}, []string{"Streamer"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := New(tt.db, metrics.NewRegistry(), tracing.NewTracer(nil))
+
+			var got []string
+
+			if _, ok := db.(database.Pinger); ok {
+				got = append(got, "Pinger")
+			}
+			if _, ok := db.(database.StatsReporter); ok {
+				got = append(got, "StatsReporter")
+			}
+			if _, ok := db.(database.Watcher); ok {
+				got = append(got, "Watcher")
+			}
+			if _, ok := db.(database.Streamer); ok {
+				got = append(got, "Streamer")
+			}
+			if _, ok := db.(database.Transactor); ok {
+				got = append(got, "Transactor")
+			}
+
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
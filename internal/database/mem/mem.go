@@ -16,4 +16,7 @@ type MemDB struct {
 	log   logger.Logger
 	mu    sync.RWMutex
 	maxID int
+
+	// watchers receive the changes; guarded by mu.
+	watchers map[*watcher]struct{}
 }
@@ -0,0 +1,56 @@
+package mem
+
+import (
+	"context"
+	"unsafe"
+
+	"ecom-internship/internal/database"
+	"ecom-internship/internal/model"
+)
+
+// Ping reports whether the storage can serve requests; the in-memory storage
+// is always available while the process runs.
+func (db *MemDB) Ping(ctx context.Context) error {
+	return ctx.Err()
+}
+
+// CountToDos returns the number of stored ToDo items.
+func (db *MemDB) CountToDos(ctx context.Context) (int, error) {
+	const funcName = "CountToDos"
+
+	select {
+	case <-ctx.Done():
+		db.log.Info("context cancelled", "func", funcName)
+
+		return 0, ctx.Err()
+	default:
+	}
+
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	return len(db.data), nil
+}
+
+// Stats returns the number of items, the largest ID and an estimate of the
+// memory held by the items: the backing array and the strings and due dates
+// it references.
+func (db *MemDB) Stats(ctx context.Context) (database.Stats, error) {
+	if err := ctx.Err(); err != nil {
+		return database.Stats{}, err
+	}
+
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	memory := int64(cap(db.data)) * int64(unsafe.Sizeof(model.ToDo{}))
+
+	for _, todo := range db.data {
+		memory += int64(len(todo.Caption) + len(todo.Description))
+		if todo.DueAt != nil {
+			memory += int64(unsafe.Sizeof(*todo.DueAt))
+		}
+	}
+
+	return database.Stats{Count: len(db.data), MaxID: db.maxID, MemoryBytes: memory}, nil
+}
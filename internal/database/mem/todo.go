@@ -109,6 +109,8 @@ func (db *MemDB) CreateToDo(ctx context.Context, todo model.ToDo) (int, error) {
 	db.data = append(db.data, todo)
 	db.maxID = todo.ID
 
+	db.publish(database.Event{Type: database.EventCreated, ToDo: todo})
+
 	return todo.ID, nil
 }
 
@@ -134,9 +136,12 @@ func (db *MemDB) UpdateToDo(ctx context.Context, todo model.ToDo) error {
 
 	todo.CreatedAt = db.data[index].CreatedAt
 	todo.UpdatedAt = time.Now()
+	todo.UID = db.data[index].UID
 
 	db.data[index] = todo
 
+	db.publish(database.Event{Type: database.EventUpdated, ToDo: todo})
+
 	return nil
 }
 
@@ -163,6 +168,8 @@ func (db *MemDB) DeleteToDo(ctx context.Context, id int) error {
 	db.data = append(db.data[:index], db.data[index+1:]...)
 	db.maxID = db.findMaxID()
 
+	db.publish(database.Event{Type: database.EventDeleted, ToDo: model.ToDo{ID: id}})
+
 	return nil
 }
 
@@ -3,6 +3,7 @@ package mem
 import (
 	"context"
 	"errors"
+	"runtime"
 	"testing"
 
 	"ecom-internship/internal/database"
@@ -254,3 +255,166 @@ func TestMemDB_ConcurrentAccess(t *testing.T) {
 		t.Errorf("Expected %d todos, got %d", expectedCount, len(todos))
 	}
 }
+
+func TestMemDB_StreamToDosUnlocked(t *testing.T) {
+	db := New(std.New("error"))
+	ctx := context.Background()
+
+	for _, caption := range []string{"first", "second"} {
+		if _, err := db.CreateToDo(ctx, model.ToDo{Caption: caption}); err != nil {
+			t.Fatalf("CreateToDo failed: %v", err)
+		}
+	}
+
+	// Writing from fn would deadlock if the lock were held while streaming.
+	var streamed int
+
+	err := db.StreamToDos(ctx, func(todo model.ToDo) error {
+		streamed++
+
+		_, err := db.CreateToDo(ctx, model.ToDo{Caption: "copy of " + todo.Caption})
+
+		return err
+	})
+	if err != nil {
+		t.Fatalf("StreamToDos failed: %v", err)
+	}
+
+	if streamed != 2 {
+		t.Errorf("Expected the 2 items of the snapshot, got %d", streamed)
+	}
+}
+
+func TestMemDB_Stats(t *testing.T) {
+	db := New(std.New("error"))
+	ctx := context.Background()
+
+	stats, err := db.Stats(ctx)
+	if err != nil {
+		t.Fatalf("Stats failed: %v", err)
+	}
+	if stats.Count != 0 || stats.MaxID != 0 {
+		t.Errorf("Expected empty stats, got %+v", stats)
+	}
+
+	for _, caption := range []string{"first", "second", "third"} {
+		if _, err := db.CreateToDo(ctx, model.ToDo{Caption: caption, Description: "description"}); err != nil {
+			t.Fatalf("CreateToDo failed: %v", err)
+		}
+	}
+
+	if err := db.DeleteToDo(ctx, 2); err != nil {
+		t.Fatalf("DeleteToDo failed: %v", err)
+	}
+
+	stats, err = db.Stats(ctx)
+	if err != nil {
+		t.Fatalf("Stats failed: %v", err)
+	}
+	if stats.Count != 2 || stats.MaxID != 3 {
+		t.Errorf("Expected 2 items with max ID 3, got %+v", stats)
+	}
+	if stats.MemoryBytes < int64(len("first")+len("third")+2*len("description")) {
+		t.Errorf("Expected memory to include item contents, got %d", stats.MemoryBytes)
+	}
+}
+
+// watch starts a watcher calling fn and waits until it is registered.
+func watch(t *testing.T, ctx context.Context, db *MemDB, fn func(database.Event) error) <-chan error {
+	t.Helper()
+
+	done := make(chan error, 1)
+
+	go func() { done <- db.WatchToDos(ctx, fn) }()
+
+	for {
+		db.mu.RLock()
+		n := len(db.watchers)
+		db.mu.RUnlock()
+
+		if n > 0 {
+			return done
+		}
+
+		runtime.Gosched()
+	}
+}
+
+func TestMemDB_WatchToDos(t *testing.T) {
+	db := New(std.New("error"))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	if _, err := db.CreateToDo(ctx, model.ToDo{Caption: "before watching"}); err != nil {
+		t.Fatalf("CreateToDo failed: %v", err)
+	}
+
+	events := make(chan database.Event, watchBuffer)
+	done := watch(t, ctx, db, func(e database.Event) error {
+		events <- e
+
+		return nil
+	})
+
+	id, _ := db.CreateToDo(ctx, model.ToDo{Caption: "created"})
+	db.UpdateToDo(ctx, model.ToDo{ID: id, Caption: "updated"}) //nolint:errcheck,gosec
+	db.DeleteToDo(ctx, 1)                                      //nolint:errcheck,gosec
+
+	db.InTx(ctx, func(tx database.Tx) error { //nolint:errcheck,gosec
+		tx.Clear()
+		tx.Put(model.ToDo{ID: 10, Caption: "imported"})
+
+		return nil
+	})
+
+	want := []struct {
+		typ database.EventType
+		id  int
+	}{
+		{database.EventCreated, id},
+		{database.EventUpdated, id},
+		{database.EventDeleted, 1},
+		{database.EventCreated, 10},
+		{database.EventDeleted, id},
+	}
+
+	for _, w := range want {
+		e := <-events
+		if e.Type != w.typ || e.ToDo.ID != w.id {
+			t.Errorf("Expected event %d for id %d, got %d for id %d", w.typ, w.id, e.Type, e.ToDo.ID)
+		}
+	}
+
+	cancel()
+
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+}
+
+func TestMemDB_WatchToDosLagged(t *testing.T) {
+	db := New(std.New("error"))
+	ctx := context.Background()
+
+	block := make(chan struct{})
+	done := watch(t, ctx, db, func(database.Event) error {
+		<-block
+
+		return nil
+	})
+
+	// The blocked watcher holds at most one event besides its buffer.
+	for range watchBuffer + 2 {
+		db.CreateToDo(ctx, model.ToDo{Caption: "change"}) //nolint:errcheck,gosec
+	}
+
+	close(block)
+
+	if err := <-done; !errors.Is(err, database.ErrWatchLagged) {
+		t.Errorf("Expected ErrWatchLagged, got %v", err)
+	}
+
+	if len(db.watchers) != 0 {
+		t.Errorf("Expected the lagged watcher to be removed, got %d", len(db.watchers))
+	}
+}
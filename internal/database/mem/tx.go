@@ -0,0 +1,182 @@
+package mem
+
+import (
+	"context"
+	"slices"
+
+	"ecom-internship/internal/database"
+	"ecom-internship/internal/model"
+)
+
+// StreamToDos calls fn for every ToDo item of a snapshot taken under the read
+// lock; fn runs unlocked, so slow consumers do not block writers.
+func (db *MemDB) StreamToDos(ctx context.Context, fn func(model.ToDo) error) error {
+	const funcName = "StreamToDos"
+
+	db.mu.RLock()
+	todos := slices.Clone(db.data)
+	db.mu.RUnlock()
+
+	for _, todo := range todos {
+		select {
+		case <-ctx.Done():
+			db.log.Info("context cancelled", "func", funcName)
+
+			return ctx.Err()
+		default:
+		}
+
+		if err := fn(todo); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// InTx runs fn on a copy of the storage and swaps it in if fn succeeds.
+func (db *MemDB) InTx(ctx context.Context, fn func(tx database.Tx) error) error {
+	const funcName = "InTx"
+
+	select {
+	case <-ctx.Done():
+		db.log.Info("context cancelled", "func", funcName)
+
+		return ctx.Err()
+	default:
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	tx := &memTx{
+		data:    make([]model.ToDo, len(db.data)),
+		index:   make(map[int]int, len(db.data)),
+		uids:    make(map[string]int),
+		touched: make(map[int]struct{}),
+	}
+	copy(tx.data, db.data)
+
+	for i, todo := range tx.data {
+		tx.index[todo.ID] = i
+		tx.maxID = max(tx.maxID, todo.ID)
+
+		if todo.UID != "" {
+			tx.uids[todo.UID] = todo.ID
+		}
+	}
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	if err := ctx.Err(); err != nil {
+		db.log.Info("context cancelled", "func", funcName)
+
+		return err
+	}
+
+	events := tx.events(db.data)
+
+	db.data = tx.data
+	db.maxID = tx.maxID
+
+	db.publish(events...)
+
+	return nil
+}
+
+type memTx struct {
+	data  []model.ToDo
+	index map[int]int
+	// uids maps the calendar UIDs of imported items to their IDs.
+	uids map[string]int
+	// touched holds the IDs of the items put in the transaction.
+	touched map[int]struct{}
+	// maxID is kept up to date by Put, so NextID does not scan the index.
+	maxID int
+}
+
+func (tx *memTx) Exists(id int) bool {
+	_, ok := tx.index[id]
+
+	return ok
+}
+
+func (tx *memTx) Get(id int) (model.ToDo, bool) {
+	i, ok := tx.index[id]
+	if !ok {
+		return model.ToDo{}, false
+	}
+
+	return tx.data[i], true
+}
+
+func (tx *memTx) IDByUID(uid string) (int, bool) {
+	id, ok := tx.uids[uid]
+
+	return id, ok
+}
+
+func (tx *memTx) NextID() int {
+	return tx.maxID + 1
+}
+
+func (tx *memTx) Put(todo model.ToDo) {
+	tx.touched[todo.ID] = struct{}{}
+	tx.maxID = max(tx.maxID, todo.ID)
+
+	if todo.UID != "" {
+		tx.uids[todo.UID] = todo.ID
+	}
+
+	if i, ok := tx.index[todo.ID]; ok {
+		if old := tx.data[i].UID; old != todo.UID && tx.uids[old] == todo.ID {
+			delete(tx.uids, old)
+		}
+
+		tx.data[i] = todo
+
+		return
+	}
+
+	tx.index[todo.ID] = len(tx.data)
+	tx.data = append(tx.data, todo)
+}
+
+func (tx *memTx) Clear() {
+	tx.data = make([]model.ToDo, 0)
+	tx.index = make(map[int]int)
+	tx.uids = make(map[string]int)
+	tx.maxID = 0
+}
+
+// events lists the changes the transaction makes to the items in before.
+func (tx *memTx) events(before []model.ToDo) []database.Event {
+	existed := make(map[int]struct{}, len(before))
+	for _, todo := range before {
+		existed[todo.ID] = struct{}{}
+	}
+
+	var events []database.Event
+
+	for _, todo := range tx.data {
+		_, ok := existed[todo.ID]
+		_, touched := tx.touched[todo.ID]
+
+		switch {
+		case !ok:
+			events = append(events, database.Event{Type: database.EventCreated, ToDo: todo})
+		case touched:
+			events = append(events, database.Event{Type: database.EventUpdated, ToDo: todo})
+		}
+	}
+
+	for _, todo := range before {
+		if !tx.Exists(todo.ID) {
+			events = append(events, database.Event{Type: database.EventDeleted, ToDo: model.ToDo{ID: todo.ID}})
+		}
+	}
+
+	return events
+}
@@ -0,0 +1,70 @@
+package mem
+
+import (
+	"context"
+
+	"ecom-internship/internal/database"
+)
+
+// watchBuffer is the number of changes a watcher may fall behind before it is stopped.
+const watchBuffer = 256
+
+type watcher struct {
+	events chan database.Event
+	// lagged is closed when the buffer overflows.
+	lagged chan struct{}
+}
+
+// WatchToDos calls fn for every change committed after the call. Changes are
+// buffered, so a slow fn does not block writers; a watcher whose buffer
+// overflows is stopped with database.ErrWatchLagged.
+func (db *MemDB) WatchToDos(ctx context.Context, fn func(database.Event) error) error {
+	w := &watcher{
+		events: make(chan database.Event, watchBuffer),
+		lagged: make(chan struct{}),
+	}
+
+	db.mu.Lock()
+	if db.watchers == nil {
+		db.watchers = make(map[*watcher]struct{})
+	}
+	db.watchers[w] = struct{}{}
+	db.mu.Unlock()
+
+	defer func() {
+		db.mu.Lock()
+		delete(db.watchers, w)
+		db.mu.Unlock()
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case e := <-w.events:
+			if err := fn(e); err != nil {
+				return err
+			}
+		case <-w.lagged:
+			return database.ErrWatchLagged
+		}
+	}
+}
+
+// publish delivers events to the watchers; db.mu must be held for writing.
+func (db *MemDB) publish(events ...database.Event) {
+	for w := range db.watchers {
+		for _, e := range events {
+			select {
+			case w.events <- e:
+				continue
+			default:
+			}
+
+			close(w.lagged)
+			delete(db.watchers, w)
+
+			break
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package graphql
+
+import (
+	"context"
+	"slices"
+)
+
+// executor resolves planned fields and collects the errors of fields. A field
+// that fails is null; if its type is non-null, the null propagates to the
+// closest nullable parent.
+type executor struct {
+	errors []*Error
+}
+
+// selections resolves fields on source. It returns false if a non-null field
+// is null, making the whole object null.
+func (e *executor) selections(ctx context.Context, obj *schemaType, source any,
+	fields []*plannedField, path []any,
+) (object, bool) {
+	out := make(object, 0, len(fields))
+
+	for _, f := range fields {
+		if f.def == nil {
+			out = append(out, member{key: f.key, value: obj.name})
+
+			continue
+		}
+
+		value, ok := e.field(ctx, source, f, append(slices.Clip(path), f.key))
+		if !ok {
+			return nil, false
+		}
+
+		out = append(out, member{key: f.key, value: value})
+	}
+
+	return out, true
+}
+
+func (e *executor) field(ctx context.Context, source any, f *plannedField, path []any) (any, bool) {
+	value, err := f.def.resolve(ctx, source, f.args)
+	if err != nil {
+		e.fail(toError(err), f.loc, path)
+
+		return nil, f.def.typ.kind != kindNonNull
+	}
+
+	return e.complete(ctx, f.def.typ, value, f, path)
+}
+
+// complete converts a resolved value into the result of type t. It returns
+// false if the value is null where t is non-null.
+func (e *executor) complete(ctx context.Context, t *schemaType, value any, f *plannedField, path []any) (any, bool) {
+	if t.kind == kindNonNull {
+		result, ok := e.completeNullable(ctx, t.of, value, f, path)
+		if ok && result == nil {
+			e.fail(&Error{
+				Message:    "Cannot return null for non-nullable field.",
+				Extensions: map[string]any{"code": CodeInternal},
+			}, f.loc, path)
+		}
+
+		return result, ok && result != nil
+	}
+
+	result, ok := e.completeNullable(ctx, t, value, f, path)
+	if !ok {
+		return nil, true
+	}
+
+	return result, true
+}
+
+func (e *executor) completeNullable(ctx context.Context, t *schemaType, value any, f *plannedField, path []any) (any, bool) {
+	if value == nil {
+		return nil, true
+	}
+
+	switch t.kind {
+	case kindList:
+		items, _ := value.([]any)
+		out := make([]any, len(items))
+
+		for i, item := range items {
+			var ok bool
+			if out[i], ok = e.complete(ctx, t.of, item, f, append(slices.Clip(path), i)); !ok {
+				return nil, false
+			}
+		}
+
+		return out, true
+	case kindObject:
+		obj, ok := e.selections(ctx, t, value, f.selections, path)
+		if !ok {
+			return nil, false
+		}
+
+		return obj, true
+	default:
+		result, err := t.serialize(value)
+		if err != nil {
+			e.fail(&Error{Message: "Internal server error", Extensions: map[string]any{"code": CodeInternal}, err: err},
+				f.loc, path)
+
+			return nil, false
+		}
+
+		return result, true
+	}
+}
+
+// fail records the error of the field at path.
+func (e *executor) fail(err *Error, loc Location, path []any) {
+	located := *err
+	located.Locations = []Location{loc}
+	located.Path = path
+
+	e.errors = append(e.errors, &located)
+}
@@ -0,0 +1,276 @@
+// Package graphql serves the todo storage through GraphQL. It implements the
+// executable part of the language - operations, variables, fragments and the
+// skip and include directives - over a schema defined in Go; introspection
+// is not supported, the schema is published in the README instead.
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"ecom-internship/internal/database"
+	"ecom-internship/internal/validation"
+)
+
+// Error codes reported in the "code" extension of errors.
+const (
+	CodeParseFailed      = "GRAPHQL_PARSE_FAILED"
+	CodeValidationFailed = "GRAPHQL_VALIDATION_FAILED"
+	CodeQueryTooComplex  = "QUERY_TOO_COMPLEX"
+	CodeBadUserInput     = "BAD_USER_INPUT"
+	CodeNotFound         = "NOT_FOUND"
+	CodeAlreadyExists    = "ALREADY_EXISTS"
+	CodeTimeout          = "TIMEOUT"
+	CodeInternal         = "INTERNAL_SERVER_ERROR"
+)
+
+// Request is a GraphQL request as sent in a POST body.
+type Request struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName,omitempty"`
+	Variables     map[string]any `json:"variables,omitempty"`
+}
+
+// Response is the result of a request. Data is present once the operation
+// has started executing, even if it is null because of errors.
+type Response struct {
+	Data   any
+	Errors []*Error
+
+	executed bool
+}
+
+// MarshalJSON writes the errors first, so that they are noticed in large results.
+func (r *Response) MarshalJSON() ([]byte, error) {
+	var resp struct {
+		Errors []*Error         `json:"errors,omitempty"`
+		Data   *json.RawMessage `json:"data,omitempty"`
+	}
+
+	resp.Errors = r.Errors
+
+	if r.executed {
+		data, err := json.Marshal(r.Data)
+		if err != nil {
+			return nil, err
+		}
+
+		resp.Data = (*json.RawMessage)(&data)
+	}
+
+	return json.Marshal(resp)
+}
+
+// Location is a position in the query document, counted from 1.
+type Location struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+// Error is an error of a request or of a field.
+type Error struct {
+	Message    string         `json:"message"`
+	Locations  []Location     `json:"locations,omitempty"`
+	Path       []any          `json:"path,omitempty"`
+	Extensions map[string]any `json:"extensions,omitempty"`
+
+	// err is the unexpected error behind an internal error; it is logged
+	// instead of being returned to the client.
+	err error
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Unwrap returns the cause of an internal error.
+func (e *Error) Unwrap() error {
+	return e.err
+}
+
+// Code returns the code of the error.
+func (e *Error) Code() string {
+	code, _ := e.Extensions["code"].(string)
+
+	return code
+}
+
+func newError(code string, loc Location, format string, args ...any) *Error {
+	return &Error{
+		Message:    fmt.Sprintf(format, args...),
+		Locations:  []Location{loc},
+		Extensions: map[string]any{"code": code},
+	}
+}
+
+func newValidationError(loc Location, format string, args ...any) *Error {
+	return newError(CodeValidationFailed, loc, format, args...)
+}
+
+// toError converts the error of a resolver into the error returned to the
+// client. Storage and validation errors are mapped to their codes; unexpected
+// errors are hidden behind a generic message.
+func toError(err error) *Error {
+	var gerr *Error
+	var verrs validation.Errors
+
+	switch {
+	case errors.As(err, &gerr):
+		return gerr
+	case errors.Is(err, database.ErrNotFound):
+		return &Error{Message: "Todo not found", Extensions: map[string]any{"code": CodeNotFound}}
+	case errors.Is(err, database.ErrIDAlreadyExists):
+		return &Error{Message: "Todo with this id already exists", Extensions: map[string]any{"code": CodeAlreadyExists}}
+	case errors.As(err, &verrs):
+		fields := make([]map[string]string, 0, len(verrs))
+		for _, fe := range verrs {
+			fields = append(fields, map[string]string{"field": fieldName(fe.Field), "message": fe.Message})
+		}
+
+		return &Error{Message: "Invalid todo", Extensions: map[string]any{"code": CodeBadUserInput, "fields": fields}}
+	case errors.Is(err, context.DeadlineExceeded):
+		return &Error{Message: "Request timed out", Extensions: map[string]any{"code": CodeTimeout}}
+	default:
+		return &Error{Message: "Internal server error", Extensions: map[string]any{"code": CodeInternal}, err: err}
+	}
+}
+
+// fieldName returns the GraphQL name of a field reported by the validator.
+func fieldName(field string) string {
+	switch field {
+	case "due_at":
+		return "dueAt"
+	case "is_completed":
+		return "isCompleted"
+	default:
+		return field
+	}
+}
+
+// Schema executes requests against the todo storage.
+type Schema struct {
+	query    *schemaType
+	mutation *schemaType
+	// types are the named input types variables may have and objects the
+	// output types fragments may apply to.
+	types   map[string]*schemaType
+	objects map[string]*schemaType
+
+	maxDepth      int
+	maxComplexity int
+}
+
+// Execute runs the operation of req. Errors of the request itself, e.g.
+// syntax errors or exceeded limits, are returned without data; errors of
+// fields null them and are returned with the rest of the data.
+func (s *Schema) Execute(ctx context.Context, req Request) *Response {
+	doc, err := parse(req.Query)
+	if err != nil {
+		return requestError(err)
+	}
+
+	op, err := selectOperation(doc, req.OperationName)
+	if err != nil {
+		return requestError(err)
+	}
+
+	root := s.query
+	if op.kind == "mutation" {
+		root = s.mutation
+	}
+
+	pl := &planner{schema: s, doc: doc, op: op}
+
+	fields, err := pl.plan(root, req.Variables)
+	if err != nil {
+		return requestError(err)
+	}
+
+	e := &executor{}
+
+	resp := &Response{executed: true}
+	if data, ok := e.selections(ctx, root, nil, fields, nil); ok {
+		resp.Data = data
+	}
+
+	resp.Errors = e.errors
+
+	return resp
+}
+
+func requestError(err error) *Response {
+	return &Response{Errors: []*Error{toError(err)}}
+}
+
+func selectOperation(doc *document, name string) (*operation, error) {
+	var op *operation
+
+	switch {
+	case name == "" && len(doc.operations) > 1:
+		return nil, newValidationError(doc.operations[1].loc,
+			"Must provide operation name if query contains multiple operations.")
+	case name == "":
+		op = doc.operations[0]
+	default:
+		for _, o := range doc.operations {
+			if o.name == name {
+				op = o
+			}
+		}
+
+		if op == nil {
+			return nil, &Error{
+				Message:    fmt.Sprintf("Unknown operation named %q.", name),
+				Extensions: map[string]any{"code": CodeValidationFailed},
+			}
+		}
+	}
+
+	if op.kind == "subscription" {
+		return nil, newValidationError(op.loc, "Subscriptions are not supported.")
+	}
+
+	return op, nil
+}
+
+// object is a result object; it keeps the fields in the order they were selected.
+type object []member
+
+type member struct {
+	key   string
+	value any
+}
+
+func (o object) MarshalJSON() ([]byte, error) {
+	var b bytes.Buffer
+
+	b.WriteByte('{')
+
+	for i, m := range o {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+
+		key, err := json.Marshal(m.key)
+		if err != nil {
+			return nil, err
+		}
+
+		b.Write(key)
+		b.WriteByte(':')
+
+		value, err := json.Marshal(m.value)
+		if err != nil {
+			return nil, err
+		}
+
+		b.Write(value)
+	}
+
+	b.WriteByte('}')
+
+	return b.Bytes(), nil
+}
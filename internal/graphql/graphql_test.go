@@ -0,0 +1,467 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"ecom-internship/internal/config"
+	"ecom-internship/internal/database/mem"
+	"ecom-internship/internal/logger/std"
+	"ecom-internship/internal/model"
+	"ecom-internship/internal/validation"
+)
+
+type result struct {
+	Data   map[string]any `json:"data"`
+	Errors []struct {
+		Message    string         `json:"message"`
+		Path       []any          `json:"path"`
+		Extensions map[string]any `json:"extensions"`
+	} `json:"errors"`
+}
+
+func newSchema(t *testing.T) (*Schema, *mem.MemDB) {
+	t.Helper()
+
+	db := mem.New(std.New("error"))
+	v := validation.New(&config.ValidationConfig{
+		MaxCaptionLength:   200,
+		MaxDescriptionSize: 4096,
+		MaxDueIn:           24 * time.Hour,
+	})
+
+	return New(&config.GraphQLConfig{MaxDepth: 5, MaxComplexity: 300}, db, v), db
+}
+
+// execute runs query and decodes the JSON response, as clients see it.
+func execute(t *testing.T, s *Schema, query string, vars map[string]any) result {
+	t.Helper()
+
+	b, err := json.Marshal(s.Execute(context.Background(), Request{Query: query, Variables: vars}))
+	if err != nil {
+		t.Fatalf("Failed to encode response: %v", err)
+	}
+
+	var res result
+	if err := json.Unmarshal(b, &res); err != nil {
+		t.Fatalf("Failed to decode response %s: %v", b, err)
+	}
+
+	return res
+}
+
+// code returns the code of the only error of res.
+func (res result) code(t *testing.T) string {
+	t.Helper()
+
+	if len(res.Errors) != 1 {
+		t.Fatalf("Expected one error, got %+v", res.Errors)
+	}
+
+	code, _ := res.Errors[0].Extensions["code"].(string)
+
+	return code
+}
+
+func get(v any, path ...any) any {
+	for _, p := range path {
+		switch key := p.(type) {
+		case string:
+			m, _ := v.(map[string]any)
+			v = m[key]
+		case int:
+			l, _ := v.([]any)
+			if key >= len(l) {
+				return nil
+			}
+
+			v = l[key]
+		}
+	}
+
+	return v
+}
+
+func createToDos(t *testing.T, db *mem.MemDB, todos ...model.ToDo) {
+	t.Helper()
+
+	for _, todo := range todos {
+		if _, err := db.CreateToDo(context.Background(), todo); err != nil {
+			t.Fatalf("CreateToDo failed: %v", err)
+		}
+	}
+}
+
+func TestExecute_Todo(t *testing.T) {
+	s, db := newSchema(t)
+
+	due := time.Date(2026, 5, 1, 9, 30, 0, 0, time.UTC)
+	createToDos(t, db, model.ToDo{Caption: "Buy milk", Description: "2 liters", DueAt: &due})
+
+	res := execute(t, s, `query Get($id: ID!) {
+		todo(id: $id) { __typename id title: caption ...Details dueAt @include(if: true) createdAt @skip(if: true) }
+	}
+	fragment Details on Todo { description isCompleted }`, map[string]any{"id": 1})
+
+	if len(res.Errors) != 0 {
+		t.Fatalf("Unexpected errors: %+v", res.Errors)
+	}
+
+	want := map[string]any{
+		"__typename":  "Todo",
+		"id":          "1",
+		"title":       "Buy milk",
+		"description": "2 liters",
+		"isCompleted": false,
+		"dueAt":       "2026-05-01T09:30:00Z",
+	}
+
+	todo, _ := res.Data["todo"].(map[string]any)
+	if len(todo) != len(want) {
+		t.Errorf("Expected fields %v, got %v", want, todo)
+	}
+
+	for k, v := range want {
+		if todo[k] != v {
+			t.Errorf("Field %s: expected %v, got %v", k, v, todo[k])
+		}
+	}
+
+	// A missing todo is null with a NOT_FOUND error at its path.
+	res = execute(t, s, `{ todo(id: 42) { id } }`, nil)
+	if code := res.code(t); code != CodeNotFound || res.Data["todo"] != nil || get(res.Errors[0].Path, 0) != "todo" {
+		t.Errorf("Expected null todo with NOT_FOUND, got %s %+v", code, res)
+	}
+}
+
+func TestExecute_TodosPagination(t *testing.T) {
+	s, db := newSchema(t)
+
+	for i, caption := range []string{"Write report", "Buy milk", "Review report", "Call mom", "Report bug"} {
+		createToDos(t, db, model.ToDo{Caption: caption, IsCompleted: i%2 == 0})
+	}
+
+	const query = `query Page($after: String) {
+		todos(filter: {completed: true, query: "REPORT"}, first: 2, after: $after) {
+			totalCount
+			edges { cursor node { caption } }
+			pageInfo { hasNextPage endCursor }
+		}
+	}`
+
+	var (
+		captions []string
+		after    any
+	)
+
+	for pages := 1; ; pages++ {
+		if pages > 3 {
+			t.Fatal("Too many pages")
+		}
+
+		res := execute(t, s, query, map[string]any{"after": after})
+		if len(res.Errors) != 0 {
+			t.Fatalf("Unexpected errors: %+v", res.Errors)
+		}
+
+		if total := get(res.Data, "todos", "totalCount"); total != 3.0 {
+			t.Errorf("Expected total count 3, got %v", total)
+		}
+
+		edges, _ := get(res.Data, "todos", "edges").([]any)
+		for _, edge := range edges {
+			captions = append(captions, get(edge, "node", "caption").(string)) //nolint:forcetypeassert
+		}
+
+		if get(res.Data, "todos", "pageInfo", "endCursor") != get(edges, len(edges)-1, "cursor") {
+			t.Errorf("Expected the end cursor to be the cursor of the last edge")
+		}
+
+		if get(res.Data, "todos", "pageInfo", "hasNextPage") == false {
+			break
+		}
+
+		after = get(res.Data, "todos", "pageInfo", "endCursor")
+	}
+
+	// Completed todos mentioning a report, in ID order.
+	if len(captions) != 3 || captions[0] != "Write report" || captions[1] != "Review report" ||
+		captions[2] != "Report bug" {
+		t.Errorf("Unexpected todos: %v", captions)
+	}
+
+	res := execute(t, s, `{ todos(first: 101) { totalCount } }`, nil)
+	if code := res.code(t); code != CodeBadUserInput {
+		t.Errorf("Expected BAD_USER_INPUT for a large page, got %s", code)
+	}
+
+	res = execute(t, s, `{ todos(after: "bogus") { totalCount } }`, nil)
+	if code := res.code(t); code != CodeBadUserInput {
+		t.Errorf("Expected BAD_USER_INPUT for an invalid cursor, got %s", code)
+	}
+}
+
+func TestExecute_DueFilter(t *testing.T) {
+	s, db := newSchema(t)
+
+	soon := time.Now().Add(time.Hour).UTC()
+	later := soon.Add(10 * time.Hour)
+	createToDos(t, db,
+		model.ToDo{Caption: "No due date"},
+		model.ToDo{Caption: "Soon", DueAt: &soon},
+		model.ToDo{Caption: "Later", DueAt: &later},
+	)
+
+	res := execute(t, s, `query($before: DateTime) { todos(filter: {dueBefore: $before}) { nodes { caption } } }`,
+		map[string]any{"before": soon.Add(time.Minute).Format(time.RFC3339)})
+
+	nodes, _ := get(res.Data, "todos", "nodes").([]any)
+	if len(nodes) != 1 || get(nodes, 0, "caption") != "Soon" {
+		t.Errorf("Expected only the todo due soon, got %v %+v", nodes, res.Errors)
+	}
+}
+
+func TestExecute_Mutations(t *testing.T) {
+	s, db := newSchema(t)
+
+	due := time.Now().Add(time.Hour).UTC().Truncate(time.Second).Format(time.RFC3339)
+
+	res := execute(t, s, `mutation Create($input: CreateTodoInput!) {
+		createTodo(input: $input) { id caption description dueAt isCompleted }
+	}`, map[string]any{"input": map[string]any{"caption": "Buy milk", "dueAt": due}})
+
+	if len(res.Errors) != 0 || get(res.Data, "createTodo", "id") != "1" || get(res.Data, "createTodo", "dueAt") != due {
+		t.Fatalf("Unexpected create result: %+v", res)
+	}
+
+	// Fields missing from the input are kept; a null due date is removed.
+	res = execute(t, s, `mutation { updateTodo(id: 1, input: {description: "oat", dueAt: null}) {
+		caption description dueAt
+	} }`, nil)
+
+	if len(res.Errors) != 0 || get(res.Data, "updateTodo", "caption") != "Buy milk" ||
+		get(res.Data, "updateTodo", "description") != "oat" || get(res.Data, "updateTodo", "dueAt") != nil {
+		t.Errorf("Unexpected update result: %+v", res)
+	}
+
+	res = execute(t, s, `mutation { toggleTodo(id: "1") { isCompleted } }`, nil)
+	if get(res.Data, "toggleTodo", "isCompleted") != true {
+		t.Errorf("Expected the todo to be completed, got %+v", res)
+	}
+
+	if todo, err := db.GetToDoByID(context.Background(), 1); err != nil || !todo.IsCompleted || todo.Description != "oat" {
+		t.Errorf("Unexpected stored todo: %+v %v", todo, err)
+	}
+
+	res = execute(t, s, `mutation { deleteTodo(id: 1) }`, nil)
+	if res.Data["deleteTodo"] != "1" {
+		t.Errorf("Expected the deleted ID, got %+v", res)
+	}
+
+	if _, err := db.GetToDoByID(context.Background(), 1); err == nil {
+		t.Error("Expected the todo to be deleted")
+	}
+}
+
+func TestExecute_MutationErrors(t *testing.T) {
+	s, db := newSchema(t)
+
+	createToDos(t, db, model.ToDo{ID: 7, Caption: "Existing"})
+
+	tests := []struct {
+		name  string
+		query string
+		code  string
+	}{
+		{"missing todo", `mutation { toggleTodo(id: 42) { id } }`, CodeNotFound},
+		{"delete missing todo", `mutation { deleteTodo(id: 42) }`, CodeNotFound},
+		{"duplicate id", `mutation { createTodo(input: {id: 7, caption: "Again"}) { id } }`, CodeAlreadyExists},
+		{"blank caption", `mutation { updateTodo(id: 7, input: {caption: " "}) { id } }`, CodeBadUserInput},
+		{"invalid id", `mutation { deleteTodo(id: "seven") }`, CodeBadUserInput},
+		{"invalid date", `mutation { createTodo(input: {caption: "x", dueAt: "tomorrow"}) { id } }`, CodeBadUserInput},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := execute(t, s, tt.query, nil)
+			if code := res.code(t); code != tt.code {
+				t.Errorf("Expected %s, got %s: %s", tt.code, code, res.Errors[0].Message)
+			}
+
+			// Mutation fields are non-null, so the error nulls the data.
+			if res.Data != nil {
+				t.Errorf("Expected null data, got %v", res.Data)
+			}
+		})
+	}
+
+	res := execute(t, s, `mutation { createTodo(input: {caption: "", description: "bell \u0007"}) { id } }`, nil)
+
+	fields, _ := res.Errors[0].Extensions["fields"].([]any)
+	if len(fields) != 2 || get(fields, 0, "field") != "caption" || get(fields, 1, "field") != "description" {
+		t.Errorf("Expected the invalid fields in the error, got %+v", res.Errors[0].Extensions)
+	}
+}
+
+func TestExecute_RequestErrors(t *testing.T) {
+	s, _ := newSchema(t)
+
+	tests := []struct {
+		name  string
+		query string
+		vars  map[string]any
+		code  string
+	}{
+		{"syntax", `{ todos { totalCount }`, nil, CodeParseFailed},
+		{"empty document", `# nothing`, nil, CodeParseFailed},
+		{"unknown field", `{ todos { count } }`, nil, CodeValidationFailed},
+		{"missing selection", `{ todo(id: 1) }`, nil, CodeValidationFailed},
+		{"selection on scalar", `{ todo(id: 1) { id { value } } }`, nil, CodeValidationFailed},
+		{"unknown argument", `{ todo(id: 1, deep: true) { id } }`, nil, CodeValidationFailed},
+		{"missing argument", `{ todo { id } }`, nil, CodeValidationFailed},
+		{"invalid argument", `{ todos(first: "ten") { totalCount } }`, nil, CodeBadUserInput},
+		{"unknown input field", `{ todos(filter: {done: true}) { totalCount } }`, nil, CodeBadUserInput},
+		{"unknown fragment", `{ todo(id: 1) { ...Missing } }`, nil, CodeValidationFailed},
+		{"fragment cycle", `{ todo(id: 1) { ...A } } fragment A on Todo { ...B } fragment B on Todo { id ...A }`,
+			nil, CodeValidationFailed},
+		{"fragment on other type", `{ todo(id: 1) { ... on Query { __typename } } }`, nil, CodeValidationFailed},
+		{"unknown directive", `{ todo(id: 1) { id @defer } }`, nil, CodeValidationFailed},
+		{"conflicting fields", `{ todo(id: 1) { a: id a: caption } }`, nil, CodeValidationFailed},
+		{"conflicting arguments", `{ t: todo(id: 1) { id } t: todo(id: 2) { id } }`, nil, CodeValidationFailed},
+		{"undefined variable", `{ todo(id: $id) { id } }`, nil, CodeValidationFailed},
+		{"variable type mismatch", `query($id: String!) { todo(id: $id) { id } }`, map[string]any{"id": "1"},
+			CodeValidationFailed},
+		{"nullable variable", `query($id: ID) { todo(id: $id) { id } }`, map[string]any{"id": "1"}, CodeValidationFailed},
+		{"missing variable", `query($id: ID!) { todo(id: $id) { id } }`, nil, CodeBadUserInput},
+		{"invalid variable", `query($first: Int) { todos(first: $first) { totalCount } }`,
+			map[string]any{"first": 1.5}, CodeBadUserInput},
+		{"unknown variable type", `query($f: Filter) { todos(filter: $f) { totalCount } }`, nil, CodeValidationFailed},
+		{"anonymous operations", `{ todos { totalCount } } query Q { todos { totalCount } }`, nil, CodeValidationFailed},
+		{"subscription", `subscription { todos { totalCount } }`, nil, CodeValidationFailed},
+		{"introspection", `{ __schema { types { name } } }`, nil, CodeValidationFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := execute(t, s, tt.query, tt.vars)
+			if code := res.code(t); code != tt.code {
+				t.Errorf("Expected %s, got %s: %s", tt.code, code, res.Errors[0].Message)
+			}
+
+			// Requests that fail before execution have no data at all.
+			if res.Data != nil {
+				t.Errorf("Expected no data, got %v", res.Data)
+			}
+		})
+	}
+}
+
+func TestExecute_Limits(t *testing.T) {
+	s, _ := newSchema(t)
+
+	tests := []struct {
+		name  string
+		query string
+		code  string
+	}{
+		{"depth within limit", `{ todos(first: 10) { edges { node { ...F } } } } fragment F on Todo { id }`, ""},
+		// 1 + 100 * (edges 1 + node 1 + 2 fields) exceeds 300.
+		{"too complex", `{ todos(first: 100) { edges { node { id caption } } } }`, CodeQueryTooComplex},
+		{"complex with default page", `{ todos { edges { node { id caption description } } nodes { id } } }`,
+			CodeQueryTooComplex},
+		{"small page", `{ todos(first: 20) { edges { node { id caption } } totalCount } }`, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := execute(t, s, tt.query, nil)
+
+			if tt.code == "" {
+				if len(res.Errors) != 0 {
+					t.Errorf("Unexpected errors: %+v", res.Errors)
+				}
+
+				return
+			}
+
+			if code := res.code(t); code != tt.code {
+				t.Errorf("Expected %s, got %s: %s", tt.code, code, res.Errors[0].Message)
+			}
+		})
+	}
+
+	deep := New(&config.GraphQLConfig{MaxDepth: 3, MaxComplexity: 1000}, mem.New(std.New("error")), nil)
+
+	res := execute(t, deep, `{ todos { edges { node { ...F } } } } fragment F on Todo { id }`, nil)
+	if code := res.code(t); code != CodeQueryTooComplex {
+		t.Errorf("Expected QUERY_TOO_COMPLEX for depth 4, got %s", code)
+	}
+
+	res = execute(t, deep, `{ todos { pageInfo { endCursor } } }`, nil)
+	if len(res.Errors) != 0 {
+		t.Errorf("Unexpected errors for depth 3: %+v", res.Errors)
+	}
+}
+
+func TestExecute_FragmentExpansion(t *testing.T) {
+	s, db := newSchema(t)
+
+	createToDos(t, db, model.ToDo{Caption: "a"})
+
+	// Every fragment spreads the previous one twice, so expanding each spread
+	// again would take 2^30 steps.
+	var b strings.Builder
+
+	b.WriteString(`{ todo(id: 1) { ...F30 } } fragment F0 on Todo { id }`)
+
+	for i := 1; i <= 30; i++ {
+		fmt.Fprintf(&b, " fragment F%d on Todo { id ...F%d ... on Todo { ...F%d } }", i, i-1, i-1)
+	}
+
+	query := b.String()
+	done := make(chan *Response, 1)
+
+	go func() { done <- s.Execute(context.Background(), Request{Query: query}) }()
+
+	select {
+	case resp := <-done:
+		if len(resp.Errors) != 0 || resp.Data == nil {
+			t.Errorf("Unexpected response: %+v", resp)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected doubling fragments to be planned quickly")
+	}
+
+	b.Reset()
+	b.WriteString("{")
+
+	for i := 0; i <= maxSpreads; i++ {
+		fmt.Fprintf(&b, " t%d: todo(id: 1) { ...F }", i)
+	}
+
+	b.WriteString(" } fragment F on Todo { id }")
+
+	res := execute(t, s, b.String(), nil)
+	if code := res.code(t); code != CodeQueryTooComplex || !strings.Contains(res.Errors[0].Message, "fragment spreads") {
+		t.Errorf("Expected the fragment spread limit, got %s: %s", code, res.Errors[0].Message)
+	}
+}
+
+func TestResponse_FieldOrder(t *testing.T) {
+	s, db := newSchema(t)
+
+	createToDos(t, db, model.ToDo{Caption: "Ordered"})
+
+	b, err := json.Marshal(s.Execute(context.Background(), Request{Query: `{ todo(id: 1) { isCompleted caption id } }`}))
+	if err != nil {
+		t.Fatalf("Failed to encode response: %v", err)
+	}
+
+	if want := `{"data":{"todo":{"isCompleted":false,"caption":"Ordered","id":"1"}}}`; string(b) != want {
+		t.Errorf("Expected %s, got %s", want, b)
+	}
+}
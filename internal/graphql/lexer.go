@@ -0,0 +1,338 @@
+package graphql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenPunct
+	tokenName
+	tokenInt
+	tokenFloat
+	tokenString
+)
+
+// token is a lexical token of a document. For strings value holds the
+// unescaped contents.
+type token struct {
+	kind  tokenKind
+	value string
+	loc   Location
+}
+
+func (t token) String() string {
+	switch t.kind {
+	case tokenEOF:
+		return "<EOF>"
+	case tokenString:
+		return strconv.Quote(t.value)
+	default:
+		return t.value
+	}
+}
+
+// lexer splits a document into tokens, skipping whitespace, commas and comments.
+type lexer struct {
+	src  string
+	pos  int
+	line int
+	// lineStart is the offset of the current line, for columns.
+	lineStart int
+}
+
+func newLexer(src string) *lexer {
+	return &lexer{src: src, line: 1}
+}
+
+func (l *lexer) errorf(loc Location, format string, args ...any) error {
+	return &Error{
+		Message:    "Syntax Error: " + fmt.Sprintf(format, args...),
+		Locations:  []Location{loc},
+		Extensions: map[string]any{"code": CodeParseFailed},
+	}
+}
+
+// next returns the following token.
+func (l *lexer) next() (token, error) {
+	l.skipIgnored()
+
+	loc := Location{Line: l.line, Column: utf8.RuneCountInString(l.src[l.lineStart:l.pos]) + 1}
+
+	if l.pos >= len(l.src) {
+		return token{kind: tokenEOF, loc: loc}, nil
+	}
+
+	c := l.src[l.pos]
+
+	switch {
+	case strings.IndexByte("!$&()[]{}:=@|", c) >= 0:
+		l.pos++
+
+		return token{kind: tokenPunct, value: string(c), loc: loc}, nil
+	case c == '.':
+		if !strings.HasPrefix(l.src[l.pos:], "...") {
+			return token{}, l.errorf(loc, "unexpected %q", c)
+		}
+
+		l.pos += 3
+
+		return token{kind: tokenPunct, value: "...", loc: loc}, nil
+	case c == '_' || isLetter(c):
+		start := l.pos
+		for l.pos < len(l.src) && (l.src[l.pos] == '_' || isLetter(l.src[l.pos]) || isDigit(l.src[l.pos])) {
+			l.pos++
+		}
+
+		return token{kind: tokenName, value: l.src[start:l.pos], loc: loc}, nil
+	case c == '-' || isDigit(c):
+		return l.number(loc)
+	case c == '"':
+		if strings.HasPrefix(l.src[l.pos:], `"""`) {
+			return l.blockString(loc)
+		}
+
+		return l.string(loc)
+	default:
+		r, _ := utf8.DecodeRuneInString(l.src[l.pos:])
+
+		return token{}, l.errorf(loc, "unexpected character %q", r)
+	}
+}
+
+func (l *lexer) skipIgnored() {
+	for l.pos < len(l.src) {
+		switch c := l.src[l.pos]; c {
+		case ' ', '\t', ',':
+			l.pos++
+		case '\n', '\r':
+			l.pos++
+			if c == '\r' && l.pos < len(l.src) && l.src[l.pos] == '\n' {
+				l.pos++
+			}
+
+			l.newLine()
+		case '#':
+			for l.pos < len(l.src) && l.src[l.pos] != '\n' && l.src[l.pos] != '\r' {
+				l.pos++
+			}
+		default:
+			if strings.HasPrefix(l.src[l.pos:], "\uFEFF") {
+				l.pos += len("\uFEFF")
+
+				continue
+			}
+
+			return
+		}
+	}
+}
+
+func (l *lexer) newLine() {
+	l.line++
+	l.lineStart = l.pos
+}
+
+func (l *lexer) number(loc Location) (token, error) {
+	start := l.pos
+	kind := tokenInt
+
+	if l.src[l.pos] == '-' {
+		l.pos++
+	}
+
+	intStart := l.pos
+	if !l.digits() {
+		return token{}, l.errorf(loc, "invalid number")
+	}
+
+	if l.src[intStart] == '0' && l.pos-intStart > 1 {
+		return token{}, l.errorf(loc, "invalid number, unexpected digit after 0")
+	}
+
+	if l.pos < len(l.src) && l.src[l.pos] == '.' {
+		kind = tokenFloat
+		l.pos++
+
+		if !l.digits() {
+			return token{}, l.errorf(loc, "invalid number")
+		}
+	}
+
+	if l.pos < len(l.src) && (l.src[l.pos] == 'e' || l.src[l.pos] == 'E') {
+		kind = tokenFloat
+		l.pos++
+
+		if l.pos < len(l.src) && (l.src[l.pos] == '+' || l.src[l.pos] == '-') {
+			l.pos++
+		}
+
+		if !l.digits() {
+			return token{}, l.errorf(loc, "invalid number")
+		}
+	}
+
+	// A number directly followed by a name, e.g. 1a, is an error.
+	if l.pos < len(l.src) && (l.src[l.pos] == '_' || l.src[l.pos] == '.' || isLetter(l.src[l.pos])) {
+		return token{}, l.errorf(loc, "invalid number")
+	}
+
+	return token{kind: kind, value: l.src[start:l.pos], loc: loc}, nil
+}
+
+// digits consumes digits and reports whether there were any.
+func (l *lexer) digits() bool {
+	start := l.pos
+	for l.pos < len(l.src) && isDigit(l.src[l.pos]) {
+		l.pos++
+	}
+
+	return l.pos > start
+}
+
+func (l *lexer) string(loc Location) (token, error) {
+	var b strings.Builder
+
+	l.pos++ // opening quote
+
+	for l.pos < len(l.src) {
+		c := l.src[l.pos]
+
+		switch {
+		case c == '"':
+			l.pos++
+
+			return token{kind: tokenString, value: b.String(), loc: loc}, nil
+		case c == '\n' || c == '\r':
+			return token{}, l.errorf(loc, "unterminated string")
+		case c == '\\':
+			if err := l.escape(&b, loc); err != nil {
+				return token{}, err
+			}
+		default:
+			b.WriteByte(c)
+			l.pos++
+		}
+	}
+
+	return token{}, l.errorf(loc, "unterminated string")
+}
+
+var escapes = map[byte]byte{'"': '"', '\\': '\\', '/': '/', 'b': '\b', 'f': '\f', 'n': '\n', 'r': '\r', 't': '\t'}
+
+func (l *lexer) escape(b *strings.Builder, loc Location) error {
+	if l.pos+1 >= len(l.src) {
+		return l.errorf(loc, "unterminated string")
+	}
+
+	c := l.src[l.pos+1]
+	if r, ok := escapes[c]; ok {
+		b.WriteByte(r)
+		l.pos += 2
+
+		return nil
+	}
+
+	if c != 'u' || l.pos+6 > len(l.src) {
+		return l.errorf(loc, "invalid escape sequence")
+	}
+
+	r, err := strconv.ParseUint(l.src[l.pos+2:l.pos+6], 16, 32)
+	if err != nil {
+		return l.errorf(loc, "invalid unicode escape sequence")
+	}
+
+	l.pos += 6
+
+	// A surrogate pair encodes a character outside the basic plane.
+	if r >= 0xD800 && r < 0xDC00 && strings.HasPrefix(l.src[l.pos:], `\u`) && l.pos+6 <= len(l.src) {
+		if low, err := strconv.ParseUint(l.src[l.pos+2:l.pos+6], 16, 32); err == nil && low >= 0xDC00 && low < 0xE000 {
+			r = 0x10000 + (r-0xD800)<<10 + (low - 0xDC00)
+			l.pos += 6
+		}
+	}
+
+	b.WriteRune(rune(r))
+
+	return nil
+}
+
+// blockString reads a """block string""", removing the common indentation
+// and the leading and trailing blank lines.
+func (l *lexer) blockString(loc Location) (token, error) {
+	l.pos += 3
+
+	var raw strings.Builder
+
+	for l.pos < len(l.src) {
+		switch {
+		case strings.HasPrefix(l.src[l.pos:], `"""`):
+			l.pos += 3
+
+			return token{kind: tokenString, value: blockStringValue(raw.String()), loc: loc}, nil
+		case strings.HasPrefix(l.src[l.pos:], `\"""`):
+			raw.WriteString(`"""`)
+			l.pos += 4
+		default:
+			c := l.src[l.pos]
+			raw.WriteByte(c)
+			l.pos++
+
+			if c == '\n' || (c == '\r' && !strings.HasPrefix(l.src[l.pos:], "\n")) {
+				l.newLine()
+			}
+		}
+	}
+
+	return token{}, l.errorf(loc, "unterminated string")
+}
+
+func blockStringValue(raw string) string {
+	lines := strings.Split(strings.ReplaceAll(strings.ReplaceAll(raw, "\r\n", "\n"), "\r", "\n"), "\n")
+
+	indent := -1
+
+	for _, line := range lines[1:] {
+		trimmed := strings.TrimLeft(line, " \t")
+		if trimmed == "" {
+			continue
+		}
+
+		if n := len(line) - len(trimmed); indent < 0 || n < indent {
+			indent = n
+		}
+	}
+
+	if indent > 0 {
+		for i := 1; i < len(lines); i++ {
+			if len(lines[i]) >= indent {
+				lines[i] = lines[i][indent:]
+			} else {
+				lines[i] = strings.TrimLeft(lines[i], " \t")
+			}
+		}
+	}
+
+	for len(lines) > 0 && strings.Trim(lines[0], " \t") == "" {
+		lines = lines[1:]
+	}
+
+	for len(lines) > 0 && strings.Trim(lines[len(lines)-1], " \t") == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func isLetter(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
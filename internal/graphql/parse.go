@@ -0,0 +1,645 @@
+package graphql
+
+// document is a parsed executable document: operations and fragments.
+type document struct {
+	operations []*operation
+	fragments  map[string]*fragment
+}
+
+type operation struct {
+	// kind is "query", "mutation" or "subscription".
+	kind       string
+	name       string
+	vars       []*varDef
+	directives []*directive
+	selections []selection
+	loc        Location
+}
+
+type varDef struct {
+	name string
+	typ  *typeRef
+	def  *value // nil without a default
+	loc  Location
+}
+
+// typeRef is a type in a variable definition, e.g. [ID!]!.
+type typeRef struct {
+	name    string
+	elem    *typeRef // set for lists
+	nonNull bool
+}
+
+func (t *typeRef) String() string {
+	s := t.name
+	if t.elem != nil {
+		s = "[" + t.elem.String() + "]"
+	}
+
+	if t.nonNull {
+		s += "!"
+	}
+
+	return s
+}
+
+// selection is a *field, *fragmentSpread or *inlineFragment.
+type selection interface {
+	location() Location
+}
+
+type field struct {
+	alias      string
+	name       string
+	args       []*argument
+	directives []*directive
+	selections []selection
+	loc        Location
+}
+
+// key is the name of the field in the response.
+func (f *field) key() string {
+	if f.alias != "" {
+		return f.alias
+	}
+
+	return f.name
+}
+
+type fragmentSpread struct {
+	name       string
+	directives []*directive
+	loc        Location
+}
+
+type inlineFragment struct {
+	typeCond   string // empty applies to any type
+	directives []*directive
+	selections []selection
+	loc        Location
+}
+
+type fragment struct {
+	name       string
+	typeCond   string
+	selections []selection
+	loc        Location
+}
+
+func (f *field) location() Location          { return f.loc }
+func (f *fragmentSpread) location() Location { return f.loc }
+func (f *inlineFragment) location() Location { return f.loc }
+
+type argument struct {
+	name  string
+	value *value
+	loc   Location
+}
+
+type directive struct {
+	name string
+	args []*argument
+	loc  Location
+}
+
+type valueKind int
+
+const (
+	valueVariable valueKind = iota
+	valueInt
+	valueFloat
+	valueString
+	valueBoolean
+	valueNull
+	valueEnum
+	valueList
+	valueObject
+)
+
+// value is an input value literal; raw holds scalars, enum values and variable names.
+type value struct {
+	kind   valueKind
+	raw    string
+	list   []*value
+	fields []*argument
+	loc    Location
+}
+
+// parser is a recursive descent parser of executable documents.
+type parser struct {
+	lex *lexer
+	tok token
+}
+
+// parse parses an executable document. Type system definitions are rejected.
+func parse(src string) (*document, error) {
+	p := &parser{lex: newLexer(src)}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+
+	doc := &document{fragments: make(map[string]*fragment)}
+
+	for {
+		var err error
+
+		switch {
+		case p.tok.kind == tokenEOF:
+			if len(doc.operations) == 0 {
+				return nil, p.lex.errorf(p.tok.loc, "document contains no operations")
+			}
+
+			return doc, nil
+		case p.peek("{"):
+			var op *operation
+
+			op, err = p.shorthand()
+			doc.operations = append(doc.operations, op)
+		case p.tok.kind == tokenName && p.tok.value == "fragment":
+			var f *fragment
+
+			if f, err = p.fragment(); err == nil {
+				if _, ok := doc.fragments[f.name]; ok {
+					return nil, newValidationError(f.loc, "There can be only one fragment named %q.", f.name)
+				}
+
+				doc.fragments[f.name] = f
+			}
+		case p.tok.kind == tokenName &&
+			(p.tok.value == "query" || p.tok.value == "mutation" || p.tok.value == "subscription"):
+			var op *operation
+
+			op, err = p.operation()
+			doc.operations = append(doc.operations, op)
+		default:
+			return nil, p.unexpected()
+		}
+
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (p *parser) advance() error {
+	tok, err := p.lex.next()
+	if err != nil {
+		return err
+	}
+
+	p.tok = tok
+
+	return nil
+}
+
+// peek reports whether the current token is the punctuator s.
+func (p *parser) peek(s string) bool {
+	return p.tok.kind == tokenPunct && p.tok.value == s
+}
+
+// skip consumes the punctuator s if it is the current token.
+func (p *parser) skip(s string) (bool, error) {
+	if !p.peek(s) {
+		return false, nil
+	}
+
+	return true, p.advance()
+}
+
+func (p *parser) expect(s string) error {
+	if !p.peek(s) {
+		return p.lex.errorf(p.tok.loc, "expected %q, found %s", s, p.tok)
+	}
+
+	return p.advance()
+}
+
+func (p *parser) expectKeyword(s string) error {
+	if p.tok.kind != tokenName || p.tok.value != s {
+		return p.lex.errorf(p.tok.loc, "expected %q, found %s", s, p.tok)
+	}
+
+	return p.advance()
+}
+
+func (p *parser) name() (string, error) {
+	if p.tok.kind != tokenName {
+		return "", p.lex.errorf(p.tok.loc, "expected name, found %s", p.tok)
+	}
+
+	name := p.tok.value
+
+	return name, p.advance()
+}
+
+func (p *parser) unexpected() error {
+	return p.lex.errorf(p.tok.loc, "unexpected %s", p.tok)
+}
+
+func (p *parser) shorthand() (*operation, error) {
+	op := &operation{kind: "query", loc: p.tok.loc}
+
+	var err error
+
+	op.selections, err = p.selectionSet()
+
+	return op, err
+}
+
+func (p *parser) operation() (*operation, error) {
+	op := &operation{kind: p.tok.value, loc: p.tok.loc}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+
+	var err error
+
+	if p.tok.kind == tokenName {
+		if op.name, err = p.name(); err != nil {
+			return nil, err
+		}
+	}
+
+	if p.peek("(") {
+		if op.vars, err = p.varDefs(); err != nil {
+			return nil, err
+		}
+	}
+
+	if op.directives, err = p.directives(); err != nil {
+		return nil, err
+	}
+
+	op.selections, err = p.selectionSet()
+
+	return op, err
+}
+
+func (p *parser) varDefs() ([]*varDef, error) {
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+
+	var defs []*varDef
+
+	for !p.peek(")") {
+		def := &varDef{loc: p.tok.loc}
+
+		if err := p.expect("$"); err != nil {
+			return nil, err
+		}
+
+		var err error
+
+		if def.name, err = p.name(); err != nil {
+			return nil, err
+		}
+
+		if err := p.expect(":"); err != nil {
+			return nil, err
+		}
+
+		if def.typ, err = p.typeRef(); err != nil {
+			return nil, err
+		}
+
+		if ok, err := p.skip("="); err != nil {
+			return nil, err
+		} else if ok {
+			if def.def, err = p.value(true); err != nil {
+				return nil, err
+			}
+		}
+
+		// Directives on variables are accepted and ignored.
+		if _, err := p.directives(); err != nil {
+			return nil, err
+		}
+
+		defs = append(defs, def)
+	}
+
+	return defs, p.advance()
+}
+
+func (p *parser) typeRef() (*typeRef, error) {
+	t := &typeRef{}
+
+	if ok, err := p.skip("["); err != nil {
+		return nil, err
+	} else if ok {
+		if t.elem, err = p.typeRef(); err != nil {
+			return nil, err
+		}
+
+		if err := p.expect("]"); err != nil {
+			return nil, err
+		}
+	} else if t.name, err = p.name(); err != nil {
+		return nil, err
+	}
+
+	var err error
+
+	t.nonNull, err = p.skip("!")
+
+	return t, err
+}
+
+func (p *parser) directives() ([]*directive, error) {
+	var dirs []*directive
+
+	for p.peek("@") {
+		d := &directive{loc: p.tok.loc}
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+
+		var err error
+
+		if d.name, err = p.name(); err != nil {
+			return nil, err
+		}
+
+		if d.args, err = p.arguments(false); err != nil {
+			return nil, err
+		}
+
+		dirs = append(dirs, d)
+	}
+
+	return dirs, nil
+}
+
+func (p *parser) selectionSet() ([]selection, error) {
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+
+	var sels []selection
+
+	for !p.peek("}") {
+		if p.tok.kind == tokenEOF {
+			return nil, p.lex.errorf(p.tok.loc, "expected \"}\", found %s", p.tok)
+		}
+
+		sel, err := p.selection()
+		if err != nil {
+			return nil, err
+		}
+
+		sels = append(sels, sel)
+	}
+
+	if len(sels) == 0 {
+		return nil, p.lex.errorf(p.tok.loc, "expected name, found \"}\"")
+	}
+
+	return sels, p.advance()
+}
+
+func (p *parser) selection() (selection, error) {
+	if p.peek("...") {
+		return p.fragmentSelection()
+	}
+
+	f := &field{loc: p.tok.loc}
+
+	var err error
+
+	if f.name, err = p.name(); err != nil {
+		return nil, err
+	}
+
+	if ok, err := p.skip(":"); err != nil {
+		return nil, err
+	} else if ok {
+		f.alias = f.name
+
+		if f.name, err = p.name(); err != nil {
+			return nil, err
+		}
+	}
+
+	if f.args, err = p.arguments(false); err != nil {
+		return nil, err
+	}
+
+	if f.directives, err = p.directives(); err != nil {
+		return nil, err
+	}
+
+	if p.peek("{") {
+		f.selections, err = p.selectionSet()
+	}
+
+	return f, err
+}
+
+func (p *parser) fragmentSelection() (selection, error) {
+	loc := p.tok.loc
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+
+	if p.tok.kind == tokenName && p.tok.value != "on" {
+		spread := &fragmentSpread{loc: loc}
+
+		var err error
+
+		if spread.name, err = p.name(); err != nil {
+			return nil, err
+		}
+
+		spread.directives, err = p.directives()
+
+		return spread, err
+	}
+
+	inline := &inlineFragment{loc: loc}
+
+	var err error
+
+	if p.tok.kind == tokenName {
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+
+		if inline.typeCond, err = p.name(); err != nil {
+			return nil, err
+		}
+	}
+
+	if inline.directives, err = p.directives(); err != nil {
+		return nil, err
+	}
+
+	inline.selections, err = p.selectionSet()
+
+	return inline, err
+}
+
+func (p *parser) fragment() (*fragment, error) {
+	f := &fragment{loc: p.tok.loc}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+
+	var err error
+
+	if f.name, err = p.name(); err != nil {
+		return nil, err
+	}
+
+	if f.name == "on" {
+		return nil, p.lex.errorf(f.loc, "unexpected name \"on\"")
+	}
+
+	if err := p.expectKeyword("on"); err != nil {
+		return nil, err
+	}
+
+	if f.typeCond, err = p.name(); err != nil {
+		return nil, err
+	}
+
+	if _, err := p.directives(); err != nil {
+		return nil, err
+	}
+
+	f.selections, err = p.selectionSet()
+
+	return f, err
+}
+
+// arguments parses an optional argument list; constant forbids variables.
+func (p *parser) arguments(constant bool) ([]*argument, error) {
+	if ok, err := p.skip("("); err != nil || !ok {
+		return nil, err
+	}
+
+	var args []*argument
+
+	for !p.peek(")") {
+		arg, err := p.argument(constant)
+		if err != nil {
+			return nil, err
+		}
+
+		args = append(args, arg)
+	}
+
+	if len(args) == 0 {
+		return nil, p.lex.errorf(p.tok.loc, "expected name, found \")\"")
+	}
+
+	return args, p.advance()
+}
+
+func (p *parser) argument(constant bool) (*argument, error) {
+	arg := &argument{loc: p.tok.loc}
+
+	var err error
+
+	if arg.name, err = p.name(); err != nil {
+		return nil, err
+	}
+
+	if err := p.expect(":"); err != nil {
+		return nil, err
+	}
+
+	arg.value, err = p.value(constant)
+
+	return arg, err
+}
+
+func (p *parser) value(constant bool) (*value, error) {
+	v := &value{loc: p.tok.loc, raw: p.tok.value}
+
+	switch p.tok.kind {
+	case tokenInt:
+		v.kind = valueInt
+	case tokenFloat:
+		v.kind = valueFloat
+	case tokenString:
+		v.kind = valueString
+	case tokenName:
+		switch p.tok.value {
+		case "true", "false":
+			v.kind = valueBoolean
+		case "null":
+			v.kind = valueNull
+		default:
+			v.kind = valueEnum
+		}
+	case tokenPunct:
+		switch p.tok.value {
+		case "$":
+			if constant {
+				return nil, p.unexpected()
+			}
+
+			return p.variable()
+		case "[":
+			return p.listValue(constant)
+		case "{":
+			return p.objectValue(constant)
+		}
+
+		return nil, p.unexpected()
+	default:
+		return nil, p.unexpected()
+	}
+
+	return v, p.advance()
+}
+
+func (p *parser) variable() (*value, error) {
+	v := &value{kind: valueVariable, loc: p.tok.loc}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+
+	var err error
+
+	v.raw, err = p.name()
+
+	return v, err
+}
+
+func (p *parser) listValue(constant bool) (*value, error) {
+	v := &value{kind: valueList, loc: p.tok.loc}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+
+	for !p.peek("]") {
+		item, err := p.value(constant)
+		if err != nil {
+			return nil, err
+		}
+
+		v.list = append(v.list, item)
+	}
+
+	return v, p.advance()
+}
+
+func (p *parser) objectValue(constant bool) (*value, error) {
+	v := &value{kind: valueObject, loc: p.tok.loc}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+
+	for !p.peek("}") {
+		f, err := p.argument(constant)
+		if err != nil {
+			return nil, err
+		}
+
+		v.fields = append(v.fields, f)
+	}
+
+	return v, p.advance()
+}
@@ -0,0 +1,568 @@
+package graphql
+
+import (
+	"encoding/json"
+	"reflect"
+)
+
+// plannedField is a field of the response with its coerced arguments and the
+// fields selected on its type, merged from all selections of the same key.
+type plannedField struct {
+	key  string
+	name string
+	// def is nil for __typename.
+	def        *fieldDef
+	args       map[string]any
+	selections []*plannedField
+	loc        Location
+}
+
+// planner validates an operation against the schema and turns it into
+// plannedFields, checking the depth and complexity limits on the way.
+type planner struct {
+	schema *Schema
+	doc    *document
+	op     *operation
+
+	varDefs map[string]*varDef
+	// vars are the provided and defaulted variable values as decoded from JSON.
+	vars map[string]any
+	// spreading holds the fragments being expanded, to detect cycles.
+	spreading map[string]bool
+	// spreads counts the expanded fragment spreads against maxSpreads.
+	spreads int
+}
+
+// maxSpreads limits the fragment spreads expanded for one operation, so
+// fragments nested under aliased fields cannot make planning exponential.
+const maxSpreads = 1000
+
+func (pl *planner) plan(root *schemaType, vars map[string]any) ([]*plannedField, error) {
+	if err := pl.variables(vars); err != nil {
+		return nil, err
+	}
+
+	pl.spreading = make(map[string]bool)
+
+	fields, err := pl.fields(root, pl.op.selections, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	if c := complexity(fields); c > pl.schema.maxComplexity {
+		return nil, newError(CodeQueryTooComplex, pl.op.loc,
+			"Query complexity %d exceeds the limit of %d.", c, pl.schema.maxComplexity)
+	}
+
+	return fields, nil
+}
+
+// variables checks the variable values against their definitions.
+func (pl *planner) variables(values map[string]any) error {
+	pl.varDefs = make(map[string]*varDef, len(pl.op.vars))
+	pl.vars = make(map[string]any, len(pl.op.vars))
+
+	for _, def := range pl.op.vars {
+		if _, ok := pl.varDefs[def.name]; ok {
+			return newValidationError(def.loc, "There can be only one variable named \"$%s\".", def.name)
+		}
+
+		pl.varDefs[def.name] = def
+
+		t, ok := pl.inputType(def.typ)
+		if !ok {
+			return newValidationError(def.loc, "Variable \"$%s\" cannot be of type %q.", def.name, def.typ)
+		}
+
+		value, ok := values[def.name]
+		if !ok && def.def != nil {
+			var err error
+
+			if value, _, err = pl.literal(def.def, t); err != nil {
+				return err
+			}
+
+			ok = true
+		}
+
+		if !ok {
+			if def.typ.nonNull {
+				return newError(CodeBadUserInput, def.loc,
+					"Variable \"$%s\" of required type %q was not provided.", def.name, def.typ)
+			}
+
+			continue
+		}
+
+		if _, err := coerce(t, value); err != nil {
+			return newError(CodeBadUserInput, def.loc, "Variable \"$%s\" got invalid value: %v.", def.name, err)
+		}
+
+		pl.vars[def.name] = value
+	}
+
+	return nil
+}
+
+// inputType resolves the type of a variable; only scalars and input objects
+// can be inputs.
+func (pl *planner) inputType(ref *typeRef) (*schemaType, bool) {
+	var t *schemaType
+
+	if ref.elem != nil {
+		elem, ok := pl.inputType(ref.elem)
+		if !ok {
+			return nil, false
+		}
+
+		t = listOf(elem)
+	} else if t = pl.schema.types[ref.name]; t == nil {
+		return nil, false
+	}
+
+	if ref.nonNull {
+		t = nonNull(t)
+	}
+
+	return t, true
+}
+
+// group holds the fields selected under one response key.
+type group struct {
+	key    string
+	fields []*field
+}
+
+func (pl *planner) fields(obj *schemaType, sels []selection, depth int) ([]*plannedField, error) {
+	var groups []*group
+
+	if err := pl.collect(obj, sels, make(map[string]bool), &groups); err != nil {
+		return nil, err
+	}
+
+	planned := make([]*plannedField, 0, len(groups))
+
+	for _, g := range groups {
+		pf, err := pl.field(obj, g, depth)
+		if err != nil {
+			return nil, err
+		}
+
+		planned = append(planned, pf)
+	}
+
+	return planned, nil
+}
+
+// collect groups the fields of sels by response key in the order of their
+// first occurrence, expanding fragments and applying skip and include. Each
+// fragment is expanded once per selection set; visited holds those expanded.
+func (pl *planner) collect(obj *schemaType, sels []selection, visited map[string]bool, groups *[]*group) error {
+	for _, sel := range sels {
+		var dirs []*directive
+
+		switch s := sel.(type) {
+		case *field:
+			dirs = s.directives
+		case *fragmentSpread:
+			dirs = s.directives
+		case *inlineFragment:
+			dirs = s.directives
+		}
+
+		include, err := pl.included(dirs)
+		if err != nil {
+			return err
+		}
+
+		if !include {
+			continue
+		}
+
+		switch s := sel.(type) {
+		case *field:
+			addToGroup(groups, s)
+		case *fragmentSpread:
+			err = pl.spread(obj, s, visited, groups)
+		case *inlineFragment:
+			var applies bool
+			if applies, err = pl.applies(obj, s.typeCond, s.loc); err == nil && applies {
+				err = pl.collect(obj, s.selections, visited, groups)
+			}
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func addToGroup(groups *[]*group, f *field) {
+	for _, g := range *groups {
+		if g.key == f.key() {
+			g.fields = append(g.fields, f)
+
+			return
+		}
+	}
+
+	*groups = append(*groups, &group{key: f.key(), fields: []*field{f}})
+}
+
+func (pl *planner) spread(obj *schemaType, s *fragmentSpread, visited map[string]bool, groups *[]*group) error {
+	frag, ok := pl.doc.fragments[s.name]
+	if !ok {
+		return newValidationError(s.loc, "Unknown fragment %q.", s.name)
+	}
+
+	if pl.spreading[s.name] {
+		return newValidationError(s.loc, "Cannot spread fragment %q within itself.", s.name)
+	}
+
+	if visited[s.name] {
+		return nil
+	}
+
+	visited[s.name] = true
+
+	applies, err := pl.applies(obj, frag.typeCond, frag.loc)
+	if err != nil || !applies {
+		return err
+	}
+
+	if pl.spreads++; pl.spreads > maxSpreads {
+		return newError(CodeQueryTooComplex, s.loc, "Query expands more than %d fragment spreads.", maxSpreads)
+	}
+
+	pl.spreading[s.name] = true
+	defer delete(pl.spreading, s.name)
+
+	return pl.collect(obj, frag.selections, visited, groups)
+}
+
+// applies reports whether a fragment with the type condition applies to obj.
+// Without interfaces and unions it is the case only for obj itself.
+func (pl *planner) applies(obj *schemaType, typeCond string, loc Location) (bool, error) {
+	if typeCond == "" || typeCond == obj.name {
+		return true, nil
+	}
+
+	if !pl.schema.isObject(typeCond) {
+		return false, newValidationError(loc, "Unknown type %q.", typeCond)
+	}
+
+	return false, newValidationError(loc,
+		"Fragment cannot be spread here as objects of type %q can never be of type %q.", obj.name, typeCond)
+}
+
+// included evaluates the skip and include directives.
+func (pl *planner) included(dirs []*directive) (bool, error) {
+	for _, d := range dirs {
+		if d.name != "skip" && d.name != "include" {
+			return false, newValidationError(d.loc, "Unknown directive \"@%s\".", d.name)
+		}
+
+		args, err := pl.arguments(d.args, []*inputValue{{name: "if", typ: nonNull(booleanType)}}, "@"+d.name, d.loc)
+		if err != nil {
+			return false, err
+		}
+
+		if args["if"].(bool) == (d.name == "skip") { //nolint:forcetypeassert
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+func (pl *planner) field(obj *schemaType, g *group, depth int) (*plannedField, error) {
+	first := g.fields[0]
+
+	if depth > pl.schema.maxDepth {
+		return nil, newError(CodeQueryTooComplex, first.loc, "Query depth exceeds the limit of %d.", pl.schema.maxDepth)
+	}
+
+	pf := &plannedField{key: g.key, name: first.name, loc: first.loc}
+
+	for _, f := range g.fields[1:] {
+		if f.name != first.name {
+			return nil, newValidationError(f.loc,
+				"Fields %q conflict because %q and %q are different fields.", g.key, first.name, f.name)
+		}
+	}
+
+	if first.name == "__typename" {
+		for _, f := range g.fields {
+			if len(f.args) > 0 || len(f.selections) > 0 {
+				return nil, newValidationError(f.loc, "Field \"__typename\" takes no arguments or selections.")
+			}
+		}
+
+		return pf, nil
+	}
+
+	def := obj.fields[first.name]
+	if def == nil {
+		return nil, newValidationError(first.loc, "Cannot query field %q on type %q.", first.name, obj.name)
+	}
+
+	pf.def = def
+
+	coordinate := obj.name + "." + first.name
+
+	var sels []selection
+
+	// A field reached through several fragments contributes its selections
+	// once, so they are not collected again for every path.
+	seen := make(map[selection]bool)
+
+	for i, f := range g.fields {
+		args, err := pl.arguments(f.args, def.args, coordinate, f.loc)
+		if err != nil {
+			return nil, err
+		}
+
+		if i == 0 {
+			pf.args = args
+		} else if !reflect.DeepEqual(args, pf.args) {
+			return nil, newValidationError(f.loc,
+				"Fields %q conflict because they have differing arguments.", g.key)
+		}
+
+		for _, sel := range f.selections {
+			if !seen[sel] {
+				seen[sel] = true
+				sels = append(sels, sel)
+			}
+		}
+	}
+
+	named := def.typ.named()
+
+	if named.kind != kindObject {
+		if len(sels) > 0 {
+			return nil, newValidationError(first.loc,
+				"Field %q must not have a selection since type %q has no subfields.", first.name, def.typ)
+		}
+
+		return pf, nil
+	}
+
+	if len(sels) == 0 {
+		return nil, newValidationError(first.loc,
+			"Field %q of type %q must have a selection of subfields.", first.name, def.typ)
+	}
+
+	var err error
+
+	pf.selections, err = pl.fields(named, sels, depth+1)
+
+	return pf, err
+}
+
+// arguments coerces the arguments given to a field or directive.
+func (pl *planner) arguments(given []*argument, defs []*inputValue, coordinate string, loc Location) (map[string]any, error) {
+	byName := make(map[string]*argument, len(given))
+
+	for _, a := range given {
+		if _, ok := byName[a.name]; ok {
+			return nil, newValidationError(a.loc, "There can be only one argument named %q.", a.name)
+		}
+
+		byName[a.name] = a
+	}
+
+	args := make(map[string]any, len(defs))
+
+	for _, def := range defs {
+		a, ok := byName[def.name]
+		delete(byName, def.name)
+
+		var raw any
+
+		if ok {
+			var err error
+
+			if raw, ok, err = pl.literal(a.value, def.typ); err != nil {
+				return nil, err
+			}
+		}
+
+		switch {
+		case ok:
+			value, err := coerce(def.typ, raw)
+			if err != nil {
+				return nil, newError(CodeBadUserInput, a.loc, "Argument %q of %q has invalid value: %v.", def.name, coordinate, err)
+			}
+
+			args[def.name] = value
+		case def.hasDef:
+			args[def.name] = def.def
+		case def.typ.kind == kindNonNull:
+			return nil, newValidationError(loc,
+				"Argument %q of type %q is required for %q, but it was not provided.", def.name, def.typ, coordinate)
+		}
+	}
+
+	for _, a := range byName {
+		return nil, newValidationError(a.loc, "Unknown argument %q on %q.", a.name, coordinate)
+	}
+
+	return args, nil
+}
+
+// literal converts a value literal expected to be of type t into the form
+// of decoded JSON, substituting variables. It reports whether the value is
+// present: a variable without a value leaves the argument or field unset.
+func (pl *planner) literal(v *value, t *schemaType) (any, bool, error) {
+	switch v.kind {
+	case valueVariable:
+		def, ok := pl.varDefs[v.raw]
+		if !ok {
+			return nil, false, newValidationError(v.loc, "Variable \"$%s\" is not defined.", v.raw)
+		}
+
+		if t != nil && !compatible(def, t) {
+			return nil, false, newValidationError(v.loc,
+				"Variable \"$%s\" of type %q used in position expecting type %q.", v.raw, def.typ, t)
+		}
+
+		value, ok := pl.vars[v.raw]
+
+		return value, ok, nil
+	case valueInt, valueFloat:
+		return json.Number(v.raw), true, nil
+	case valueString:
+		return v.raw, true, nil
+	case valueBoolean:
+		return v.raw == "true", true, nil
+	case valueNull:
+		return nil, true, nil
+	case valueEnum:
+		return enumValue(v.raw), true, nil
+	case valueList:
+		return pl.listLiteral(v, t)
+	default:
+		return pl.objectLiteral(v, t)
+	}
+}
+
+func (pl *planner) listLiteral(v *value, t *schemaType) (any, bool, error) {
+	var elem *schemaType
+
+	if t != nil {
+		if t.kind == kindNonNull {
+			t = t.of
+		}
+
+		if t.kind == kindList {
+			elem = t.of
+		}
+	}
+
+	items := make([]any, 0, len(v.list))
+
+	for _, item := range v.list {
+		value, _, err := pl.literal(item, elem)
+		if err != nil {
+			return nil, false, err
+		}
+
+		items = append(items, value)
+	}
+
+	return items, true, nil
+}
+
+func (pl *planner) objectLiteral(v *value, t *schemaType) (any, bool, error) {
+	if t != nil {
+		t = t.named()
+	}
+
+	m := make(map[string]any, len(v.fields))
+
+	for _, f := range v.fields {
+		if _, ok := m[f.name]; ok {
+			return nil, false, newValidationError(f.loc, "There can be only one input field named %q.", f.name)
+		}
+
+		var ft *schemaType
+
+		if t != nil && t.kind == kindInputObject {
+			if in := inputField(t, f.name); in != nil {
+				ft = in.typ
+			}
+		}
+
+		value, ok, err := pl.literal(f.value, ft)
+		if err != nil {
+			return nil, false, err
+		}
+
+		if ok {
+			m[f.name] = value
+		}
+	}
+
+	return m, true, nil
+}
+
+// compatible reports whether a variable may be used where type t is expected.
+// A nullable variable fits a non-null position if it has a default.
+func compatible(def *varDef, t *schemaType) bool {
+	ref := def.typ
+
+	if t.kind == kindNonNull {
+		if !ref.nonNull && (def.def == nil || def.def.kind == valueNull) {
+			return false
+		}
+
+		t = t.of
+	}
+
+	return sameType(&typeRef{name: ref.name, elem: ref.elem}, t)
+}
+
+func sameType(ref *typeRef, t *schemaType) bool {
+	if ref.nonNull {
+		if t.kind == kindNonNull {
+			t = t.of
+		}
+
+		ref = &typeRef{name: ref.name, elem: ref.elem}
+	} else if t.kind == kindNonNull {
+		return false
+	}
+
+	if t.kind == kindList {
+		return ref.elem != nil && sameType(ref.elem, t.of)
+	}
+
+	return ref.elem == nil && ref.name == t.name
+}
+
+// complexity counts the fields resolved for the selections, multiplying the
+// selections of connections by their page size.
+func complexity(fields []*plannedField) int {
+	total := 0
+
+	for _, f := range fields {
+		n := 1
+
+		if f.def != nil && f.def.multiplier != nil {
+			n = f.def.multiplier(f.args)
+		}
+
+		total += 1 + n*complexity(f.selections)
+	}
+
+	return total
+}
+
+func (s *Schema) isObject(name string) bool {
+	_, ok := s.objects[name]
+
+	return ok
+}
@@ -0,0 +1,334 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+)
+
+type typeKind int
+
+const (
+	kindScalar typeKind = iota
+	kindObject
+	kindInputObject
+	kindList
+	kindNonNull
+)
+
+// schemaType is a type of the schema. Lists and non-null types wrap the
+// type in of; named types carry their fields or scalar conversions.
+type schemaType struct {
+	kind typeKind
+	name string
+	of   *schemaType
+
+	// fields are the fields of an object type.
+	fields map[string]*fieldDef
+	// inputFields are the fields of an input object type.
+	inputFields []*inputValue
+
+	// serialize converts a resolved value into its JSON representation and
+	// parse an input value into the Go value resolvers receive.
+	serialize func(v any) (any, error)
+	parse     func(v any) (any, error)
+}
+
+func (t *schemaType) String() string {
+	switch t.kind {
+	case kindList:
+		return "[" + t.of.String() + "]"
+	case kindNonNull:
+		return t.of.String() + "!"
+	default:
+		return t.name
+	}
+}
+
+// named returns the named type wrapped by lists and non-null types.
+func (t *schemaType) named() *schemaType {
+	for t.of != nil {
+		t = t.of
+	}
+
+	return t
+}
+
+func nonNull(t *schemaType) *schemaType {
+	return &schemaType{kind: kindNonNull, of: t}
+}
+
+func listOf(t *schemaType) *schemaType {
+	return &schemaType{kind: kindList, of: t}
+}
+
+// resolver returns the value of a field of source. Lists are returned as []any.
+type resolver func(ctx context.Context, source any, args map[string]any) (any, error)
+
+type fieldDef struct {
+	typ     *schemaType
+	args    []*inputValue
+	resolve resolver
+	// multiplier returns how many times the selections of the field are
+	// resolved, e.g. the page size of a connection; nil means once.
+	multiplier func(args map[string]any) int
+}
+
+// inputValue is an argument or a field of an input object.
+type inputValue struct {
+	name string
+	typ  *schemaType
+	// def is the default value, applied when the input is not provided.
+	def    any
+	hasDef bool
+}
+
+// Built-in scalars and the DateTime scalar in RFC 3339 format.
+var (
+	intType = &schemaType{
+		kind: kindScalar, name: "Int",
+		serialize: func(v any) (any, error) {
+			n, ok := v.(int)
+			if !ok || n < math.MinInt32 || n > math.MaxInt32 {
+				return nil, fmt.Errorf("Int cannot represent %v", v)
+			}
+
+			return n, nil
+		},
+		parse: parseInt,
+	}
+	stringType = &schemaType{
+		kind: kindScalar, name: "String",
+		serialize: func(v any) (any, error) {
+			s, ok := v.(string)
+			if !ok {
+				return nil, fmt.Errorf("String cannot represent %v", v)
+			}
+
+			return s, nil
+		},
+		parse: func(v any) (any, error) {
+			s, ok := v.(string)
+			if !ok {
+				return nil, fmt.Errorf("String cannot represent a non string value: %s", describe(v))
+			}
+
+			return s, nil
+		},
+	}
+	booleanType = &schemaType{
+		kind: kindScalar, name: "Boolean",
+		serialize: func(v any) (any, error) {
+			b, ok := v.(bool)
+			if !ok {
+				return nil, fmt.Errorf("Boolean cannot represent %v", v)
+			}
+
+			return b, nil
+		},
+		parse: func(v any) (any, error) {
+			b, ok := v.(bool)
+			if !ok {
+				return nil, fmt.Errorf("Boolean cannot represent a non boolean value: %s", describe(v))
+			}
+
+			return b, nil
+		},
+	}
+	// idType is serialized as a string; inputs may also be integers.
+	idType = &schemaType{
+		kind: kindScalar, name: "ID",
+		serialize: func(v any) (any, error) {
+			switch id := v.(type) {
+			case int:
+				return strconv.Itoa(id), nil
+			case string:
+				return id, nil
+			default:
+				return nil, fmt.Errorf("ID cannot represent %v", v)
+			}
+		},
+		parse: func(v any) (any, error) {
+			if s, ok := v.(string); ok {
+				return s, nil
+			}
+
+			n, err := parseInt(v)
+			if err != nil {
+				return nil, fmt.Errorf("ID cannot represent value: %s", describe(v))
+			}
+
+			return strconv.Itoa(n.(int)), nil //nolint:forcetypeassert
+		},
+	}
+	dateTimeType = &schemaType{
+		kind: kindScalar, name: "DateTime",
+		serialize: func(v any) (any, error) {
+			t, ok := v.(time.Time)
+			if !ok {
+				return nil, fmt.Errorf("DateTime cannot represent %v", v)
+			}
+
+			return t.Format(time.RFC3339Nano), nil
+		},
+		parse: func(v any) (any, error) {
+			s, ok := v.(string)
+			if !ok {
+				return nil, fmt.Errorf("DateTime cannot represent a non string value: %s", describe(v))
+			}
+
+			t, err := time.Parse(time.RFC3339Nano, s)
+			if err != nil {
+				return nil, fmt.Errorf("DateTime must be in RFC 3339 format: %q", s)
+			}
+
+			return t, nil
+		},
+	}
+)
+
+func parseInt(v any) (any, error) {
+	var n int64
+
+	switch x := v.(type) {
+	case json.Number:
+		i, err := strconv.ParseInt(string(x), 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("Int cannot represent non-integer value: %s", x)
+		}
+
+		n = i
+	case float64:
+		if x != math.Trunc(x) || x < math.MinInt32 || x > math.MaxInt32 {
+			return nil, fmt.Errorf("Int cannot represent non-integer value: %v", x)
+		}
+
+		n = int64(x)
+	case int:
+		n = int64(x)
+	default:
+		return nil, fmt.Errorf("Int cannot represent non-integer value: %s", describe(v))
+	}
+
+	if n < math.MinInt32 || n > math.MaxInt32 {
+		return nil, fmt.Errorf("Int cannot represent non 32-bit signed integer value: %d", n)
+	}
+
+	return int(n), nil
+}
+
+// enumValue is an enum literal; no input type of the schema accepts one.
+type enumValue string
+
+func describe(v any) string {
+	switch x := v.(type) {
+	case string:
+		return strconv.Quote(x)
+	case enumValue:
+		return string(x)
+	case nil:
+		return "null"
+	case []any:
+		return "a list"
+	case map[string]any:
+		return "an object"
+	default:
+		return fmt.Sprint(x)
+	}
+}
+
+var errNull = errors.New("expected non-null value, found null")
+
+// coerce converts an input value, decoded from JSON or a literal, into the Go
+// value of type t: scalars are parsed, input objects become maps holding the
+// provided and defaulted fields, and a single value is a list of one item.
+func coerce(t *schemaType, v any) (any, error) {
+	if t.kind == kindNonNull {
+		if v == nil {
+			return nil, errNull
+		}
+
+		return coerce(t.of, v)
+	}
+
+	if v == nil {
+		return nil, nil
+	}
+
+	switch t.kind {
+	case kindList:
+		items, ok := v.([]any)
+		if !ok {
+			item, err := coerce(t.of, v)
+			if err != nil {
+				return nil, err
+			}
+
+			return []any{item}, nil
+		}
+
+		out := make([]any, len(items))
+
+		for i, item := range items {
+			var err error
+			if out[i], err = coerce(t.of, item); err != nil {
+				return nil, fmt.Errorf("at index %d: %w", i, err)
+			}
+		}
+
+		return out, nil
+	case kindInputObject:
+		return coerceObject(t, v)
+	default:
+		return t.parse(v)
+	}
+}
+
+func coerceObject(t *schemaType, v any) (any, error) {
+	m, ok := v.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("expected type %s to be an object, found %s", t.name, describe(v))
+	}
+
+	out := make(map[string]any, len(m))
+
+	for name := range m {
+		if inputField(t, name) == nil {
+			return nil, fmt.Errorf("field %q is not defined by type %s", name, t.name)
+		}
+	}
+
+	for _, f := range t.inputFields {
+		value, ok := m[f.name]
+
+		switch {
+		case ok:
+			c, err := coerce(f.typ, value)
+			if err != nil {
+				return nil, fmt.Errorf("at field %q: %w", f.name, err)
+			}
+
+			out[f.name] = c
+		case f.hasDef:
+			out[f.name] = f.def
+		case f.typ.kind == kindNonNull:
+			return nil, fmt.Errorf("field %q of required type %s was not provided", f.name, f.typ)
+		}
+	}
+
+	return out, nil
+}
+
+func inputField(t *schemaType, name string) *inputValue {
+	for _, f := range t.inputFields {
+		if f.name == name {
+			return f
+		}
+	}
+
+	return nil
+}
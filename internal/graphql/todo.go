@@ -0,0 +1,454 @@
+package graphql
+
+import (
+	"cmp"
+	"context"
+	"encoding/base64"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"ecom-internship/internal/config"
+	"ecom-internship/internal/database"
+	"ecom-internship/internal/model"
+	"ecom-internship/internal/validation"
+)
+
+// Page sizes of the todos connection.
+const (
+	defaultPageSize = 50
+	maxPageSize     = 100
+)
+
+// cursorPrefix tells cursors apart from arbitrary strings.
+const cursorPrefix = "todo:"
+
+// resolvers resolve the fields of the todo schema on the storage.
+type resolvers struct {
+	db database.Database
+	v  *validation.Validator
+}
+
+// New creates the schema serving db. Created and updated todos are validated
+// with v, like those of the REST API.
+//
+//	type Query {
+//	  todo(id: ID!): Todo
+//	  todos(filter: TodoFilter, first: Int = 50, after: String): TodoConnection!
+//	}
+//
+//	type Mutation {
+//	  createTodo(input: CreateTodoInput!): Todo!
+//	  updateTodo(id: ID!, input: UpdateTodoInput!): Todo!
+//	  deleteTodo(id: ID!): ID!
+//	  toggleTodo(id: ID!): Todo!
+//	}
+func New(cfg *config.GraphQLConfig, db database.Database, v *validation.Validator) *Schema {
+	r := &resolvers{db: db, v: v}
+
+	todo := &schemaType{kind: kindObject, name: "Todo", fields: map[string]*fieldDef{
+		"id":          todoField(nonNull(idType), func(t model.ToDo) any { return t.ID }),
+		"caption":     todoField(nonNull(stringType), func(t model.ToDo) any { return t.Caption }),
+		"description": todoField(nonNull(stringType), func(t model.ToDo) any { return t.Description }),
+		"isCompleted": todoField(nonNull(booleanType), func(t model.ToDo) any { return t.IsCompleted }),
+		"dueAt": todoField(dateTimeType, func(t model.ToDo) any {
+			if t.DueAt == nil {
+				return nil
+			}
+
+			return *t.DueAt
+		}),
+		"createdAt": todoField(nonNull(dateTimeType), func(t model.ToDo) any { return t.CreatedAt }),
+		"updatedAt": todoField(nonNull(dateTimeType), func(t model.ToDo) any { return t.UpdatedAt }),
+	}}
+
+	edge := &schemaType{kind: kindObject, name: "TodoEdge", fields: map[string]*fieldDef{
+		"cursor": todoField(nonNull(stringType), func(t model.ToDo) any { return encodeCursor(t.ID) }),
+		"node":   todoField(nonNull(todo), func(t model.ToDo) any { return t }),
+	}}
+
+	pageInfo := &schemaType{kind: kindObject, name: "PageInfo", fields: map[string]*fieldDef{
+		"hasNextPage": connectionField(nonNull(booleanType), func(c *connection) any { return c.hasNext }),
+		"endCursor": connectionField(stringType, func(c *connection) any {
+			if len(c.todos) == 0 {
+				return nil
+			}
+
+			return encodeCursor(c.todos[len(c.todos)-1].ID)
+		}),
+	}}
+
+	conn := &schemaType{kind: kindObject, name: "TodoConnection", fields: map[string]*fieldDef{
+		"edges":      connectionField(nonNull(listOf(nonNull(edge))), func(c *connection) any { return c.items() }),
+		"nodes":      connectionField(nonNull(listOf(nonNull(todo))), func(c *connection) any { return c.items() }),
+		"pageInfo":   connectionField(nonNull(pageInfo), func(c *connection) any { return c }),
+		"totalCount": connectionField(nonNull(intType), func(c *connection) any { return c.total }),
+	}}
+
+	filter := &schemaType{kind: kindInputObject, name: "TodoFilter", inputFields: []*inputValue{
+		{name: "completed", typ: booleanType},
+		{name: "query", typ: stringType},
+		{name: "dueBefore", typ: dateTimeType},
+		{name: "dueAfter", typ: dateTimeType},
+	}}
+
+	createInput := &schemaType{kind: kindInputObject, name: "CreateTodoInput", inputFields: []*inputValue{
+		{name: "id", typ: idType},
+		{name: "caption", typ: nonNull(stringType)},
+		{name: "description", typ: stringType},
+		{name: "isCompleted", typ: booleanType},
+		{name: "dueAt", typ: dateTimeType},
+	}}
+
+	updateInput := &schemaType{kind: kindInputObject, name: "UpdateTodoInput", inputFields: []*inputValue{
+		{name: "caption", typ: stringType},
+		{name: "description", typ: stringType},
+		{name: "isCompleted", typ: booleanType},
+		{name: "dueAt", typ: dateTimeType},
+	}}
+
+	idArg := &inputValue{name: "id", typ: nonNull(idType)}
+
+	query := &schemaType{kind: kindObject, name: "Query", fields: map[string]*fieldDef{
+		"todo": {typ: todo, args: []*inputValue{idArg}, resolve: r.todo},
+		"todos": {
+			typ: nonNull(conn),
+			args: []*inputValue{
+				{name: "filter", typ: filter},
+				{name: "first", typ: intType, def: defaultPageSize, hasDef: true},
+				{name: "after", typ: stringType},
+			},
+			resolve:    r.todos,
+			multiplier: pageSize,
+		},
+	}}
+
+	mutation := &schemaType{kind: kindObject, name: "Mutation", fields: map[string]*fieldDef{
+		"createTodo": {
+			typ:     nonNull(todo),
+			args:    []*inputValue{{name: "input", typ: nonNull(createInput)}},
+			resolve: r.createTodo,
+		},
+		"updateTodo": {
+			typ:     nonNull(todo),
+			args:    []*inputValue{idArg, {name: "input", typ: nonNull(updateInput)}},
+			resolve: r.updateTodo,
+		},
+		"deleteTodo": {typ: nonNull(idType), args: []*inputValue{idArg}, resolve: r.deleteTodo},
+		"toggleTodo": {typ: nonNull(todo), args: []*inputValue{idArg}, resolve: r.toggleTodo},
+	}}
+
+	s := &Schema{
+		query:         query,
+		mutation:      mutation,
+		types:         make(map[string]*schemaType),
+		objects:       make(map[string]*schemaType),
+		maxDepth:      cfg.MaxDepth,
+		maxComplexity: cfg.MaxComplexity,
+	}
+
+	for _, t := range []*schemaType{intType, stringType, booleanType, idType, dateTimeType, filter, createInput, updateInput} {
+		s.types[t.name] = t
+	}
+
+	for _, t := range []*schemaType{todo, edge, pageInfo, conn, query, mutation} {
+		s.objects[t.name] = t
+	}
+
+	return s
+}
+
+func todoField(t *schemaType, get func(model.ToDo) any) *fieldDef {
+	return &fieldDef{typ: t, resolve: func(_ context.Context, source any, _ map[string]any) (any, error) {
+		return get(source.(model.ToDo)), nil //nolint:forcetypeassert
+	}}
+}
+
+func connectionField(t *schemaType, get func(*connection) any) *fieldDef {
+	return &fieldDef{typ: t, resolve: func(_ context.Context, source any, _ map[string]any) (any, error) {
+		return get(source.(*connection)), nil //nolint:forcetypeassert
+	}}
+}
+
+// connection is a page of todos.
+type connection struct {
+	todos   []model.ToDo
+	hasNext bool
+	// total counts the todos matching the filter on all pages.
+	total int
+}
+
+func (c *connection) items() any {
+	items := make([]any, len(c.todos))
+	for i, todo := range c.todos {
+		items[i] = todo
+	}
+
+	return items
+}
+
+// pageSize weighs the selections of a connection by the number of todos it returns.
+func pageSize(args map[string]any) int {
+	return max(first(args), 1)
+}
+
+// first returns the requested page size; an explicit null selects the default.
+func first(args map[string]any) int {
+	if n, ok := args["first"].(int); ok {
+		return n
+	}
+
+	return defaultPageSize
+}
+
+func badInput(msg string) error {
+	return &Error{Message: msg, Extensions: map[string]any{"code": CodeBadUserInput}}
+}
+
+func todoID(args map[string]any) (int, error) {
+	s, _ := args["id"].(string)
+
+	id, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, badInput("Invalid todo id " + strconv.Quote(s))
+	}
+
+	return id, nil
+}
+
+func (r *resolvers) todo(ctx context.Context, _ any, args map[string]any) (any, error) {
+	id, err := todoID(args)
+	if err != nil {
+		return nil, err
+	}
+
+	todo, err := r.db.GetToDoByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return todo, nil
+}
+
+// todos returns a page of the todos matching the filter, ordered by ID.
+// Pages continue after the ID in the cursor, so items created or deleted in
+// the meantime do not shift them.
+func (r *resolvers) todos(ctx context.Context, _ any, args map[string]any) (any, error) {
+	size := first(args)
+	if size < 0 || size > maxPageSize {
+		return nil, badInput("Argument \"first\" must be between 0 and " + strconv.Itoa(maxPageSize))
+	}
+
+	after := 0
+
+	if cursor, ok := args["after"].(string); ok {
+		var err error
+
+		if after, err = decodeCursor(cursor); err != nil {
+			return nil, badInput("Invalid cursor " + strconv.Quote(cursor))
+		}
+	}
+
+	filter, _ := args["filter"].(map[string]any)
+
+	conn := &connection{}
+
+	var page []model.ToDo
+
+	err := r.stream(ctx, func(todo model.ToDo) error {
+		if !matches(todo, filter) {
+			return nil
+		}
+
+		conn.total++
+
+		if todo.ID > after {
+			page = append(page, todo)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	slices.SortFunc(page, func(a, b model.ToDo) int { return cmp.Compare(a.ID, b.ID) })
+
+	conn.hasNext = len(page) > size
+	conn.todos = page[:min(size, len(page))]
+
+	return conn, nil
+}
+
+func matches(todo model.ToDo, filter map[string]any) bool {
+	if completed, ok := filter["completed"].(bool); ok && todo.IsCompleted != completed {
+		return false
+	}
+
+	if query, ok := filter["query"].(string); ok {
+		query = strings.ToLower(query)
+
+		if !strings.Contains(strings.ToLower(todo.Caption), query) &&
+			!strings.Contains(strings.ToLower(todo.Description), query) {
+			return false
+		}
+	}
+
+	// Todos without a due date match neither bound.
+	if before, ok := filter["dueBefore"].(time.Time); ok && (todo.DueAt == nil || !todo.DueAt.Before(before)) {
+		return false
+	}
+
+	if after, ok := filter["dueAfter"].(time.Time); ok && (todo.DueAt == nil || !todo.DueAt.After(after)) {
+		return false
+	}
+
+	return true
+}
+
+func (r *resolvers) stream(ctx context.Context, fn func(model.ToDo) error) error {
+	if streamer, ok := r.db.(database.Streamer); ok {
+		return streamer.StreamToDos(ctx, fn)
+	}
+
+	toDos, err := r.db.GetAllToDos(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, toDo := range toDos {
+		if err := fn(toDo); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func encodeCursor(id int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(cursorPrefix + strconv.Itoa(id)))
+}
+
+func decodeCursor(cursor string) (int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, err
+	}
+
+	id, ok := strings.CutPrefix(string(b), cursorPrefix)
+	if !ok {
+		return 0, strconv.ErrSyntax
+	}
+
+	return strconv.Atoi(id)
+}
+
+func (r *resolvers) createTodo(ctx context.Context, _ any, args map[string]any) (any, error) {
+	input, _ := args["input"].(map[string]any)
+
+	var todo model.ToDo
+
+	if id, ok := input["id"].(string); ok {
+		var err error
+
+		if todo.ID, err = strconv.Atoi(id); err != nil {
+			return nil, badInput("Invalid todo id " + strconv.Quote(id))
+		}
+	}
+
+	apply(&todo, input)
+
+	if err := r.v.ToDo(todo); err != nil {
+		return nil, err
+	}
+
+	id, err := r.db.CreateToDo(ctx, todo)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.db.GetToDoByID(ctx, id)
+}
+
+// updateTodo changes the provided fields of a todo; a null dueAt removes
+// the due date, other null fields are left unchanged.
+func (r *resolvers) updateTodo(ctx context.Context, _ any, args map[string]any) (any, error) {
+	id, err := todoID(args)
+	if err != nil {
+		return nil, err
+	}
+
+	todo, err := r.db.GetToDoByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	apply(&todo, args["input"].(map[string]any)) //nolint:forcetypeassert
+
+	return r.save(ctx, todo)
+}
+
+func (r *resolvers) deleteTodo(ctx context.Context, _ any, args map[string]any) (any, error) {
+	id, err := todoID(args)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.db.DeleteToDo(ctx, id); err != nil {
+		return nil, err
+	}
+
+	return id, nil
+}
+
+func (r *resolvers) toggleTodo(ctx context.Context, _ any, args map[string]any) (any, error) {
+	id, err := todoID(args)
+	if err != nil {
+		return nil, err
+	}
+
+	todo, err := r.db.GetToDoByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	todo.IsCompleted = !todo.IsCompleted
+
+	return r.save(ctx, todo)
+}
+
+func (r *resolvers) save(ctx context.Context, todo model.ToDo) (any, error) {
+	if err := r.v.ToDo(todo); err != nil {
+		return nil, err
+	}
+
+	if err := r.db.UpdateToDo(ctx, todo); err != nil {
+		return nil, err
+	}
+
+	return r.db.GetToDoByID(ctx, todo.ID)
+}
+
+// apply sets the fields of a create or update input on todo.
+func apply(todo *model.ToDo, input map[string]any) {
+	if caption, ok := input["caption"].(string); ok {
+		todo.Caption = caption
+	}
+
+	if description, ok := input["description"].(string); ok {
+		todo.Description = description
+	}
+
+	if completed, ok := input["isCompleted"].(bool); ok {
+		todo.IsCompleted = completed
+	}
+
+	if dueAt, ok := input["dueAt"]; ok {
+		if t, ok := dueAt.(time.Time); ok {
+			todo.DueAt = &t
+		} else {
+			todo.DueAt = nil
+		}
+	}
+}
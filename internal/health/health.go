@@ -0,0 +1,142 @@
+// Package health implements liveness and readiness checks.
+package health
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultTimeout bounds a single readiness check.
+const DefaultTimeout = 2 * time.Second
+
+// Check statuses.
+const (
+	StatusOK   = "ok"
+	StatusFail = "fail"
+)
+
+// ErrShuttingDown is reported by readiness once shutdown has begun.
+var ErrShuttingDown = errors.New("server is shutting down")
+
+// Check verifies a single dependency.
+type Check func(ctx context.Context) error
+
+type namedCheck struct {
+	name  string
+	check Check
+}
+
+// Checker aggregates readiness checks.
+type Checker struct {
+	timeout      time.Duration
+	shuttingDown atomic.Bool
+
+	mu     sync.RWMutex
+	checks []namedCheck
+}
+
+// Result is the outcome of a single check.
+type Result struct {
+	Status  string `json:"status"`
+	Latency string `json:"latency"`
+	Error   string `json:"error,omitempty"`
+}
+
+// Report is the outcome of all checks.
+type Report struct {
+	Status string            `json:"status"`
+	Checks map[string]Result `json:"checks,omitempty"`
+}
+
+// OK reports whether all checks passed.
+func (r Report) OK() bool {
+	return r.Status == StatusOK
+}
+
+// New creates a Checker running each check with the given timeout.
+func New(timeout time.Duration) *Checker {
+	return &Checker{timeout: timeout}
+}
+
+// Register adds a named readiness check.
+func (c *Checker) Register(name string, check Check) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.checks = append(c.checks, namedCheck{name: name, check: check})
+}
+
+// SetShuttingDown makes readiness fail so that load balancers stop routing traffic.
+func (c *Checker) SetShuttingDown() {
+	c.shuttingDown.Store(true)
+}
+
+// ShuttingDown reports whether shutdown has begun.
+func (c *Checker) ShuttingDown() bool {
+	return c.shuttingDown.Load()
+}
+
+// Live reports the liveness of the process, which has no dependency checks.
+func (c *Checker) Live() Report {
+	return Report{Status: StatusOK}
+}
+
+// Ready runs all checks concurrently and reports their results.
+func (c *Checker) Ready(ctx context.Context) Report {
+	c.mu.RLock()
+	checks := c.checks
+	c.mu.RUnlock()
+
+	report := Report{Status: StatusOK, Checks: make(map[string]Result, len(checks)+1)}
+
+	if c.ShuttingDown() {
+		report.Status = StatusFail
+		report.Checks["shutdown"] = Result{Status: StatusFail, Latency: "0s", Error: ErrShuttingDown.Error()}
+	}
+
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+
+	for _, nc := range checks {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			res := c.run(ctx, nc.check)
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			report.Checks[nc.name] = res
+			if res.Status != StatusOK {
+				report.Status = StatusFail
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	return report
+}
+
+func (c *Checker) run(ctx context.Context, check Check) Result {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := check(ctx)
+	res := Result{Status: StatusOK, Latency: time.Since(start).String()}
+
+	if err != nil {
+		res.Status = StatusFail
+		res.Error = err.Error()
+	}
+
+	return res
+}
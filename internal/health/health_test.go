@@ -0,0 +1,54 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestChecker_Ready(t *testing.T) {
+	c := New(50 * time.Millisecond)
+	c.Register("ok", func(context.Context) error { return nil })
+
+	report := c.Ready(context.Background())
+	if !report.OK() || report.Checks["ok"].Status != StatusOK || report.Checks["ok"].Latency == "" {
+		t.Errorf("Expected passing report, got %+v", report)
+	}
+
+	c.Register("slow", func(ctx context.Context) error {
+		<-ctx.Done()
+
+		return ctx.Err()
+	})
+	c.Register("broken", func(context.Context) error { return errors.New("connection refused") })
+
+	report = c.Ready(context.Background())
+	if report.OK() {
+		t.Error("Expected failing report")
+	}
+	if report.Checks["slow"].Error != context.DeadlineExceeded.Error() {
+		t.Errorf("Expected slow check to time out, got %+v", report.Checks["slow"])
+	}
+	if report.Checks["broken"].Error != "connection refused" || report.Checks["ok"].Status != StatusOK {
+		t.Errorf("Unexpected checks: %+v", report.Checks)
+	}
+}
+
+func TestChecker_ShuttingDown(t *testing.T) {
+	c := New(DefaultTimeout)
+
+	if !c.Ready(context.Background()).OK() {
+		t.Fatal("Expected ready before shutdown")
+	}
+
+	c.SetShuttingDown()
+
+	report := c.Ready(context.Background())
+	if report.OK() || report.Checks["shutdown"].Error != ErrShuttingDown.Error() {
+		t.Errorf("Expected readiness to fail after shutdown, got %+v", report)
+	}
+	if !c.Live().OK() {
+		t.Error("Expected liveness to be unaffected by shutdown")
+	}
+}
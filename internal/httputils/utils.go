@@ -6,7 +6,10 @@ import (
 	"crypto/rand"
 	"encoding/hex"
 	"fmt"
+	"net"
 	"net/http"
+	"net/netip"
+	"strings"
 	"time"
 )
 
@@ -17,22 +20,73 @@ const (
 	// RequestIDKey is used to store request ID in context.
 	// Using custom type to avoid collisions with other packages.
 	RequestIDKey KeyType = iota
+	// DrainingKey is used to store the server shutdown signal in context.
+	DrainingKey
+)
+
+// RequestIDHeader carries the request ID in requests and responses.
+const RequestIDHeader = "X-Request-ID"
+
+const (
+	// maxRequestIDLength bounds the length of an accepted incoming request ID.
+	maxRequestIDLength = 128
+	// requestIDRandomBytes is the amount of randomness in a generated request ID.
+	requestIDRandomBytes = 12
 )
 
 // RequestID extracts the request ID from the context.
 func RequestID(r *http.Request) string {
-	if id, ok := r.Context().Value(RequestIDKey).(string); ok {
+	return RequestIDFromContext(r.Context())
+}
+
+// RequestIDFromContext extracts the request ID from ctx.
+func RequestIDFromContext(ctx context.Context) string {
+	if id, ok := ctx.Value(RequestIDKey).(string); ok {
 		return id
 	}
 
 	return ""
 }
 
+// ValidRequestID reports whether an incoming request ID is safe to reuse:
+// non-empty, at most 128 characters of letters, digits and "-_.:".
+func ValidRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+
+	for _, c := range id {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case c == '-', c == '_', c == '.', c == ':':
+		default:
+			return false
+		}
+	}
+
+	return true
+}
+
 // WithRequestID adds a request ID to the context.
 func WithRequestID(ctx context.Context, id string) context.Context {
 	return context.WithValue(ctx, RequestIDKey, id)
 }
 
+// WithDraining adds a channel that is closed when the server starts shutting down.
+func WithDraining(ctx context.Context, draining <-chan struct{}) context.Context {
+	return context.WithValue(ctx, DrainingKey, draining)
+}
+
+// Draining returns a channel closed when the server serving the request starts
+// shutting down. Long-lived responses, such as event streams, should watch it
+// and finish with a final message instead of holding up draining. The channel
+// is nil, and never ready, outside of a server.
+func Draining(ctx context.Context) <-chan struct{} {
+	draining, _ := ctx.Value(DrainingKey).(<-chan struct{})
+
+	return draining
+}
+
 // BuildLocation creates a URL for a newly created resource.
 func BuildLocation(r *http.Request, id int) string {
 	scheme := "http"
@@ -49,9 +103,9 @@ func BuildLocation(r *http.Request, id int) string {
 	)
 }
 
-// GenerateRequestID generates a request ID based on time and random bytes to avoid dublication.
+// GenerateRequestID generates a request ID based on time and 96 random bits to avoid dublication.
 func GenerateRequestID() string {
-	extra := make([]byte, 2)
+	extra := make([]byte, requestIDRandomBytes)
 
 	//nolint:errcheck,gosec
 	rand.Read(extra)
@@ -60,3 +114,125 @@ func GenerateRequestID() string {
 
 	return requestID
 }
+
+// Transport propagates the request ID from the request context to outbound
+// calls made with it. Trace context is injected by the tracing package.
+type Transport struct {
+	Base http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	id := RequestIDFromContext(req.Context())
+	if id == "" {
+		return base.RoundTrip(req)
+	}
+
+	// RoundTrippers must not modify the caller's request.
+	req = req.Clone(req.Context())
+	req.Header.Set(RequestIDHeader, id)
+
+	return base.RoundTrip(req)
+}
+
+// ClientIP returns the address of the client that sent r. X-Forwarded-For is
+// only honoured when the direct peer is a trusted proxy; the header is then
+// read right to left and the first address that is not a trusted proxy wins.
+// Peers on a Unix socket are local proxies and always trusted; without the
+// header their requests have no client address.
+func ClientIP(r *http.Request, trusted []netip.Prefix) netip.Addr {
+	ip := peerIP(r)
+	if !FromTrustedProxy(r, trusted) {
+		return ip
+	}
+
+	hops := strings.Split(strings.Join(r.Header.Values("X-Forwarded-For"), ","), ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop, err := netip.ParseAddr(strings.TrimSpace(hops[i]))
+		if err != nil {
+			break
+		}
+
+		ip = hop.Unmap()
+		if !isTrusted(ip, trusted) {
+			break
+		}
+	}
+
+	return ip
+}
+
+// FromTrustedProxy reports whether the direct peer of r is a trusted proxy,
+// whose headers identifying the client can be believed. Only processes allowed
+// by the socket permissions can connect to a Unix socket, so its peers are.
+func FromTrustedProxy(r *http.Request, trusted []netip.Prefix) bool {
+	if unixPeer(r) {
+		return true
+	}
+
+	ip := peerIP(r)
+
+	return ip.IsValid() && isTrusted(ip, trusted)
+}
+
+// unixPeer reports whether r was received on a Unix socket, whose peers have
+// no address: RemoteAddr is then "@" or empty.
+func unixPeer(r *http.Request) bool {
+	addr, ok := r.Context().Value(http.LocalAddrContextKey).(net.Addr)
+
+	return ok && addr.Network() == "unix"
+}
+
+// peerIP returns the address of the direct peer of r.
+func peerIP(r *http.Request) netip.Addr {
+	peer, err := netip.ParseAddrPort(r.RemoteAddr)
+	if err != nil {
+		addr, err := netip.ParseAddr(r.RemoteAddr)
+		if err != nil {
+			return netip.Addr{}
+		}
+
+		peer = netip.AddrPortFrom(addr, 0)
+	}
+
+	return peer.Addr().Unmap()
+}
+
+func isTrusted(ip netip.Addr, trusted []netip.Prefix) bool {
+	for _, p := range trusted {
+		if p.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ClientIdentity returns the identity from a verified client certificate:
+// its common name, or the first DNS, URI or email SAN. It is empty for
+// plain HTTP requests and TLS requests without a client certificate.
+func ClientIdentity(r *http.Request) string {
+	if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 || len(r.TLS.VerifiedChains[0]) == 0 {
+		return ""
+	}
+
+	cert := r.TLS.VerifiedChains[0][0]
+
+	switch {
+	case cert.Subject.CommonName != "":
+		return cert.Subject.CommonName
+	case len(cert.DNSNames) > 0:
+		return cert.DNSNames[0]
+	case len(cert.URIs) > 0:
+		return cert.URIs[0].String()
+	case len(cert.EmailAddresses) > 0:
+		return cert.EmailAddresses[0]
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,122 @@
+package httputils
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"testing"
+)
+
+func TestValidRequestID(t *testing.T) {
+	tests := map[string]bool{
+		"":                                     false,
+		"abc-123_DEF.4:5":                      true,
+		"550e8400-e29b-41d4-a716-446655440000": true,
+		"with space":                           false,
+		"new\nline":                            false,
+		"юникод":                               false,
+		strings.Repeat("a", 128):               true,
+		strings.Repeat("a", 129):               false,
+	}
+
+	for id, want := range tests {
+		if got := ValidRequestID(id); got != want {
+			t.Errorf("ValidRequestID(%q) = %v, want %v", id, got, want)
+		}
+	}
+}
+
+func TestGenerateRequestID(t *testing.T) {
+	a, b := GenerateRequestID(), GenerateRequestID()
+
+	if a == b {
+		t.Error("Expected unique request IDs")
+	}
+	if !ValidRequestID(a) {
+		t.Errorf("Expected generated ID %q to be valid", a)
+	}
+}
+
+func TestTransport(t *testing.T) {
+	var got string
+
+	srv := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		got = r.Header.Get(RequestIDHeader)
+	}))
+	defer srv.Close()
+
+	req, err := http.NewRequestWithContext(WithRequestID(context.Background(), "req-42"), http.MethodGet, srv.URL, nil)
+	if err != nil {
+		t.Fatalf("NewRequest failed: %v", err)
+	}
+
+	client := &http.Client{Transport: &Transport{}}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("Do failed: %v", err)
+	}
+	resp.Body.Close()
+
+	if got != "req-42" {
+		t.Errorf("Expected request ID to be propagated, got %q", got)
+	}
+	if req.Header.Get(RequestIDHeader) != "" {
+		t.Error("Expected caller's request to be left unmodified")
+	}
+}
+
+func TestClientIP(t *testing.T) {
+	trusted := []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8"), netip.MustParsePrefix("::1/128")}
+
+	tests := []struct {
+		name   string
+		remote string
+		xff    string
+		unix   bool
+		want   string
+	}{
+		{"direct client", "203.0.113.7:5000", "", false, "203.0.113.7"},
+		{"untrusted peer spoofing header", "203.0.113.7:5000", "198.51.100.1", false, "203.0.113.7"},
+		{"trusted proxy", "10.0.0.2:5000", "198.51.100.1", false, "198.51.100.1"},
+		{"proxy chain", "10.0.0.2:5000", "192.0.2.9, 198.51.100.1, 10.0.0.3", false, "198.51.100.1"},
+		{"only proxies", "10.0.0.2:5000", "10.0.0.3", false, "10.0.0.3"},
+		{"malformed hop", "10.0.0.2:5000", "garbage", false, "10.0.0.2"},
+		{"ipv6 proxy", "[::1]:5000", "2001:db8::1", false, "2001:db8::1"},
+		{"unix socket proxy", "@", "192.0.2.9, 198.51.100.1", true, "198.51.100.1"},
+		{"unix socket without header", "@", "", true, "invalid IP"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = tt.remote
+			if tt.unix {
+				addr := &net.UnixAddr{Name: "/run/api.sock", Net: "unix"}
+				req = req.WithContext(context.WithValue(req.Context(), http.LocalAddrContextKey, addr))
+			}
+			if tt.xff != "" {
+				req.Header.Set("X-Forwarded-For", tt.xff)
+			}
+
+			if got := ClientIP(req, trusted).String(); got != tt.want {
+				t.Errorf("ClientIP() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildLocation(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "http://api.example.com/todos", nil)
+	if got := BuildLocation(req, 7); got != "http://api.example.com/todos/7" {
+		t.Errorf("Unexpected location %s", got)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "https://api.example.com:8443/todos", nil)
+	if got := BuildLocation(req, 7); got != "https://api.example.com:8443/todos/7" {
+		t.Errorf("Unexpected location %s", got)
+	}
+}
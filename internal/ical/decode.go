@@ -0,0 +1,246 @@
+package ical
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"strings"
+
+	"ecom-internship/internal/model"
+	"ecom-internship/internal/transfer"
+)
+
+const maxLineSize = 1 << 20
+
+var _ transfer.Decoder = (*Decoder)(nil)
+
+// Decoder reads VTODO components from an iCalendar stream.
+// It implements transfer.Decoder, so .ics files can be imported like other formats.
+type Decoder struct {
+	scanner *bufio.Scanner
+	domain  string
+	next    *string
+	started bool
+	row     int
+}
+
+// NewDecoder creates a Decoder reading from r. UIDs produced for domain are
+// decoded into IDs; all other UIDs are kept in ToDo.UID.
+func NewDecoder(r io.Reader, domain string) *Decoder {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+
+	return &Decoder{scanner: scanner, domain: domain}
+}
+
+// Row returns the number of the last decoded VTODO.
+func (d *Decoder) Row() int {
+	return d.row
+}
+
+// Decode returns the next VTODO as a ToDo, skipping all other components.
+func (d *Decoder) Decode() (model.ToDo, error) {
+	for {
+		name, _, value, err := d.readProperty()
+		if err != nil {
+			return model.ToDo{}, err
+		}
+
+		if !d.started {
+			if name != "BEGIN" || !strings.EqualFold(value, "VCALENDAR") {
+				return model.ToDo{}, ErrNotCalendar
+			}
+
+			d.started = true
+
+			continue
+		}
+
+		switch {
+		case name == "END" && strings.EqualFold(value, "VCALENDAR"):
+			return model.ToDo{}, io.EOF
+		case name == "BEGIN" && strings.EqualFold(value, "VTODO"):
+			d.row++
+
+			return d.decodeToDo()
+		}
+	}
+}
+
+func (d *Decoder) decodeToDo() (model.ToDo, error) {
+	var (
+		todo   model.ToDo
+		rowErr error
+		depth  int
+	)
+
+	for {
+		name, params, value, err := d.readProperty()
+		if errors.Is(err, io.EOF) {
+			return model.ToDo{}, ErrUnterminated
+		}
+
+		if err != nil {
+			return model.ToDo{}, err
+		}
+
+		switch {
+		case name == "BEGIN":
+			depth++
+
+			continue
+		case name == "END" && depth > 0:
+			depth--
+
+			continue
+		case name == "END":
+			if rowErr != nil {
+				return model.ToDo{}, &transfer.RowError{Row: d.row, Err: rowErr}
+			}
+
+			return todo, nil
+		case depth > 0:
+			// Properties of nested components such as VALARM are ignored.
+			continue
+		}
+
+		if err := d.applyProperty(&todo, name, params, value); err != nil && rowErr == nil {
+			rowErr = err
+		}
+	}
+}
+
+func (d *Decoder) applyProperty(todo *model.ToDo, name string, params map[string]string, value string) error {
+	switch name {
+	case "UID":
+		if todo.ID = parseUID(value, d.domain); todo.ID == 0 {
+			todo.UID = value
+		}
+	case "SUMMARY":
+		todo.Caption = unescapeText(value)
+	case "DESCRIPTION":
+		todo.Description = unescapeText(value)
+	case "STATUS":
+		todo.IsCompleted = strings.EqualFold(value, "COMPLETED")
+	case "COMPLETED":
+		todo.IsCompleted = true
+	case "DUE":
+		due, err := parseTime(value, params)
+		if err != nil {
+			return err
+		}
+
+		todo.DueAt = &due
+	case "CREATED":
+		created, err := parseTime(value, params)
+		if err != nil {
+			return err
+		}
+
+		todo.CreatedAt = created
+	case "LAST-MODIFIED", "DTSTAMP":
+		modified, err := parseTime(value, params)
+		if err != nil {
+			return err
+		}
+
+		// LAST-MODIFIED takes precedence over DTSTAMP regardless of their order.
+		if name == "LAST-MODIFIED" || todo.UpdatedAt.IsZero() {
+			todo.UpdatedAt = modified
+		}
+	}
+
+	return nil
+}
+
+// readProperty returns the next unfolded content line split into its parts.
+func (d *Decoder) readProperty() (string, map[string]string, string, error) {
+	for {
+		line, err := d.readLine()
+		if err != nil {
+			return "", nil, "", err
+		}
+
+		if line == "" {
+			continue
+		}
+
+		name, params, value := parseContentLine(line)
+
+		return name, params, value, nil
+	}
+}
+
+// readLine returns the next logical line, joining folded continuation lines.
+func (d *Decoder) readLine() (string, error) {
+	var line string
+
+	if d.next != nil {
+		line = *d.next
+		d.next = nil
+	} else {
+		if !d.scanner.Scan() {
+			return "", d.scanErr()
+		}
+
+		line = strings.TrimRight(d.scanner.Text(), "\r")
+	}
+
+	for d.scanner.Scan() {
+		next := strings.TrimRight(d.scanner.Text(), "\r")
+		if next == "" || (next[0] != ' ' && next[0] != '\t') {
+			d.next = &next
+
+			return line, nil
+		}
+
+		line += next[1:]
+	}
+
+	if err := d.scanner.Err(); err != nil {
+		return "", err
+	}
+
+	return line, nil
+}
+
+func (d *Decoder) scanErr() error {
+	if err := d.scanner.Err(); err != nil {
+		return err
+	}
+
+	return io.EOF
+}
+
+// parseContentLine splits "NAME;PARAM=VALUE:value" honoring quoted parameter values.
+func parseContentLine(line string) (string, map[string]string, string) {
+	inQuotes := false
+	colon := -1
+
+	for i, r := range line {
+		if r == '"' {
+			inQuotes = !inQuotes
+		}
+
+		if r == ':' && !inQuotes {
+			colon = i
+
+			break
+		}
+	}
+
+	if colon < 0 {
+		return strings.ToUpper(line), nil, ""
+	}
+
+	head, value := line[:colon], line[colon+1:]
+	parts := strings.Split(head, ";")
+	params := make(map[string]string, len(parts)-1)
+
+	for _, p := range parts[1:] {
+		key, val, _ := strings.Cut(p, "=")
+		params[strings.ToUpper(key)] = strings.Trim(val, `"`)
+	}
+
+	return strings.ToUpper(parts[0]), params, value
+}
@@ -0,0 +1,115 @@
+package ical
+
+import (
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"ecom-internship/internal/model"
+)
+
+// Encoder writes ToDo items as VTODO components of a single VCALENDAR.
+type Encoder struct {
+	w       io.Writer
+	name    string
+	domain  string
+	started bool
+	err     error
+}
+
+// NewEncoder creates an Encoder. The name is used as the calendar display name
+// and the domain in the UIDs of items created by this instance.
+func NewEncoder(w io.Writer, name, domain string) *Encoder {
+	return &Encoder{w: w, name: name, domain: domain}
+}
+
+// Encode writes a single ToDo as a VTODO component.
+func (e *Encoder) Encode(todo model.ToDo) error {
+	e.begin()
+
+	e.line("BEGIN:VTODO")
+	// Imported items keep their UID, so the source calendar recognises them.
+	uid := todo.UID
+	if uid == "" {
+		uid = UID(todo.ID, e.domain)
+	}
+
+	e.line("UID:" + uid)
+	e.line("DTSTAMP:" + formatTime(todo.UpdatedAt))
+	e.line("CREATED:" + formatTime(todo.CreatedAt))
+	e.line("LAST-MODIFIED:" + formatTime(todo.UpdatedAt))
+	e.line("SUMMARY:" + escapeText(todo.Caption))
+
+	if todo.Description != "" {
+		e.line("DESCRIPTION:" + escapeText(todo.Description))
+	}
+
+	if todo.DueAt != nil {
+		e.line("DUE:" + formatTime(*todo.DueAt))
+	}
+
+	if todo.IsCompleted {
+		e.line("STATUS:COMPLETED")
+	} else {
+		e.line("STATUS:NEEDS-ACTION")
+	}
+
+	e.line("END:VTODO")
+
+	return e.err
+}
+
+// Close terminates the calendar. It does not close the underlying writer.
+func (e *Encoder) Close() error {
+	e.begin()
+	e.line("END:VCALENDAR")
+
+	return e.err
+}
+
+func (e *Encoder) begin() {
+	if e.started {
+		return
+	}
+
+	e.started = true
+
+	e.line("BEGIN:VCALENDAR")
+	e.line("VERSION:2.0")
+	e.line("PRODID:" + prodID)
+	e.line("CALSCALE:GREGORIAN")
+
+	if e.name != "" {
+		e.line("X-WR-CALNAME:" + escapeText(e.name))
+	}
+
+	e.line("X-PUBLISHED-TTL:PT1H")
+}
+
+// line writes a content line folded to 75 octets as required by RFC 5545.
+func (e *Encoder) line(s string) {
+	if e.err != nil {
+		return
+	}
+
+	var b strings.Builder
+
+	limit := maxLineLen
+	for len(s) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(s[cut]) {
+			cut--
+		}
+
+		b.WriteString(s[:cut])
+		b.WriteString("\r\n ")
+		s = s[cut:]
+		// Continuation lines start with a space which counts towards the limit.
+		limit = maxLineLen - 1
+	}
+
+	b.WriteString(s)
+	b.WriteString("\r\n")
+
+	_, e.err = io.WriteString(e.w, b.String())
+}
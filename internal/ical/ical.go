@@ -0,0 +1,113 @@
+// Package ical provides RFC 5545 VTODO encoding and decoding of ToDo items.
+package ical
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	// The scratch image has no zoneinfo, but TZID parameters must still resolve.
+	_ "time/tzdata"
+)
+
+// ContentType is the MIME type of iCalendar data.
+const ContentType = "text/calendar; charset=utf-8"
+
+const (
+	prodID     = "-//ecom-internship//ToDo API//EN"
+	uidPrefix  = "todo-"
+	dateTime   = "20060102T150405Z"
+	localTime  = "20060102T150405"
+	dateOnly   = "20060102"
+	maxLineLen = 75
+)
+
+// Decoding errors.
+var (
+	ErrNotCalendar     = errors.New("input is not an iCalendar object")
+	ErrUnterminated    = errors.New("unterminated component")
+	ErrInvalidDateTime = errors.New("invalid date-time value")
+)
+
+// DefaultDomain is the UID domain of instances that do not set their own.
+const DefaultDomain = "ecom-internship"
+
+// UID returns the iCalendar UID of the ToDo with the given ID created by the
+// instance with the given domain.
+func UID(id int, domain string) string {
+	return uidPrefix + strconv.Itoa(id) + "@" + domain
+}
+
+// parseUID extracts a ToDo ID from a UID produced by UID for domain. UIDs
+// created by other applications or instances yield 0.
+func parseUID(uid, domain string) int {
+	rest, ok := strings.CutPrefix(uid, uidPrefix)
+	if !ok {
+		return 0
+	}
+
+	rest, ok = strings.CutSuffix(rest, "@"+domain)
+	if !ok {
+		return 0
+	}
+
+	id, err := strconv.Atoi(rest)
+	if err != nil || id < 0 {
+		return 0
+	}
+
+	return id
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(dateTime)
+}
+
+// parseTime parses DATE and DATE-TIME values in UTC, floating or TZID form.
+func parseTime(value string, params map[string]string) (time.Time, error) {
+	loc := time.UTC
+
+	if tzid, ok := params["TZID"]; ok {
+		if l, err := time.LoadLocation(tzid); err == nil {
+			loc = l
+		}
+	}
+
+	for _, layout := range []string{dateTime, localTime, dateOnly} {
+		if t, err := time.ParseInLocation(layout, value, loc); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("%w: %q", ErrInvalidDateTime, value)
+}
+
+var textEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+
+func escapeText(s string) string {
+	return textEscaper.Replace(s)
+}
+
+func unescapeText(s string) string {
+	var b strings.Builder
+
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 == len(s) {
+			b.WriteByte(s[i])
+
+			continue
+		}
+
+		i++
+
+		switch s[i] {
+		case 'n', 'N':
+			b.WriteByte('\n')
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+
+	return b.String()
+}
@@ -0,0 +1,139 @@
+package ical
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"ecom-internship/internal/model"
+	"ecom-internship/internal/transfer"
+)
+
+func TestEncodeDecode_RoundTrip(t *testing.T) {
+	created := time.Date(2025, 12, 29, 10, 30, 0, 0, time.UTC)
+	due := created.Add(48 * time.Hour)
+	todo := model.ToDo{
+		ID:          42,
+		Caption:     "Buy groceries; milk, bread",
+		Description: strings.Repeat("Long description line\n", 10),
+		IsCompleted: true,
+		DueAt:       &due,
+		CreatedAt:   created,
+		UpdatedAt:   created.Add(time.Hour),
+	}
+
+	var buf bytes.Buffer
+
+	enc := NewEncoder(&buf, "ToDo", DefaultDomain)
+	if err := enc.Encode(todo); err != nil {
+		t.Fatalf("Encode failed: %v", err)
+	}
+	if err := enc.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	for _, line := range strings.Split(buf.String(), "\r\n") {
+		if len(line) > maxLineLen {
+			t.Errorf("Line exceeds %d octets: %q", maxLineLen, line)
+		}
+	}
+
+	dec := NewDecoder(&buf, DefaultDomain)
+
+	got, err := dec.Decode()
+	if err != nil {
+		t.Fatalf("Decode failed: %v", err)
+	}
+
+	if got.ID != todo.ID || got.Caption != todo.Caption || got.Description != todo.Description {
+		t.Errorf("Expected %+v, got %+v", todo, got)
+	}
+	if !got.IsCompleted {
+		t.Error("Expected IsCompleted to be true")
+	}
+	if got.DueAt == nil || !got.DueAt.Equal(due) {
+		t.Errorf("Expected due %v, got %v", due, got.DueAt)
+	}
+	if !got.CreatedAt.Equal(todo.CreatedAt) || !got.UpdatedAt.Equal(todo.UpdatedAt) {
+		t.Errorf("Expected timestamps %v/%v, got %v/%v", todo.CreatedAt, todo.UpdatedAt, got.CreatedAt, got.UpdatedAt)
+	}
+
+	if _, err := dec.Decode(); !errors.Is(err, io.EOF) {
+		t.Errorf("Expected io.EOF, got %v", err)
+	}
+}
+
+func TestDecode_ForeignCalendar(t *testing.T) {
+	input := "BEGIN:VCALENDAR\r\n" +
+		"VERSION:2.0\r\n" +
+		"BEGIN:VEVENT\r\nUID:event-1\r\nSUMMARY:Meeting\r\nEND:VEVENT\r\n" +
+		"BEGIN:VTODO\r\n" +
+		"UID:4f1c@example.com\r\n" +
+		"SUMMARY:Call the\r\n  bank\r\n" +
+		"DUE;TZID=Europe/Moscow:20260102T120000\r\n" +
+		"BEGIN:VALARM\r\nSUMMARY:Alarm\r\nEND:VALARM\r\n" +
+		"END:VTODO\r\n" +
+		"BEGIN:VTODO\r\nSUMMARY:Bad due\r\nDUE:tomorrow\r\nEND:VTODO\r\n" +
+		"END:VCALENDAR\r\n"
+
+	dec := NewDecoder(strings.NewReader(input), DefaultDomain)
+
+	todo, err := dec.Decode()
+	if err != nil {
+		t.Fatalf("Decode failed: %v", err)
+	}
+	if todo.ID != 0 || todo.UID != "4f1c@example.com" {
+		t.Errorf("Expected foreign UID to be kept with ID 0, got %d %q", todo.ID, todo.UID)
+	}
+	if todo.Caption != "Call the bank" {
+		t.Errorf("Expected unfolded caption, got %q", todo.Caption)
+	}
+	if todo.DueAt == nil || todo.DueAt.UTC().Hour() != 9 {
+		t.Errorf("Expected due 09:00 UTC, got %v", todo.DueAt)
+	}
+
+	_, err = dec.Decode()
+
+	var rowErr *transfer.RowError
+	if !errors.As(err, &rowErr) || rowErr.Row != 2 {
+		t.Errorf("Expected RowError at row 2, got %v", err)
+	}
+}
+
+func TestEncodeDecode_UIDs(t *testing.T) {
+	var buf bytes.Buffer
+
+	enc := NewEncoder(&buf, "ToDo", "other")
+	enc.Encode(model.ToDo{ID: 1, Caption: "Local"})                             //nolint:errcheck,gosec
+	enc.Encode(model.ToDo{ID: 2, Caption: "Imported", UID: "4f1c@example.com"}) //nolint:errcheck,gosec
+	enc.Close()                                                                 //nolint:errcheck,gosec
+
+	if !strings.Contains(buf.String(), "UID:todo-1@other\r\n") || !strings.Contains(buf.String(), "UID:4f1c@example.com\r\n") {
+		t.Fatalf("Expected local and imported UIDs, got %q", buf.String())
+	}
+
+	// Items of another instance must not overwrite the local items with the same IDs.
+	dec := NewDecoder(strings.NewReader(buf.String()), DefaultDomain)
+
+	for _, want := range []string{"todo-1@other", "4f1c@example.com"} {
+		todo, err := dec.Decode()
+		if err != nil {
+			t.Fatalf("Decode failed: %v", err)
+		}
+
+		if todo.ID != 0 || todo.UID != want {
+			t.Errorf("Expected UID %q with ID 0, got %d %q", want, todo.ID, todo.UID)
+		}
+	}
+}
+
+func TestDecode_NotCalendar(t *testing.T) {
+	dec := NewDecoder(strings.NewReader("caption,description\n"), DefaultDomain)
+
+	if _, err := dec.Decode(); !errors.Is(err, ErrNotCalendar) {
+		t.Errorf("Expected ErrNotCalendar, got %v", err)
+	}
+}
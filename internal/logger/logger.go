@@ -1,6 +1,8 @@
 // Package logger defines the logging interface for the application.
 package logger
 
+import "context"
+
 // Logger defines the logging interface.
 type Logger interface {
 	Debug(msg string, args ...any)
@@ -9,3 +11,37 @@ type Logger interface {
 	Error(msg string, args ...any)
 	With(args ...any) Logger
 }
+
+// ComponentKey is the attribute naming the component a logger belongs to,
+// set with With(ComponentKey, name). Components can have their own levels.
+const ComponentKey = "component"
+
+// Levels are the minimal levels of log records: Default applies to
+// components without a level of their own.
+type Levels struct {
+	Default    string            `json:"default"`
+	Components map[string]string `json:"components"`
+}
+
+// LevelController is implemented by loggers whose levels can be changed at runtime.
+// Changes apply to the logger and all loggers derived from it with With.
+type LevelController interface {
+	Levels() Levels
+	SetLevels(levels Levels)
+}
+
+type contextKey struct{}
+
+// WithContext returns a copy of ctx carrying a request-scoped logger.
+func WithContext(ctx context.Context, l Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, l)
+}
+
+// FromContext returns the request-scoped logger from ctx, or fallback if there is none.
+func FromContext(ctx context.Context, fallback Logger) Logger {
+	if l, ok := ctx.Value(contextKey{}).(Logger); ok {
+		return l
+	}
+
+	return fallback
+}
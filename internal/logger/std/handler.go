@@ -0,0 +1,189 @@
+package std
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// fanout sends every record to all sinks.
+type fanout []slog.Handler
+
+func (f fanout) Enabled(ctx context.Context, level slog.Level) bool {
+	for _, h := range f {
+		if h.Enabled(ctx, level) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (f fanout) Handle(ctx context.Context, r slog.Record) error {
+	var errs []error
+
+	for _, h := range f {
+		if h.Enabled(ctx, r.Level) {
+			errs = append(errs, h.Handle(ctx, r.Clone()))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (f fanout) WithAttrs(attrs []slog.Attr) slog.Handler {
+	res := make(fanout, len(f))
+	for i, h := range f {
+		res[i] = h.WithAttrs(attrs)
+	}
+
+	return res
+}
+
+func (f fanout) WithGroup(name string) slog.Handler {
+	res := make(fanout, len(f))
+	for i, h := range f {
+		res[i] = h.WithGroup(name)
+	}
+
+	return res
+}
+
+// facilityUser is the syslog facility of log messages.
+const facilityUser = 1
+
+// syslogHandler sends every record as a message to the local syslog socket
+// with a priority matching its level. The record is formatted by the inner
+// JSON or text handler.
+type syslogHandler struct {
+	inner slog.Handler
+	conn  *syslogConn
+}
+
+// syslogConn is the socket shared by a syslog handler and handlers derived from it.
+type syslogConn struct {
+	address string
+	tag     string
+
+	mu   sync.Mutex
+	conn net.Conn
+	// buf receives the output of the inner handlers.
+	buf bytes.Buffer
+}
+
+func newSyslogHandler(address string, newHandler func(io.Writer) slog.Handler) (*syslogHandler, error) {
+	c := &syslogConn{address: address, tag: filepath.Base(os.Args[0])}
+	if err := c.dial(); err != nil {
+		return nil, err
+	}
+
+	return &syslogHandler{inner: newHandler(&c.buf), conn: c}, nil
+}
+
+func (h *syslogHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.inner.Enabled(ctx, level)
+}
+
+func (h *syslogHandler) Handle(ctx context.Context, r slog.Record) error {
+	c := h.conn
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.buf.Reset()
+
+	if err := h.inner.Handle(ctx, r); err != nil {
+		return err
+	}
+
+	t := r.Time
+	if t.IsZero() {
+		t = time.Now()
+	}
+
+	msg := fmt.Sprintf("<%d>%s %s[%d]: %s", facilityUser*8+severity(r.Level), t.Format(time.Stamp),
+		c.tag, os.Getpid(), strings.TrimSuffix(c.buf.String(), "\n"))
+
+	return c.write(msg)
+}
+
+func (h *syslogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &syslogHandler{inner: h.inner.WithAttrs(attrs), conn: h.conn}
+}
+
+func (h *syslogHandler) WithGroup(name string) slog.Handler {
+	return &syslogHandler{inner: h.inner.WithGroup(name), conn: h.conn}
+}
+
+// dial connects to a datagram socket, as syslog daemons usually provide, or a stream one.
+func (c *syslogConn) dial() error {
+	var err error
+
+	for _, network := range []string{"unixgram", "unix"} {
+		var conn net.Conn
+		if conn, err = net.Dial(network, c.address); err == nil {
+			c.conn = conn
+
+			return nil
+		}
+	}
+
+	return err
+}
+
+// write sends msg, reconnecting once in case the syslog daemon was restarted.
+func (c *syslogConn) write(msg string) error {
+	if c.conn != nil {
+		if _, err := io.WriteString(c.conn, msg); err == nil {
+			return nil
+		}
+
+		c.conn.Close() //nolint:errcheck,gosec
+		c.conn = nil
+	}
+
+	if err := c.dial(); err != nil {
+		return err
+	}
+
+	_, err := io.WriteString(c.conn, msg)
+
+	return err
+}
+
+func (c *syslogConn) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.conn == nil {
+		return nil
+	}
+
+	err := c.conn.Close()
+	c.conn = nil
+
+	return err
+}
+
+// severity maps a level to a syslog severity.
+func severity(level slog.Level) int {
+	switch {
+	case level >= slog.LevelError:
+		return 3
+	case level >= slog.LevelWarn:
+		return 4
+	case level >= slog.LevelInfo:
+		return 6
+	default:
+		return 7
+	}
+}
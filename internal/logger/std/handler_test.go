@@ -0,0 +1,70 @@
+package std
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"net"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"ecom-internship/internal/logger"
+)
+
+func TestSyslogHandler(t *testing.T) {
+	address := filepath.Join(t.TempDir(), "log.sock")
+
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: address, Net: "unixgram"})
+	if err != nil {
+		t.Skipf("unixgram sockets are unavailable: %v", err)
+	}
+	defer conn.Close()
+
+	h, err := newSyslogHandler(address, func(w io.Writer) slog.Handler {
+		return slog.NewJSONHandler(w, handlerOptions)
+	})
+	if err != nil {
+		t.Fatalf("newSyslogHandler failed: %v", err)
+	}
+	defer h.conn.Close()
+
+	l := newLogger(h, logger.Levels{Default: "info"}, nil)
+	l.Info("started")
+	l.Error("failed")
+
+	for _, want := range []string{"<14>", "<11>"} {
+		buf := make([]byte, 1024)
+
+		if err := conn.SetReadDeadline(time.Now().Add(time.Second)); err != nil {
+			t.Fatal(err)
+		}
+
+		n, err := conn.Read(buf)
+		if err != nil {
+			t.Fatalf("Failed to read syslog message: %v", err)
+		}
+
+		if msg := string(buf[:n]); !strings.HasPrefix(msg, want) || !strings.Contains(msg, `"msg":`) {
+			t.Errorf("Expected a message with priority %s, got %q", want, msg)
+		}
+	}
+}
+
+func TestFanout(t *testing.T) {
+	var jsonBuf, textBuf bytes.Buffer
+
+	h := fanout{slog.NewJSONHandler(&jsonBuf, handlerOptions), slog.NewTextHandler(&textBuf, handlerOptions)}
+
+	l := newLogger(h, logger.Levels{Default: "info"}, nil)
+	l.With(logger.ComponentKey, "server").Info("hello")
+
+	if !strings.Contains(jsonBuf.String(), `"component":"server"`) {
+		t.Errorf("Expected a JSON record, got %s", jsonBuf.String())
+	}
+
+	if !strings.Contains(textBuf.String(), "component=server") {
+		t.Errorf("Expected a text record, got %s", textBuf.String())
+	}
+}
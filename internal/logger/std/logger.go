@@ -2,65 +2,238 @@
 package std
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
 	"log/slog"
 	"os"
+	"strings"
+	"sync/atomic"
 
+	"ecom-internship/internal/config"
 	"ecom-internship/internal/logger"
 )
 
+// handlerOptions leave filtering to StdLogger, which knows the component of each record.
+var handlerOptions = &slog.HandlerOptions{Level: slog.LevelDebug}
+
 // StdLogger implements the Logger interface using slog.
 //
 //nolint:revive
 type StdLogger struct {
-	logger *slog.Logger
+	logger    *slog.Logger
+	levels    *levels
+	component string
+	sampler   *sampler
+	closers   []io.Closer
 }
 
-// New creates a new StdLogger instance with the specified log level.
+// New creates a new StdLogger instance writing JSON to stdout with the specified log level.
 func New(lvl string) *StdLogger {
-	var level slog.Level
-	switch lvl {
-	case "debug":
-		level = slog.LevelDebug
-	case "warn":
-		level = slog.LevelWarn
-	case "error":
-		level = slog.LevelError
-	default:
-		level = slog.LevelInfo
+	return newLogger(slog.NewJSONHandler(os.Stdout, handlerOptions), logger.Levels{Default: lvl}, nil)
+}
+
+// NewFromConfig creates a StdLogger with the configured format, sinks, levels,
+// sampling and redaction. Close releases the file and syslog sinks.
+func NewFromConfig(cfg *config.LoggerConfig) (*StdLogger, error) {
+	opts := &slog.HandlerOptions{Level: handlerOptions.Level, ReplaceAttr: redactor(cfg.RedactKeys)}
+
+	newHandler := func(w io.Writer) slog.Handler {
+		if cfg.Format == "text" {
+			return slog.NewTextHandler(w, opts)
+		}
+
+		return slog.NewJSONHandler(w, opts)
+	}
+
+	outputs := cfg.Outputs
+	if len(outputs) == 0 {
+		outputs = []string{"stdout"}
 	}
 
-	handler := slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
-		Level: level,
-	})
+	var (
+		handlers fanout
+		closers  []io.Closer
+	)
+
+	for _, output := range outputs {
+		switch output {
+		case "stdout":
+			handlers = append(handlers, newHandler(os.Stdout))
+		case "stderr":
+			handlers = append(handlers, newHandler(os.Stderr))
+		case "file":
+			f, err := newRotatingFile(cfg.File, int64(cfg.FileMaxSize)<<20, cfg.FileMaxAge, cfg.FileMaxBackups)
+			if err != nil {
+				return nil, errors.Join(fmt.Errorf("log file: %w", err), closeAll(closers))
+			}
 
-	return &StdLogger{
-		logger: slog.New(handler),
+			handlers = append(handlers, newHandler(f))
+			closers = append(closers, f)
+		case "syslog":
+			h, err := newSyslogHandler(cfg.SyslogAddress, newHandler)
+			if err != nil {
+				return nil, errors.Join(fmt.Errorf("syslog: %w", err), closeAll(closers))
+			}
+
+			handlers = append(handlers, h)
+			closers = append(closers, h.conn)
+		default:
+			return nil, errors.Join(fmt.Errorf("%w: %q", config.ErrInvalidLogOutput, output), closeAll(closers))
+		}
+	}
+
+	var h slog.Handler = handlers
+	if len(handlers) == 1 {
+		h = handlers[0]
 	}
+
+	l := newLogger(h, logger.Levels{Default: cfg.Level, Components: cfg.ComponentLevels}, closers)
+	l.sampler = newSampler(cfg.SamplingInitial, cfg.SamplingThereafter)
+
+	return l, nil
+}
+
+func newLogger(h slog.Handler, lv logger.Levels, closers []io.Closer) *StdLogger {
+	l := &StdLogger{
+		logger:  slog.New(h),
+		levels:  &levels{},
+		closers: closers,
+	}
+
+	l.levels.set(lv)
+
+	return l
 }
 
 // Debug logs a message at DEBUG level.
 func (l *StdLogger) Debug(msg string, args ...any) {
-	l.logger.Debug(msg, args...)
+	l.log(slog.LevelDebug, msg, args...)
 }
 
 // Info logs a message at INFO level.
 func (l *StdLogger) Info(msg string, args ...any) {
-	l.logger.Info(msg, args...)
+	l.log(slog.LevelInfo, msg, args...)
 }
 
 // Warn logs a message at WARN level.
 func (l *StdLogger) Warn(msg string, args ...any) {
-	l.logger.Warn(msg, args...)
+	l.log(slog.LevelWarn, msg, args...)
 }
 
 // Error logs a message at ERROR level.
 func (l *StdLogger) Error(msg string, args ...any) {
-	l.logger.Error(msg, args...)
+	l.log(slog.LevelError, msg, args...)
+}
+
+func (l *StdLogger) log(level slog.Level, msg string, args ...any) {
+	if level < l.levels.level(l.component) || !l.sampler.allow(l.component, level, msg) {
+		return
+	}
+
+	l.logger.Log(context.Background(), level, msg, args...)
 }
 
-// With adds attributes to the logger.
+// With adds attributes to the logger. A logger.ComponentKey attribute
+// makes the logger use the level of that component.
 func (l *StdLogger) With(args ...any) logger.Logger {
-	return &StdLogger{
-		logger: l.logger.With(args...),
+	child := *l
+	child.logger = l.logger.With(args...)
+
+	for i := 0; i < len(args); i++ {
+		switch arg := args[i].(type) {
+		case slog.Attr:
+			if arg.Key == logger.ComponentKey {
+				child.component = arg.Value.String()
+			}
+		case string:
+			if i+1 < len(args) {
+				if arg == logger.ComponentKey {
+					child.component = fmt.Sprint(args[i+1])
+				}
+
+				i++
+			}
+		}
 	}
+
+	return &child
+}
+
+// Levels returns the current default and component levels.
+func (l *StdLogger) Levels() logger.Levels {
+	return l.levels.get()
+}
+
+// SetLevels replaces the levels of the logger and all loggers derived from it.
+func (l *StdLogger) SetLevels(lv logger.Levels) {
+	l.levels.set(lv)
+}
+
+// Close closes the file and syslog sinks shared by the logger and loggers derived from it.
+func (l *StdLogger) Close() error {
+	return closeAll(l.closers)
+}
+
+func closeAll(closers []io.Closer) error {
+	var errs []error
+
+	for _, c := range closers {
+		errs = append(errs, c.Close())
+	}
+
+	return errors.Join(errs...)
+}
+
+// levels holds the default level and per-component overrides, swapped atomically.
+type levels struct {
+	def        slog.LevelVar
+	components atomic.Pointer[map[string]slog.Level]
+}
+
+func (lv *levels) level(component string) slog.Level {
+	if component != "" {
+		if level, ok := (*lv.components.Load())[component]; ok {
+			return level
+		}
+	}
+
+	return lv.def.Level()
+}
+
+func (lv *levels) set(l logger.Levels) {
+	components := make(map[string]slog.Level, len(l.Components))
+	for component, level := range l.Components {
+		components[component] = parseLevel(level)
+	}
+
+	lv.def.Set(parseLevel(l.Default))
+	lv.components.Store(&components)
+}
+
+func (lv *levels) get() logger.Levels {
+	components := make(map[string]string)
+	for component, level := range *lv.components.Load() {
+		components[component] = levelName(level)
+	}
+
+	return logger.Levels{Default: levelName(lv.def.Level()), Components: components}
+}
+
+func parseLevel(lvl string) slog.Level {
+	switch lvl {
+	case "debug":
+		return slog.LevelDebug
+	case "warn":
+		return slog.LevelWarn
+	case "error":
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
+}
+
+func levelName(level slog.Level) string {
+	return strings.ToLower(level.String())
 }
@@ -0,0 +1,96 @@
+package std
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"ecom-internship/internal/config"
+	"ecom-internship/internal/logger"
+)
+
+func TestStdLogger_ComponentLevels(t *testing.T) {
+	var buf bytes.Buffer
+
+	l := newLogger(slog.NewJSONHandler(&buf, handlerOptions),
+		logger.Levels{Default: "info", Components: map[string]string{"database": "debug", "server": "error"}}, nil)
+
+	db := l.With(logger.ComponentKey, "database")
+	srv := l.With("request_id", "1", logger.ComponentKey, "server")
+
+	l.Debug("root debug")
+	db.Debug("database debug")
+	srv.Warn("server warn")
+	srv.Error("server error")
+
+	out := buf.String()
+	for _, want := range []string{"database debug", "server error"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected %q to be logged, got %s", want, out)
+		}
+	}
+
+	for _, unwanted := range []string{"root debug", "server warn"} {
+		if strings.Contains(out, unwanted) {
+			t.Errorf("Expected %q to be filtered, got %s", unwanted, out)
+		}
+	}
+
+	// Loggers derived before the change follow the new levels.
+	l.SetLevels(logger.Levels{Default: "debug"})
+	buf.Reset()
+
+	srv.Warn("server warn")
+
+	if !strings.Contains(buf.String(), "server warn") {
+		t.Errorf("Expected the removed override to fall back to the default level, got %s", buf.String())
+	}
+
+	if got := l.Levels(); got.Default != "debug" || len(got.Components) != 0 {
+		t.Errorf("Unexpected levels: %+v", got)
+	}
+}
+
+func TestNewFromConfig_TextFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "app.log")
+
+	l, err := NewFromConfig(&config.LoggerConfig{
+		Level:       "info",
+		Format:      "text",
+		Outputs:     []string{"file"},
+		File:        path,
+		FileMaxSize: 1,
+	})
+	if err != nil {
+		t.Fatalf("NewFromConfig failed: %v", err)
+	}
+
+	l.Info("hello", "key", "value")
+
+	if err := l.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read log file: %v", err)
+	}
+
+	if !strings.Contains(string(data), "msg=hello key=value") {
+		t.Errorf("Expected a text record, got %s", data)
+	}
+
+	if json.Valid(bytes.TrimSpace(data)) {
+		t.Errorf("Expected text rather than JSON, got %s", data)
+	}
+}
+
+func TestNewFromConfig_InvalidOutput(t *testing.T) {
+	if _, err := NewFromConfig(&config.LoggerConfig{Level: "info", Outputs: []string{"kafka"}}); err == nil {
+		t.Error("Expected an error for an unknown output")
+	}
+}
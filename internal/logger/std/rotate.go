@@ -0,0 +1,158 @@
+package std
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"ecom-internship/internal/config"
+)
+
+// backupTimeFormat is the timestamp in names of rotated files; it sorts chronologically.
+const backupTimeFormat = "2006-01-02T15-04-05.000000000"
+
+// rotatingFile is a log file that is renamed with a timestamp, e.g.
+// app-2026-01-02T15-04-05.000000000.log, once a write would grow it beyond
+// maxSize bytes. Rotated files older than maxAge or beyond the newest
+// maxBackups are removed; zero disables any of the limits.
+type rotatingFile struct {
+	path       string
+	maxSize    int64
+	maxAge     time.Duration
+	maxBackups int
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+}
+
+// NewFileWriter opens path for appending and rotates it with the limits of
+// the log file in cfg. It lets other logs, such as the access log, share
+// the rotation policy.
+func NewFileWriter(path string, cfg *config.LoggerConfig) (io.WriteCloser, error) {
+	return newRotatingFile(path, int64(cfg.FileMaxSize)<<20, cfg.FileMaxAge, cfg.FileMaxBackups)
+}
+
+func newRotatingFile(path string, maxSize int64, maxAge time.Duration, maxBackups int) (*rotatingFile, error) {
+	f := &rotatingFile{path: path, maxSize: maxSize, maxAge: maxAge, maxBackups: maxBackups}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+
+	return f, nil
+}
+
+func (f *rotatingFile) open() error {
+	if err := os.MkdirAll(filepath.Dir(f.path), 0o750); err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(f.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o640)
+	if err != nil {
+		return err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close() //nolint:errcheck,gosec
+
+		return err
+	}
+
+	f.file, f.size = file, info.Size()
+
+	return nil
+}
+
+func (f *rotatingFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return 0, os.ErrClosed
+	}
+
+	if f.maxSize > 0 && f.size > 0 && f.size+int64(len(p)) > f.maxSize {
+		if err := f.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+
+	return n, err
+}
+
+func (f *rotatingFile) rotate() error {
+	if err := f.file.Close(); err != nil {
+		return err
+	}
+
+	f.file = nil
+
+	if err := os.Rename(f.path, f.backupName(time.Now())); err != nil {
+		return err
+	}
+
+	if err := f.open(); err != nil {
+		return err
+	}
+
+	f.removeOld()
+
+	return nil
+}
+
+func (f *rotatingFile) backupName(t time.Time) string {
+	ext := filepath.Ext(f.path)
+
+	return strings.TrimSuffix(f.path, ext) + "-" + t.Format(backupTimeFormat) + ext
+}
+
+// removeOld deletes rotated files over the limits. Failures are ignored,
+// as they must not prevent logging.
+func (f *rotatingFile) removeOld() {
+	ext := filepath.Ext(f.path)
+
+	backups, err := filepath.Glob(strings.TrimSuffix(f.path, ext) + "-*" + ext)
+	if err != nil {
+		return
+	}
+
+	// Newest first.
+	slices.Sort(backups)
+	slices.Reverse(backups)
+
+	for i, backup := range backups {
+		expired := false
+
+		if f.maxAge > 0 {
+			if info, err := os.Stat(backup); err == nil && time.Since(info.ModTime()) > f.maxAge {
+				expired = true
+			}
+		}
+
+		if expired || f.maxBackups > 0 && i >= f.maxBackups {
+			os.Remove(backup) //nolint:errcheck,gosec
+		}
+	}
+}
+
+func (f *rotatingFile) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return nil
+	}
+
+	err := f.file.Close()
+	f.file = nil
+
+	return err
+}
@@ -0,0 +1,52 @@
+package std
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRotatingFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+
+	f, err := newRotatingFile(path, 10, 0, 2)
+	if err != nil {
+		t.Fatalf("newRotatingFile failed: %v", err)
+	}
+	defer f.Close()
+
+	for _, line := range []string{"first\n", "second\n", "third\n", "fourth\n"} {
+		if _, err := f.Write([]byte(line)); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read log file: %v", err)
+	}
+
+	if string(data) != "fourth\n" {
+		t.Errorf("Expected the current file to hold the last line, got %q", data)
+	}
+
+	backups, err := filepath.Glob(filepath.Join(dir, "app-*.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(backups) != 2 {
+		t.Fatalf("Expected 2 backups to be kept, got %v", backups)
+	}
+
+	newest, err := os.ReadFile(backups[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(string(newest), "third") {
+		t.Errorf("Expected the newest backup to hold the third line, got %q", newest)
+	}
+}
@@ -0,0 +1,95 @@
+package std
+
+import (
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+)
+
+// redacted replaces the values of redacted attributes.
+const redacted = "[REDACTED]"
+
+// sampler limits repetitive records: each second the first initial records
+// with the same component, level and message are logged, then every
+// thereafter-th one. A zero thereafter drops the rest of the second.
+type sampler struct {
+	initial    uint64
+	thereafter uint64
+	now        func() time.Time
+
+	mu     sync.Mutex
+	second int64
+	counts map[sampleKey]uint64
+}
+
+type sampleKey struct {
+	component string
+	level     slog.Level
+	msg       string
+}
+
+func newSampler(initial, thereafter int) *sampler {
+	if initial <= 0 {
+		return nil
+	}
+
+	return &sampler{
+		initial:    uint64(initial),
+		thereafter: uint64(max(thereafter, 0)),
+		now:        time.Now,
+		counts:     make(map[sampleKey]uint64),
+	}
+}
+
+// allow reports whether the record should be logged. A nil sampler allows everything.
+func (s *sampler) allow(component string, level slog.Level, msg string) bool {
+	if s == nil {
+		return true
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Counters are reset every second, which also bounds the map by the
+	// number of distinct messages logged within a second.
+	if second := s.now().Unix(); second != s.second {
+		s.second = second
+		clear(s.counts)
+	}
+
+	key := sampleKey{component: component, level: level, msg: msg}
+	s.counts[key]++
+	n := s.counts[key]
+
+	if n <= s.initial {
+		return true
+	}
+
+	return s.thereafter > 0 && (n-s.initial)%s.thereafter == 0
+}
+
+// redactor returns a slog ReplaceAttr function masking the values of keys,
+// or nil if keys is empty. Attributes added with With are masked as well.
+func redactor(keys []string) func(groups []string, a slog.Attr) slog.Attr {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	set := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		set[normalizeKey(key)] = true
+	}
+
+	return func(_ []string, a slog.Attr) slog.Attr {
+		if set[normalizeKey(a.Key)] {
+			return slog.String(a.Key, redacted)
+		}
+
+		return a
+	}
+}
+
+func normalizeKey(key string) string {
+	return strings.ReplaceAll(strings.ToLower(key), "-", "_")
+}
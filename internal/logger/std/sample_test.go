@@ -0,0 +1,66 @@
+package std
+
+import (
+	"bytes"
+	"log/slog"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"ecom-internship/internal/logger"
+)
+
+func TestSampler(t *testing.T) {
+	now := time.Unix(1000, 0)
+
+	s := newSampler(2, 3)
+	s.now = func() time.Time { return now }
+
+	var allowed []int
+
+	for i := 1; i <= 9; i++ {
+		if s.allow("server", slog.LevelInfo, "request completed") {
+			allowed = append(allowed, i)
+		}
+	}
+
+	// The first two, then every third one.
+	if want := []int{1, 2, 5, 8}; !slices.Equal(allowed, want) {
+		t.Errorf("Expected records %v to be logged, got %v", want, allowed)
+	}
+
+	if !s.allow("server", slog.LevelError, "request completed") {
+		t.Error("Expected records of another level to be counted separately")
+	}
+
+	now = now.Add(time.Second)
+
+	if !s.allow("server", slog.LevelInfo, "request completed") {
+		t.Error("Expected counters to be reset in the next second")
+	}
+
+	if newSampler(0, 100) != nil {
+		t.Error("Expected sampling to be disabled without initial records")
+	}
+}
+
+func TestRedactor(t *testing.T) {
+	var buf bytes.Buffer
+
+	h := slog.NewJSONHandler(&buf, &slog.HandlerOptions{ReplaceAttr: redactor([]string{"Authorization", "x_api_key", "api_key"})})
+
+	l := newLogger(h, logger.Levels{Default: "info"}, nil)
+	l.With("authorization", "Bearer secret").Info("request", "X-API-Key", "key", "API_KEY", "other", "path", "/todos")
+
+	out := buf.String()
+	for _, secret := range []string{"Bearer secret", `"key"`, "other"} {
+		if strings.Contains(out, secret) {
+			t.Errorf("Expected %s to be redacted, got %s", secret, out)
+		}
+	}
+
+	if !strings.Contains(out, `"path":"/todos"`) || strings.Count(out, redacted) != 3 {
+		t.Errorf("Expected only configured keys to be redacted, got %s", out)
+	}
+}
@@ -0,0 +1,129 @@
+package metrics
+
+import (
+	"strings"
+	"sync"
+	"testing"
+)
+
+func scrape(t *testing.T, r *Registry) string {
+	t.Helper()
+
+	var b strings.Builder
+	if _, err := r.WriteTo(&b); err != nil {
+		t.Fatalf("WriteTo failed: %v", err)
+	}
+
+	return b.String()
+}
+
+func TestCounterVec(t *testing.T) {
+	r := NewRegistry()
+	c := r.NewCounterVec("requests_total", "Number of requests.", "code")
+
+	c.With("200").Inc()
+	c.With("200").Add(2)
+	c.With("500").Inc()
+
+	want := "# HELP requests_total Number of requests.\n" +
+		"# TYPE requests_total counter\n" +
+		`requests_total{code="200"} 3` + "\n" +
+		`requests_total{code="500"} 1` + "\n"
+
+	if got := scrape(t, r); got != want {
+		t.Errorf("Unexpected output:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestGaugeVec(t *testing.T) {
+	r := NewRegistry()
+	g := r.NewGaugeVec("in_flight", "In flight.", "route")
+
+	g.With("/a").Inc()
+	g.With("/a").Inc()
+	g.With("/a").Dec()
+	g.With(`we"ird`).Set(1.5)
+
+	got := scrape(t, r)
+	for _, want := range []string{`in_flight{route="/a"} 1`, `in_flight{route="we\"ird"} 1.5`} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Expected %q in:\n%s", want, got)
+		}
+	}
+}
+
+func TestHistogramVec(t *testing.T) {
+	r := NewRegistry()
+	h := r.NewHistogramVec("latency_seconds", "Latency.", []float64{1, 0.1}, "op")
+
+	h.With("get").Observe(0.05)
+	h.With("get").Observe(0.5)
+	h.With("get").Observe(5)
+
+	want := "# HELP latency_seconds Latency.\n" +
+		"# TYPE latency_seconds histogram\n" +
+		`latency_seconds_bucket{op="get",le="0.1"} 1` + "\n" +
+		`latency_seconds_bucket{op="get",le="1"} 2` + "\n" +
+		`latency_seconds_bucket{op="get",le="+Inf"} 3` + "\n" +
+		`latency_seconds_sum{op="get"} 5.55` + "\n" +
+		`latency_seconds_count{op="get"} 3` + "\n"
+
+	if got := scrape(t, r); got != want {
+		t.Errorf("Unexpected output:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestRegistry_SortedAndDuplicate(t *testing.T) {
+	r := NewRegistry()
+	r.NewGaugeFunc("b_metric", "B.", func() float64 { return 2 })
+	r.NewCounterFunc("a_metric", "A.", func() float64 { return 1 })
+
+	got := scrape(t, r)
+	if strings.Index(got, "a_metric") > strings.Index(got, "b_metric") {
+		t.Errorf("Expected metrics sorted by name:\n%s", got)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected panic on duplicate registration")
+		}
+	}()
+
+	r.NewGaugeFunc("a_metric", "Again.", func() float64 { return 0 })
+}
+
+func TestCounter_Concurrent(t *testing.T) {
+	r := NewRegistry()
+	c := r.NewCounterVec("hits_total", "Hits.")
+
+	var wg sync.WaitGroup
+	for range 50 {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for range 100 {
+				c.With().Inc()
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	if got := scrape(t, r); !strings.Contains(got, "hits_total 5000\n") {
+		t.Errorf("Expected 5000 hits:\n%s", got)
+	}
+}
+
+func TestRegisterRuntime(t *testing.T) {
+	r := NewRegistry()
+	RegisterRuntime(r)
+
+	got := scrape(t, r)
+	for _, want := range []string{"go_goroutines ", "go_memstats_alloc_bytes ", "go_gc_cycles_total ", "go_info{version=\"go"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Expected %q in:\n%s", want, got)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package metrics
+
+import (
+	"runtime"
+	"sync"
+	"time"
+)
+
+// RegisterRuntime registers Go runtime and process metrics.
+func RegisterRuntime(r *Registry) {
+	start := float64(time.Now().Unix())
+
+	r.NewGaugeFunc("go_goroutines", "Number of goroutines that currently exist.", func() float64 {
+		return float64(runtime.NumGoroutine())
+	})
+	r.NewGaugeFunc("go_threads", "Number of OS threads that can execute Go code simultaneously.", func() float64 {
+		return float64(runtime.GOMAXPROCS(0))
+	})
+	r.NewGaugeFunc("go_memstats_alloc_bytes", "Number of bytes allocated and still in use.", func() float64 {
+		return float64(readMemStats().HeapAlloc)
+	})
+	r.NewGaugeFunc("go_memstats_sys_bytes", "Number of bytes obtained from system.", func() float64 {
+		return float64(readMemStats().Sys)
+	})
+	r.NewGaugeFunc("go_memstats_heap_objects", "Number of allocated objects.", func() float64 {
+		return float64(readMemStats().HeapObjects)
+	})
+	r.NewCounterFunc("go_gc_cycles_total", "Number of completed GC cycles.", func() float64 {
+		return float64(readMemStats().NumGC)
+	})
+	r.NewCounterFunc("go_gc_pause_seconds_total", "Total GC stop-the-world pause time.", func() float64 {
+		return time.Duration(readMemStats().PauseTotalNs).Seconds() //nolint:gosec
+	})
+	r.NewGaugeFunc("process_start_time_seconds", "Start time of the process since unix epoch in seconds.", func() float64 {
+		return start
+	})
+
+	info := r.NewGaugeVec("go_info", "Information about the Go environment.", "version")
+	info.With(runtime.Version()).Set(1)
+}
+
+// memStatsTTL bounds how often the stop-the-world ReadMemStats runs during a scrape.
+const memStatsTTL = time.Second
+
+var memStatsCache struct {
+	mu      sync.Mutex
+	stats   runtime.MemStats
+	updated time.Time
+}
+
+func readMemStats() runtime.MemStats {
+	memStatsCache.mu.Lock()
+	defer memStatsCache.mu.Unlock()
+
+	if time.Since(memStatsCache.updated) > memStatsTTL {
+		runtime.ReadMemStats(&memStatsCache.stats)
+		memStatsCache.updated = time.Now()
+	}
+
+	return memStatsCache.stats
+}
@@ -0,0 +1,277 @@
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// vec stores series of a metric family keyed by label values.
+type vec[T any] struct {
+	metricName string
+	help       string
+	labels     []string
+	newSeries  func() *T
+
+	mu     sync.RWMutex
+	series map[string]*T
+	values map[string][]string
+}
+
+func newVec[T any](name, help string, labels []string, newSeries func() *T) vec[T] {
+	return vec[T]{
+		metricName: name,
+		help:       help,
+		labels:     labels,
+		newSeries:  newSeries,
+		series:     make(map[string]*T),
+		values:     make(map[string][]string),
+	}
+}
+
+func (v *vec[T]) name() string {
+	return v.metricName
+}
+
+// with returns the series for the label values, creating it if needed.
+func (v *vec[T]) with(values ...string) *T {
+	if len(values) != len(v.labels) {
+		panic(fmt.Sprintf("metrics: %s expects %d label values, got %d", v.metricName, len(v.labels), len(values)))
+	}
+
+	key := strings.Join(values, "\xff")
+
+	v.mu.RLock()
+	s, ok := v.series[key]
+	v.mu.RUnlock()
+
+	if ok {
+		return s
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if s, ok := v.series[key]; ok {
+		return s
+	}
+
+	s = v.newSeries()
+	v.series[key] = s
+	v.values[key] = slices.Clone(values)
+
+	return s
+}
+
+// each calls fn for all series ordered by their label values.
+func (v *vec[T]) each(fn func(values []string, s *T)) {
+	v.mu.RLock()
+	keys := make([]string, 0, len(v.series))
+
+	for key := range v.series {
+		keys = append(keys, key)
+	}
+
+	v.mu.RUnlock()
+
+	slices.Sort(keys)
+
+	for _, key := range keys {
+		v.mu.RLock()
+		s, values := v.series[key], v.values[key]
+		v.mu.RUnlock()
+
+		fn(values, s)
+	}
+}
+
+// Counter is a monotonically increasing value.
+type Counter struct {
+	value atomicFloat
+}
+
+// Inc increments the counter by one.
+func (c *Counter) Inc() {
+	c.value.add(1)
+}
+
+// Add increases the counter by v, which must not be negative.
+func (c *Counter) Add(v float64) {
+	if v < 0 {
+		panic("metrics: counter cannot decrease")
+	}
+
+	c.value.add(v)
+}
+
+// CounterVec is a family of counters partitioned by labels.
+type CounterVec struct {
+	vec[Counter]
+}
+
+// NewCounterVec registers a new CounterVec.
+func (r *Registry) NewCounterVec(name, help string, labels ...string) *CounterVec {
+	c := &CounterVec{newVec(name, help, labels, func() *Counter { return &Counter{} })}
+	r.register(c)
+
+	return c
+}
+
+// With returns the counter for the given label values.
+func (c *CounterVec) With(values ...string) *Counter {
+	return c.with(values...)
+}
+
+func (c *CounterVec) write(w *bufio.Writer) {
+	writeHeader(w, c.metricName, c.help, "counter")
+	c.each(func(values []string, s *Counter) {
+		writeSample(w, c.metricName, c.labels, values, s.value.load())
+	})
+}
+
+// Gauge is a value that can go up and down.
+type Gauge struct {
+	value atomicFloat
+}
+
+// Set sets the gauge to v.
+func (g *Gauge) Set(v float64) {
+	g.value.set(v)
+}
+
+// Add adds v to the gauge.
+func (g *Gauge) Add(v float64) {
+	g.value.add(v)
+}
+
+// Inc increments the gauge by one.
+func (g *Gauge) Inc() {
+	g.value.add(1)
+}
+
+// Dec decrements the gauge by one.
+func (g *Gauge) Dec() {
+	g.value.add(-1)
+}
+
+// GaugeVec is a family of gauges partitioned by labels.
+type GaugeVec struct {
+	vec[Gauge]
+}
+
+// NewGaugeVec registers a new GaugeVec.
+func (r *Registry) NewGaugeVec(name, help string, labels ...string) *GaugeVec {
+	g := &GaugeVec{newVec(name, help, labels, func() *Gauge { return &Gauge{} })}
+	r.register(g)
+
+	return g
+}
+
+// With returns the gauge for the given label values.
+func (g *GaugeVec) With(values ...string) *Gauge {
+	return g.with(values...)
+}
+
+func (g *GaugeVec) write(w *bufio.Writer) {
+	writeHeader(w, g.metricName, g.help, "gauge")
+	g.each(func(values []string, s *Gauge) {
+		writeSample(w, g.metricName, g.labels, values, s.value.load())
+	})
+}
+
+// DefBuckets are histogram buckets suited for request latencies in seconds.
+var DefBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// Histogram counts observations in configurable buckets.
+type Histogram struct {
+	upperBounds []float64
+	counts      []atomicFloat
+	sum         atomicFloat
+	count       atomicFloat
+}
+
+// Observe adds a single observation.
+func (h *Histogram) Observe(v float64) {
+	i, _ := slices.BinarySearch(h.upperBounds, v)
+	if i < len(h.counts) {
+		h.counts[i].add(1)
+	}
+
+	h.sum.add(v)
+	h.count.add(1)
+}
+
+// HistogramVec is a family of histograms partitioned by labels.
+type HistogramVec struct {
+	vec[Histogram]
+}
+
+// NewHistogramVec registers a new HistogramVec with the given bucket upper bounds.
+func (r *Registry) NewHistogramVec(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	bounds := slices.Clone(buckets)
+	slices.Sort(bounds)
+
+	h := &HistogramVec{newVec(name, help, labels, func() *Histogram {
+		return &Histogram{
+			upperBounds: bounds,
+			counts:      make([]atomicFloat, len(bounds)),
+		}
+	})}
+	r.register(h)
+
+	return h
+}
+
+// With returns the histogram for the given label values.
+func (h *HistogramVec) With(values ...string) *Histogram {
+	return h.with(values...)
+}
+
+func (h *HistogramVec) write(w *bufio.Writer) {
+	writeHeader(w, h.metricName, h.help, "histogram")
+
+	labels := append(slices.Clone(h.labels), "le")
+
+	h.each(func(values []string, s *Histogram) {
+		var cumulative float64
+
+		for i, bound := range s.upperBounds {
+			cumulative += s.counts[i].load()
+			writeSample(w, h.metricName+"_bucket", labels, append(slices.Clone(values), formatFloat(bound)), cumulative)
+		}
+
+		count := s.count.load()
+		writeSample(w, h.metricName+"_bucket", labels, append(slices.Clone(values), "+Inf"), count)
+		writeSample(w, h.metricName+"_sum", h.labels, values, s.sum.load())
+		writeSample(w, h.metricName+"_count", h.labels, values, count)
+	})
+}
+
+// funcMetric is a metric whose value is computed at scrape time.
+type funcMetric struct {
+	metricName string
+	help       string
+	typ        string
+	fn         func() float64
+}
+
+func (f *funcMetric) name() string {
+	return f.metricName
+}
+
+func (f *funcMetric) write(w *bufio.Writer) {
+	writeHeader(w, f.metricName, f.help, f.typ)
+	writeSample(w, f.metricName, nil, nil, f.fn())
+}
+
+// NewGaugeFunc registers a gauge whose value is returned by fn on every scrape.
+func (r *Registry) NewGaugeFunc(name, help string, fn func() float64) {
+	r.register(&funcMetric{metricName: name, help: help, typ: "gauge", fn: fn})
+}
+
+// NewCounterFunc registers a counter whose value is returned by fn on every scrape.
+func (r *Registry) NewCounterFunc(name, help string, fn func() float64) {
+	r.register(&funcMetric{metricName: name, help: help, typ: "counter", fn: fn})
+}
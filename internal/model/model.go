@@ -2,6 +2,7 @@
 package model
 
 import (
+	"encoding/xml"
 	"time"
 )
 
@@ -9,10 +10,15 @@ import (
 //
 //nolint:godox
 type ToDo struct {
-	ID          int       `json:"id"`
-	Caption     string    `json:"caption"`
-	Description string    `json:"description"`
-	IsCompleted bool      `json:"is_completed"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	XMLName     xml.Name   `json:"-"                xml:"todo"`
+	ID          int        `json:"id"               xml:"id"`
+	Caption     string     `json:"caption"          xml:"caption"`
+	Description string     `json:"description"      xml:"description"`
+	IsCompleted bool       `json:"is_completed"     xml:"is_completed"`
+	DueAt       *time.Time `json:"due_at,omitempty" xml:"due_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"       xml:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"       xml:"updated_at"`
+	// UID is the iCalendar UID of an item imported from another calendar;
+	// it is empty for items created here.
+	UID string `json:"-" xml:"-"`
 }
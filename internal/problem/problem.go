@@ -0,0 +1,99 @@
+// Package problem provides RFC 9457 Problem Details error responses.
+package problem
+
+import (
+	"encoding/xml"
+	"net/http"
+
+	"ecom-internship/internal/codec"
+	"ecom-internship/internal/httputils"
+)
+
+// Problem types.
+const (
+	// TypeBlank is used when the problem has no semantics beyond the HTTP status code.
+	TypeBlank = "about:blank"
+	// TypeValidation is used when the request contains invalid fields.
+	TypeValidation = "/problems/validation-error"
+)
+
+// Problem media types.
+const (
+	ContentTypeJSON = "application/problem+json"
+	ContentTypeXML  = "application/problem+xml"
+)
+
+// Details is an RFC 9457 problem details object.
+type Details struct {
+	XMLName   xml.Name     `json:"-"                    xml:"urn:ietf:rfc:7807 problem"`
+	Type      string       `json:"type"                 xml:"type"`
+	Title     string       `json:"title"                xml:"title"`
+	Status    int          `json:"status"               xml:"status"`
+	Detail    string       `json:"detail,omitempty"     xml:"detail,omitempty"`
+	Instance  string       `json:"instance,omitempty"   xml:"instance,omitempty"`
+	RequestID string       `json:"request_id,omitempty" xml:"request_id,omitempty"`
+	Errors    []FieldError `json:"errors,omitempty"     xml:"errors>error,omitempty"`
+}
+
+// FieldError describes a single invalid part of the request.
+type FieldError struct {
+	// Pointer is a JSON Pointer to the invalid member, e.g. "#/caption".
+	Pointer string `json:"pointer,omitempty" xml:"pointer,omitempty"`
+	// Row is the number of the invalid row of bulk requests.
+	Row    int    `json:"row,omitempty"     xml:"row,omitempty"`
+	Detail string `json:"detail"            xml:"detail"`
+}
+
+// New creates a problem for the request with the given status and detail.
+func New(r *http.Request, status int, detail string) *Details {
+	return &Details{
+		Type:      TypeBlank,
+		Title:     http.StatusText(status),
+		Status:    status,
+		Detail:    detail,
+		Instance:  r.URL.Path,
+		RequestID: httputils.RequestID(r),
+	}
+}
+
+// Validation creates a problem describing invalid request fields.
+func Validation(r *http.Request, status int, detail string, errs []FieldError) *Details {
+	p := New(r, status, detail)
+	p.Type = TypeValidation
+	p.Title = "Validation failed"
+	p.Errors = errs
+
+	return p
+}
+
+// Write sends a problem with the given status and detail.
+func Write(w http.ResponseWriter, r *http.Request, status int, detail string) {
+	WriteDetails(w, r, New(r, status, detail))
+}
+
+// WriteDetails sends p encoded in the format accepted by the client.
+// JSON is used if the client accepts none of the supported formats.
+func WriteDetails(w http.ResponseWriter, r *http.Request, p *Details) {
+	c, err := codec.Negotiate(r.Header.Get("Accept"))
+	if err != nil {
+		c = codec.JSON
+	}
+
+	w.Header().Set("Content-Type", contentType(c))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(p.Status)
+
+	// The status is already sent, so encoding errors can't be reported.
+	c.Encode(w, p) //nolint:errcheck,gosec
+}
+
+func contentType(c codec.Codec) string {
+	switch c {
+	case codec.JSON:
+		return ContentTypeJSON
+	case codec.XML:
+		return ContentTypeXML
+	default:
+		return c.ContentType()
+	}
+}
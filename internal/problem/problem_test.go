@@ -0,0 +1,80 @@
+package problem
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"ecom-internship/internal/httputils"
+)
+
+func TestWrite_JSON(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/todos/999", nil)
+	req = req.WithContext(httputils.WithRequestID(req.Context(), "req-1"))
+	w := httptest.NewRecorder()
+
+	Write(w, req, http.StatusNotFound, "ToDo id not found")
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404, got %d", w.Code)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != ContentTypeJSON {
+		t.Errorf("Expected %s, got %s", ContentTypeJSON, ct)
+	}
+
+	var p Details
+	if err := json.Unmarshal(w.Body.Bytes(), &p); err != nil {
+		t.Fatalf("Failed to unmarshal problem: %v", err)
+	}
+
+	want := Details{
+		Type:      TypeBlank,
+		Title:     "Not Found",
+		Status:    http.StatusNotFound,
+		Detail:    "ToDo id not found",
+		Instance:  "/todos/999",
+		RequestID: "req-1",
+	}
+	if p.Type != want.Type || p.Title != want.Title || p.Status != want.Status ||
+		p.Detail != want.Detail || p.Instance != want.Instance || p.RequestID != want.RequestID {
+		t.Errorf("Expected %+v, got %+v", want, p)
+	}
+}
+
+func TestWriteDetails_Validation(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/todos", nil)
+	req.Header.Set("Accept", "application/xml")
+	w := httptest.NewRecorder()
+
+	WriteDetails(w, req, Validation(req, http.StatusBadRequest, "Invalid todo",
+		[]FieldError{{Pointer: "#/caption", Detail: "must not be empty"}}))
+
+	if ct := w.Header().Get("Content-Type"); ct != ContentTypeXML {
+		t.Errorf("Expected %s, got %s", ContentTypeXML, ct)
+	}
+
+	body := w.Body.String()
+	for _, want := range []string{
+		`<problem xmlns="urn:ietf:rfc:7807">`,
+		"<type>/problems/validation-error</type>",
+		"<errors><error><pointer>#/caption</pointer><detail>must not be empty</detail></error></errors>",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("Expected %q in %s", want, body)
+		}
+	}
+}
+
+func TestWrite_UnsupportedAccept(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/todos", nil)
+	req.Header.Set("Accept", "text/html")
+	w := httptest.NewRecorder()
+
+	Write(w, req, http.StatusNotAcceptable, "Not acceptable")
+
+	if ct := w.Header().Get("Content-Type"); ct != ContentTypeJSON {
+		t.Errorf("Expected fallback to %s, got %s", ContentTypeJSON, ct)
+	}
+}
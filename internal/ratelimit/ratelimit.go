@@ -0,0 +1,160 @@
+// Package ratelimit implements per-client token bucket rate limiting.
+package ratelimit
+
+import (
+	"container/list"
+	"math"
+	"sync"
+	"time"
+)
+
+// Decision is the outcome of a rate limit check.
+type Decision struct {
+	Allowed bool
+	// Limit is the bucket capacity.
+	Limit int
+	// Remaining is the number of requests that can be made immediately.
+	Remaining int
+	// Reset is the time until the bucket is full again.
+	Reset time.Duration
+	// RetryAfter is the time until the next request is allowed; zero if allowed.
+	RetryAfter time.Duration
+}
+
+type bucket struct {
+	key      string
+	tokens   float64
+	lastSeen time.Time
+}
+
+// Limiter keeps a token bucket per client key refilled at a constant rate.
+// Buckets idle for longer than the idle TTL are evicted and the number of
+// buckets is capped, so memory stays bounded regardless of the number of clients.
+// Buckets are kept in least recently seen order, so evictions take constant time.
+type Limiter struct {
+	rate       float64
+	burst      int
+	idleTTL    time.Duration
+	maxBuckets int
+
+	mu      sync.Mutex
+	buckets map[string]*list.Element
+	// lru holds the buckets, the most recently seen first.
+	lru *list.List
+}
+
+// New creates a Limiter allowing rate requests per second with bursts of burst requests.
+func New(rate float64, burst int, idleTTL time.Duration, maxBuckets int) *Limiter {
+	return &Limiter{
+		rate:       rate,
+		burst:      burst,
+		idleTTL:    idleTTL,
+		maxBuckets: maxBuckets,
+		buckets:    make(map[string]*list.Element),
+		lru:        list.New(),
+	}
+}
+
+// Allow takes a token from the bucket of key if one is available.
+func (l *Limiter) Allow(key string, now time.Time) Decision {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.evictIdle(now)
+
+	var b *bucket
+
+	if e, ok := l.buckets[key]; ok {
+		l.lru.MoveToFront(e)
+		b = e.Value.(*bucket) //nolint:forcetypeassert
+	} else {
+		if len(l.buckets) >= l.maxBuckets {
+			l.evictOne()
+		}
+
+		b = &bucket{key: key, tokens: float64(l.burst), lastSeen: now}
+		l.buckets[key] = l.lru.PushFront(b)
+	}
+
+	if elapsed := now.Sub(b.lastSeen).Seconds(); elapsed > 0 {
+		b.tokens = math.Min(float64(l.burst), b.tokens+elapsed*l.rate)
+	}
+
+	b.lastSeen = now
+
+	d := Decision{Limit: l.burst}
+
+	if b.tokens >= 1 {
+		b.tokens--
+		d.Allowed = true
+	} else {
+		d.RetryAfter = l.duration(1 - b.tokens)
+	}
+
+	d.Remaining = int(b.tokens)
+	d.Reset = l.duration(float64(l.burst) - b.tokens)
+
+	return d
+}
+
+// SetLimits changes the limits applied from the next request on. Existing buckets
+// are kept with their tokens capped at the new burst; the least recently seen
+// ones are evicted if there are more than maxBuckets.
+func (l *Limiter) SetLimits(rate float64, burst int, idleTTL time.Duration, maxBuckets int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.rate = rate
+	l.burst = burst
+	l.idleTTL = idleTTL
+	l.maxBuckets = maxBuckets
+
+	for len(l.buckets) > maxBuckets {
+		l.evictOne()
+	}
+
+	for e := l.lru.Front(); e != nil; e = e.Next() {
+		b := e.Value.(*bucket) //nolint:forcetypeassert
+		b.tokens = math.Min(float64(burst), b.tokens)
+	}
+}
+
+// Len returns the number of tracked clients.
+func (l *Limiter) Len() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return len(l.buckets)
+}
+
+// duration returns the time needed to refill the given number of tokens.
+func (l *Limiter) duration(tokens float64) time.Duration {
+	if tokens <= 0 {
+		return 0
+	}
+
+	return time.Duration(tokens / l.rate * float64(time.Second))
+}
+
+// evictIdle drops the buckets not seen for the idle TTL, starting from the
+// least recently seen one.
+func (l *Limiter) evictIdle(now time.Time) {
+	for e := l.lru.Back(); e != nil; e = l.lru.Back() {
+		if now.Sub(e.Value.(*bucket).lastSeen) < l.idleTTL { //nolint:forcetypeassert
+			return
+		}
+
+		l.evictOne()
+	}
+}
+
+// evictOne drops the least recently seen bucket.
+func (l *Limiter) evictOne() {
+	e := l.lru.Back()
+	if e == nil {
+		return
+	}
+
+	l.lru.Remove(e)
+	delete(l.buckets, e.Value.(*bucket).key) //nolint:forcetypeassert
+}
@@ -0,0 +1,91 @@
+package ratelimit
+
+import (
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestLimiter_Allow(t *testing.T) {
+	l := New(2, 3, time.Minute, 100)
+	now := time.Unix(1000, 0)
+
+	for i := range 3 {
+		d := l.Allow("a", now)
+		if !d.Allowed || d.Remaining != 2-i || d.Limit != 3 {
+			t.Fatalf("Request %d: unexpected decision %+v", i, d)
+		}
+	}
+
+	d := l.Allow("a", now)
+	if d.Allowed || d.RetryAfter != 500*time.Millisecond || d.Reset != 1500*time.Millisecond {
+		t.Errorf("Expected denial with retry after 500ms, got %+v", d)
+	}
+
+	if d := l.Allow("b", now); !d.Allowed {
+		t.Error("Expected independent bucket for another client")
+	}
+
+	if d := l.Allow("a", now.Add(500*time.Millisecond)); !d.Allowed || d.Remaining != 0 {
+		t.Errorf("Expected one refilled token, got %+v", d)
+	}
+
+	if d := l.Allow("a", now.Add(time.Hour)); d.Remaining != 2 {
+		t.Errorf("Expected refill capped at burst, got %+v", d)
+	}
+}
+
+func TestLimiter_Eviction(t *testing.T) {
+	l := New(1, 1, time.Minute, 3)
+	now := time.Unix(1000, 0)
+
+	for i := range 5 {
+		l.Allow(strconv.Itoa(i), now.Add(time.Duration(i)*time.Second))
+	}
+
+	if l.Len() != 3 {
+		t.Errorf("Expected bucket count capped at 3, got %d", l.Len())
+	}
+
+	if d := l.Allow("4", now.Add(4*time.Second)); d.Allowed {
+		t.Error("Expected most recent bucket to be kept")
+	}
+
+	// Seeing a bucket again protects it from the next eviction.
+	l.Allow("2", now.Add(5*time.Second))
+	l.Allow("5", now.Add(5*time.Second))
+
+	if d := l.Allow("2", now.Add(5*time.Second)); d.Allowed {
+		t.Errorf("Expected recently seen bucket to be kept, got %+v", d)
+	}
+
+	l.Allow("fresh", now.Add(2*time.Minute))
+
+	if l.Len() != 1 {
+		t.Errorf("Expected idle buckets to be evicted, got %d", l.Len())
+	}
+}
+
+func TestLimiter_SetLimits(t *testing.T) {
+	l := New(1, 5, time.Minute, 3)
+	now := time.Unix(1000, 0)
+
+	for i := range 3 {
+		l.Allow(strconv.Itoa(i), now.Add(time.Duration(i)*time.Millisecond))
+	}
+
+	now = now.Add(time.Millisecond * 2)
+	l.SetLimits(10, 2, time.Minute, 2)
+
+	if n := l.Len(); n != 2 {
+		t.Errorf("Expected buckets over the new maximum to be evicted, got %d", n)
+	}
+
+	if d := l.Allow("2", now); !d.Allowed || d.Limit != 2 || d.Remaining != 1 {
+		t.Errorf("Expected existing bucket capped at new burst, got %+v", d)
+	}
+
+	if d := l.Allow("new", now); d.Limit != 2 || d.Remaining != 1 {
+		t.Errorf("Expected new bucket with new burst, got %+v", d)
+	}
+}
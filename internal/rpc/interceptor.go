@@ -0,0 +1,78 @@
+package rpc
+
+import (
+	"context"
+	"time"
+
+	"ecom-internship/internal/httputils"
+	"ecom-internship/internal/logger"
+)
+
+// requestIDInterceptor reuses a valid X-Request-ID metadata value of the call
+// or generates one, and returns it in the response headers.
+func requestIDInterceptor(_ logger.Logger, next invoker) invoker {
+	return func(ctx context.Context) error {
+		c := callFromContext(ctx)
+
+		requestID := c.r.Header.Get(httputils.RequestIDHeader)
+		if !httputils.ValidRequestID(requestID) {
+			requestID = httputils.GenerateRequestID()
+		}
+
+		c.header.Set(httputils.RequestIDHeader, requestID)
+
+		return next(httputils.WithRequestID(ctx, requestID))
+	}
+}
+
+// loggingInterceptor logs every call with its status. Calls ending with an
+// unexpected error are logged at error level with the error, which clients
+// do not see.
+func loggingInterceptor(log logger.Logger, next invoker) invoker {
+	return func(ctx context.Context) error {
+		start := time.Now()
+		err := next(ctx)
+
+		c := callFromContext(ctx)
+		st := toStatus(err)
+
+		args := []any{
+			"request_id", httputils.RequestIDFromContext(ctx),
+			"method", c.method,
+			"code", st.code.String(),
+			"messages", c.sent,
+			"remote_addr", c.r.RemoteAddr,
+			"client_cert", httputils.ClientIdentity(c.r),
+			"duration", time.Since(start).String(),
+		}
+
+		log := logger.FromContext(ctx, log)
+
+		if st.code == Internal || st.code == Unknown {
+			log.Error("rpc failed", append(args, "error", err)...)
+		} else {
+			log.Info("rpc completed", args...)
+		}
+
+		return err
+	}
+}
+
+// recoveryInterceptor ends a call whose method panics with Internal.
+func recoveryInterceptor(log logger.Logger, next invoker) invoker {
+	return func(ctx context.Context) (err error) {
+		defer func() {
+			if p := recover(); p != nil {
+				logger.FromContext(ctx, log).Error("recovered from panic",
+					"request_id", httputils.RequestIDFromContext(ctx),
+					"error", p,
+					"method", callFromContext(ctx).method,
+				)
+
+				err = newStatus(Internal, "internal error")
+			}
+		}()
+
+		return next(ctx)
+	}
+}
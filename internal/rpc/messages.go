@@ -0,0 +1,196 @@
+package rpc
+
+import (
+	"ecom-internship/internal/database"
+	"ecom-internship/internal/model"
+)
+
+// Messages of proto/todo/v1/todo.proto. Responses implement marshaler and
+// requests unmarshaler.
+type (
+	marshaler interface {
+		marshal(b []byte) []byte
+	}
+
+	unmarshaler interface {
+		unmarshal(b []byte) error
+	}
+)
+
+// todoMessage is the Todo message.
+type todoMessage model.ToDo
+
+func (m todoMessage) marshal(b []byte) []byte {
+	b = appendVarint(b, 1, uint64(m.ID))
+	b = appendString(b, 2, m.Caption)
+	b = appendString(b, 3, m.Description)
+	b = appendBool(b, 4, m.IsCompleted)
+
+	if m.DueAt != nil {
+		b = appendTimestamp(b, 5, *m.DueAt)
+	}
+
+	b = appendTimestamp(b, 6, m.CreatedAt)
+
+	return appendTimestamp(b, 7, m.UpdatedAt)
+}
+
+func (m *todoMessage) unmarshal(b []byte) error {
+	d := fields{b: b}
+	for d.next() {
+		var err error
+
+		switch d.field {
+		case 1:
+			if d.is(wireVarint) {
+				m.ID = int(int64(d.varint))
+			}
+		case 2:
+			if d.is(wireBytes) {
+				m.Caption = string(d.bytes)
+			}
+		case 3:
+			if d.is(wireBytes) {
+				m.Description = string(d.bytes)
+			}
+		case 4:
+			if d.is(wireVarint) {
+				m.IsCompleted = d.varint != 0
+			}
+		case 5:
+			if d.is(wireBytes) {
+				due, derr := decodeTimestamp(d.bytes)
+				m.DueAt, err = &due, derr
+			}
+		case 6:
+			if d.is(wireBytes) {
+				m.CreatedAt, err = decodeTimestamp(d.bytes)
+			}
+		case 7:
+			if d.is(wireBytes) {
+				m.UpdatedAt, err = decodeTimestamp(d.bytes)
+			}
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return d.err
+}
+
+// decodeTodoField decodes the embedded Todo of field 1 of CreateRequest and UpdateRequest.
+func decodeTodoField(b []byte) (model.ToDo, error) {
+	var todo todoMessage
+
+	d := fields{b: b}
+	for d.next() {
+		if d.field == 1 && d.is(wireBytes) {
+			if err := todo.unmarshal(d.bytes); err != nil {
+				return model.ToDo{}, err
+			}
+		}
+	}
+
+	return model.ToDo(todo), d.err
+}
+
+// idRequest is GetRequest and DeleteRequest.
+type idRequest struct {
+	ID int
+}
+
+func (m *idRequest) unmarshal(b []byte) error {
+	d := fields{b: b}
+	for d.next() {
+		if d.field == 1 && d.is(wireVarint) {
+			m.ID = int(int64(d.varint))
+		}
+	}
+
+	return d.err
+}
+
+type listRequest struct {
+	Completed *bool
+	Query     string
+	PageSize  int
+	PageToken string
+}
+
+func (m *listRequest) unmarshal(b []byte) error {
+	d := fields{b: b}
+	for d.next() {
+		switch d.field {
+		case 1:
+			if d.is(wireVarint) {
+				completed := d.varint != 0
+				m.Completed = &completed
+			}
+		case 2:
+			if d.is(wireBytes) {
+				m.Query = string(d.bytes)
+			}
+		case 3:
+			if d.is(wireVarint) {
+				m.PageSize = int(int32(d.varint))
+			}
+		case 4:
+			if d.is(wireBytes) {
+				m.PageToken = string(d.bytes)
+			}
+		}
+	}
+
+	return d.err
+}
+
+type listResponse struct {
+	ToDos         []model.ToDo
+	NextPageToken string
+}
+
+func (m listResponse) marshal(b []byte) []byte {
+	for _, todo := range m.ToDos {
+		b = appendMessage(b, 1, todoMessage(todo).marshal)
+	}
+
+	return appendString(b, 2, m.NextPageToken)
+}
+
+// todoRequest is CreateRequest and UpdateRequest.
+type todoRequest struct {
+	ToDo model.ToDo
+}
+
+func (m *todoRequest) unmarshal(b []byte) error {
+	var err error
+
+	m.ToDo, err = decodeTodoField(b)
+
+	return err
+}
+
+// emptyMessage is google.protobuf.Empty and WatchRequest.
+type emptyMessage struct{}
+
+func (emptyMessage) marshal(b []byte) []byte { return b }
+
+func (*emptyMessage) unmarshal(b []byte) error {
+	d := fields{b: b}
+	for d.next() {
+	}
+
+	return d.err
+}
+
+// watchEvent is the WatchEvent message; database.EventType values match the
+// numbers of its Type enum.
+type watchEvent database.Event
+
+func (m watchEvent) marshal(b []byte) []byte {
+	b = appendVarint(b, 1, uint64(m.Type))
+
+	return appendMessage(b, 2, todoMessage(m.ToDo).marshal)
+}
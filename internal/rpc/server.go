@@ -0,0 +1,321 @@
+// Package rpc serves the gRPC TodoService defined in proto/todo/v1/todo.proto
+// over HTTP/2 with net/http. Messages are encoded by hand and only the
+// identity message encoding is supported.
+package rpc
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"ecom-internship/internal/database"
+	"ecom-internship/internal/logger"
+	"ecom-internship/internal/validation"
+)
+
+const (
+	contentType = "application/grpc"
+	// servicePrefix is the path prefix of the TodoService methods.
+	servicePrefix = "/todo.v1.TodoService/"
+	// frameHeaderSize is the compression flag and length preceding every message.
+	frameHeaderSize = 5
+)
+
+// invoker runs a call. Interceptors wrap it like middlewares wrap handlers and
+// find the call in its context with callFromContext.
+type invoker func(ctx context.Context) error
+
+// methodHandler decodes the request message and sends the responses: one for
+// unary methods, any number for streaming ones.
+type methodHandler func(ctx context.Context, req []byte, send func(marshaler) error) error
+
+// Server is the http.Handler of the gRPC API.
+type Server struct {
+	log            logger.Logger
+	methods        map[string]methodHandler
+	interceptors   []func(logger.Logger, invoker) invoker
+	maxMessageSize int
+}
+
+// New creates the gRPC API serving the TodoService on db. Todos are
+// validated with v, like those of the REST API, and request messages are
+// limited to maxMessageSize bytes.
+func New(log logger.Logger, db database.Database, v *validation.Validator, maxMessageSize int) *Server {
+	svc := &service{db: db, v: v}
+
+	return &Server{
+		log: log,
+		methods: map[string]methodHandler{
+			"Get":    unary(svc.get),
+			"List":   unary(svc.list),
+			"Create": unary(svc.create),
+			"Update": unary(svc.update),
+			"Delete": unary(svc.delete),
+			"Watch":  serverStream(svc.watch),
+		},
+		// As with middlewares, the first interceptor is the innermost.
+		interceptors: []func(logger.Logger, invoker) invoker{
+			recoveryInterceptor,
+			loggingInterceptor,
+			requestIDInterceptor,
+		},
+		maxMessageSize: maxMessageSize,
+	}
+}
+
+func unary[Req any, PReq interface {
+	*Req
+	unmarshaler
+}, Resp marshaler](h func(context.Context, PReq) (Resp, error)) methodHandler {
+	return func(ctx context.Context, data []byte, send func(marshaler) error) error {
+		req := PReq(new(Req))
+		if err := req.unmarshal(data); err != nil {
+			return newStatus(InvalidArgument, "malformed request message")
+		}
+
+		resp, err := h(ctx, req)
+		if err != nil {
+			return err
+		}
+
+		return send(resp)
+	}
+}
+
+func serverStream[Req any, PReq interface {
+	*Req
+	unmarshaler
+}](h func(context.Context, PReq, func(marshaler) error) error) methodHandler {
+	return func(ctx context.Context, data []byte, send func(marshaler) error) error {
+		req := PReq(new(Req))
+		if err := req.unmarshal(data); err != nil {
+			return newStatus(InvalidArgument, "malformed request message")
+		}
+
+		// Clients learn that the stream is open before the first message.
+		callFromContext(ctx).flushHeader()
+
+		return h(ctx, req, send)
+	}
+}
+
+type callKey struct{}
+
+// call is the state of a call.
+type call struct {
+	// method is the full method name, e.g. /todo.v1.TodoService/Get.
+	method string
+	r      *http.Request
+	w      http.ResponseWriter
+	// header holds the response headers until the first message or the status is sent.
+	header      http.Header
+	wroteHeader bool
+	// sent counts the response messages.
+	sent int
+}
+
+func callFromContext(ctx context.Context) *call {
+	c, _ := ctx.Value(callKey{}).(*call)
+
+	return c
+}
+
+func (c *call) writeHeader() {
+	if c.wroteHeader {
+		return
+	}
+
+	c.wroteHeader = true
+
+	for k, v := range c.header {
+		c.w.Header()[k] = v
+	}
+
+	c.w.WriteHeader(http.StatusOK)
+}
+
+func (c *call) flushHeader() {
+	c.writeHeader()
+
+	http.NewResponseController(c.w).Flush() //nolint:errcheck,gosec
+}
+
+// send writes a response message and flushes it, so that streamed messages
+// reach the client as they are produced.
+func (c *call) send(m marshaler) error {
+	c.writeHeader()
+
+	msg := m.marshal(make([]byte, frameHeaderSize))
+	binary.BigEndian.PutUint32(msg[1:frameHeaderSize], uint32(len(msg)-frameHeaderSize)) //nolint:gosec
+
+	if _, err := c.w.Write(msg); err != nil {
+		return err
+	}
+
+	c.sent++
+
+	return http.NewResponseController(c.w).Flush()
+}
+
+// finish sends the status of the call in the trailers.
+func (c *call) finish(err error) {
+	st := toStatus(err)
+
+	c.writeHeader()
+
+	c.w.Header().Set("Grpc-Status", strconv.Itoa(int(st.code)))
+
+	if st.msg != "" {
+		c.w.Header().Set("Grpc-Message", encodeStatusMessage(st.msg))
+	}
+}
+
+// ServeHTTP serves a gRPC call.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case r.ProtoMajor != 2:
+		http.Error(w, "gRPC requires HTTP/2", http.StatusHTTPVersionNotSupported)
+
+		return
+	case r.Method != http.MethodPost:
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+
+		return
+	case !validContentType(r.Header.Get("Content-Type")):
+		http.Error(w, http.StatusText(http.StatusUnsupportedMediaType), http.StatusUnsupportedMediaType)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Trailer", "Grpc-Status, Grpc-Message")
+
+	c := &call{method: r.URL.Path, r: r, w: w, header: make(http.Header)}
+
+	h := s.handle
+	for _, ic := range s.interceptors {
+		h = ic(s.log, h)
+	}
+
+	c.finish(h(context.WithValue(r.Context(), callKey{}, c)))
+}
+
+// handle reads the request message and runs the method.
+func (s *Server) handle(ctx context.Context) error {
+	c := callFromContext(ctx)
+
+	method, ok := strings.CutPrefix(c.method, servicePrefix)
+	h := s.methods[method]
+
+	if !ok || h == nil {
+		return newStatus(Unimplemented, "unknown method "+c.method)
+	}
+
+	if enc := c.r.Header.Get("Grpc-Encoding"); enc != "" && enc != "identity" {
+		c.header.Set("Grpc-Accept-Encoding", "identity")
+
+		return newStatus(Unimplemented, "unsupported message encoding "+enc)
+	}
+
+	if v := c.r.Header.Get("Grpc-Timeout"); v != "" {
+		timeout, err := parseTimeout(v)
+		if err != nil {
+			return newStatus(InvalidArgument, "malformed grpc-timeout")
+		}
+
+		var cancel context.CancelFunc
+
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	req, err := readMessage(c.r.Body, s.maxMessageSize)
+	if err != nil {
+		return err
+	}
+
+	return h(ctx, req, c.send)
+}
+
+func validContentType(ct string) bool {
+	return ct == contentType || ct == contentType+"+proto"
+}
+
+// readMessage reads the single request message of a call.
+func readMessage(r io.Reader, limit int) ([]byte, error) {
+	var hdr [frameHeaderSize]byte
+	if _, err := io.ReadFull(r, hdr[:]); err != nil {
+		return nil, newStatus(InvalidArgument, "missing request message")
+	}
+
+	if hdr[0] != 0 {
+		return nil, newStatus(Unimplemented, "compressed messages are not supported")
+	}
+
+	size := binary.BigEndian.Uint32(hdr[1:])
+	if int64(size) > int64(limit) {
+		return nil, newStatus(ResourceExhausted, fmt.Sprintf("request message larger than %d bytes", limit))
+	}
+
+	msg := make([]byte, size)
+	if _, err := io.ReadFull(r, msg); err != nil {
+		return nil, newStatus(InvalidArgument, "truncated request message")
+	}
+
+	return msg, nil
+}
+
+var errInvalidTimeout = errors.New("invalid grpc-timeout")
+
+// timeoutUnits maps the units of grpc-timeout to durations.
+var timeoutUnits = map[byte]time.Duration{
+	'H': time.Hour,
+	'M': time.Minute,
+	'S': time.Second,
+	'm': time.Millisecond,
+	'u': time.Microsecond,
+	'n': time.Nanosecond,
+}
+
+// parseTimeout parses a grpc-timeout value: up to 8 digits and a unit, e.g. "250m".
+func parseTimeout(v string) (time.Duration, error) {
+	const maxDigits = 8
+
+	if len(v) < 2 || len(v) > maxDigits+1 {
+		return 0, errInvalidTimeout
+	}
+
+	unit, ok := timeoutUnits[v[len(v)-1]]
+	if !ok {
+		return 0, errInvalidTimeout
+	}
+
+	n, err := strconv.ParseInt(v[:len(v)-1], 10, 64)
+	if err != nil || n < 0 {
+		return 0, errInvalidTimeout
+	}
+
+	return time.Duration(n) * unit, nil
+}
+
+// encodeStatusMessage percent-encodes grpc-message as the protocol requires.
+func encodeStatusMessage(msg string) string {
+	var b strings.Builder
+
+	for i := range len(msg) {
+		c := msg[i]
+		if c >= ' ' && c <= '~' && c != '%' {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+
+	return b.String()
+}
@@ -0,0 +1,460 @@
+package rpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"ecom-internship/internal/config"
+	"ecom-internship/internal/database"
+	"ecom-internship/internal/database/mem"
+	"ecom-internship/internal/httputils"
+	"ecom-internship/internal/logger/std"
+	"ecom-internship/internal/model"
+	"ecom-internship/internal/validation"
+)
+
+// testServer serves the gRPC API on an in-memory storage over h2c.
+// Long-lived calls end once draining is closed.
+func testServer(t *testing.T, draining <-chan struct{}) (*httptest.Server, *mem.MemDB) {
+	t.Helper()
+
+	db := mem.New(std.New("error"))
+	v := validation.New(&config.ValidationConfig{
+		MaxCaptionLength:   200,
+		MaxDescriptionSize: 4096,
+		MaxDueIn:           24 * time.Hour,
+	})
+
+	srv := httptest.NewUnstartedServer(New(std.New("error"), db, v, 1024))
+
+	srv.Config.BaseContext = func(net.Listener) context.Context {
+		return httputils.WithDraining(context.Background(), draining)
+	}
+
+	var protocols http.Protocols
+	protocols.SetHTTP1(true)
+	protocols.SetUnencryptedHTTP2(true)
+	srv.Config.Protocols = &protocols
+
+	srv.Start()
+	t.Cleanup(srv.Close)
+
+	return srv, db
+}
+
+func testClient() *http.Client {
+	var protocols http.Protocols
+	protocols.SetUnencryptedHTTP2(true)
+
+	return &http.Client{Transport: &http.Transport{Protocols: &protocols}, Timeout: 5 * time.Second}
+}
+
+// frame prefixes an encoded message with the gRPC message header.
+func frame(msg []byte) []byte {
+	b := make([]byte, frameHeaderSize, frameHeaderSize+len(msg))
+	binary.BigEndian.PutUint32(b[1:], uint32(len(msg))) //nolint:gosec
+
+	return append(b, msg...)
+}
+
+// start opens a call of method with the request message msg.
+func start(t *testing.T, ctx context.Context, srv *httptest.Server, method string, msg []byte) *http.Response {
+	t.Helper()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, srv.URL+servicePrefix+method, bytes.NewReader(frame(msg)))
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+
+	req.Header.Set("Content-Type", "application/grpc")
+	req.Header.Set(httputils.RequestIDHeader, "rpc-test-1")
+
+	resp, err := testClient().Do(req)
+	if err != nil {
+		t.Fatalf("Call failed: %v", err)
+	}
+
+	t.Cleanup(func() { resp.Body.Close() })
+
+	return resp
+}
+
+// next reads the following response message; ok is false at the end of the stream.
+func next(t *testing.T, r io.Reader) ([]byte, bool) {
+	t.Helper()
+
+	var hdr [frameHeaderSize]byte
+	if _, err := io.ReadFull(r, hdr[:]); err != nil {
+		return nil, false
+	}
+
+	msg := make([]byte, binary.BigEndian.Uint32(hdr[1:]))
+	if _, err := io.ReadFull(r, msg); err != nil {
+		t.Fatalf("Truncated response message: %v", err)
+	}
+
+	return msg, true
+}
+
+// invoke runs a unary call and returns its response messages and status.
+func invoke(t *testing.T, srv *httptest.Server, method string, msg []byte) ([][]byte, Code, string) {
+	t.Helper()
+
+	resp := start(t, context.Background(), srv, method, msg)
+
+	var msgs [][]byte
+	for m, ok := next(t, resp.Body); ok; m, ok = next(t, resp.Body) {
+		msgs = append(msgs, m)
+	}
+
+	code, text := status(t, resp)
+
+	return msgs, code, text
+}
+
+// status returns the status from the trailers of a finished call.
+func status(t *testing.T, resp *http.Response) (Code, string) {
+	t.Helper()
+
+	code, err := strconv.Atoi(resp.Trailer.Get("Grpc-Status"))
+	if err != nil {
+		t.Fatalf("Missing grpc-status in trailers %v", resp.Trailer)
+	}
+
+	return Code(code), resp.Trailer.Get("Grpc-Message")
+}
+
+func decodeTodo(t *testing.T, b []byte) model.ToDo {
+	t.Helper()
+
+	var m todoMessage
+	if err := m.unmarshal(b); err != nil {
+		t.Fatalf("Failed to decode todo: %v", err)
+	}
+
+	return model.ToDo(m)
+}
+
+func decodeList(t *testing.T, b []byte) ([]model.ToDo, string) {
+	t.Helper()
+
+	var (
+		todos []model.ToDo
+		token string
+	)
+
+	d := fields{b: b}
+	for d.next() {
+		switch d.field {
+		case 1:
+			todos = append(todos, decodeTodo(t, d.bytes))
+		case 2:
+			token = string(d.bytes)
+		}
+	}
+
+	if d.err != nil {
+		t.Fatalf("Failed to decode list response: %v", d.err)
+	}
+
+	return todos, token
+}
+
+func todoRequestMessage(todo model.ToDo) []byte {
+	return appendMessage(nil, 1, todoMessage(todo).marshal)
+}
+
+func TestServer_CRUD(t *testing.T) {
+	srv, _ := testServer(t, nil)
+
+	due := time.Now().Add(time.Hour).UTC().Truncate(time.Microsecond)
+
+	msgs, code, msg := invoke(t, srv, "Create", todoRequestMessage(model.ToDo{Caption: "Buy milk", DueAt: &due}))
+	if code != OK || len(msgs) != 1 {
+		t.Fatalf("Create: expected one message and OK, got %d messages and %s %q", len(msgs), code, msg)
+	}
+
+	created := decodeTodo(t, msgs[0])
+	if created.ID == 0 || created.Caption != "Buy milk" || created.DueAt == nil || !created.DueAt.Equal(due) ||
+		created.CreatedAt.IsZero() {
+		t.Errorf("Unexpected created todo: %+v", created)
+	}
+
+	created.Caption = "Buy oat milk"
+	created.IsCompleted = true
+
+	msgs, code, _ = invoke(t, srv, "Update", todoRequestMessage(created))
+	if code != OK {
+		t.Fatalf("Update: expected OK, got %s", code)
+	}
+
+	if updated := decodeTodo(t, msgs[0]); updated.Caption != "Buy oat milk" || !updated.IsCompleted {
+		t.Errorf("Unexpected updated todo: %+v", updated)
+	}
+
+	id := appendVarint(nil, 1, uint64(created.ID)) //nolint:gosec
+
+	msgs, code, _ = invoke(t, srv, "Get", id)
+	if code != OK || decodeTodo(t, msgs[0]).Caption != "Buy oat milk" {
+		t.Errorf("Get: unexpected result %s %v", code, msgs)
+	}
+
+	if msgs, code, _ = invoke(t, srv, "Delete", id); code != OK || len(msgs) != 1 || len(msgs[0]) != 0 {
+		t.Errorf("Delete: expected an empty message and OK, got %s %v", code, msgs)
+	}
+
+	if _, code, _ = invoke(t, srv, "Get", id); code != NotFound {
+		t.Errorf("Get after Delete: expected NOT_FOUND, got %s", code)
+	}
+}
+
+func TestServer_Errors(t *testing.T) {
+	srv, db := testServer(t, nil)
+
+	if _, err := db.CreateToDo(context.Background(), model.ToDo{ID: 7, Caption: "Existing"}); err != nil {
+		t.Fatalf("CreateToDo failed: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		method string
+		msg    []byte
+		code   Code
+	}{
+		{"missing todo", "Get", appendVarint(nil, 1, 42), NotFound},
+		{"duplicate id", "Create", todoRequestMessage(model.ToDo{ID: 7, Caption: "Duplicate"}), AlreadyExists},
+		{"empty caption", "Create", todoRequestMessage(model.ToDo{}), InvalidArgument},
+		{"update without id", "Update", todoRequestMessage(model.ToDo{Caption: "No id"}), InvalidArgument},
+		{"malformed message", "Get", []byte{0xff}, InvalidArgument},
+		{"too large message", "Create", todoRequestMessage(model.ToDo{Caption: string(make([]byte, 2048))}), ResourceExhausted},
+		{"negative page size", "List", appendVarint(nil, 3, uint64(0xffffffffffffffff)), InvalidArgument},
+		{"invalid page token", "List", appendString(nil, 4, "not a token"), InvalidArgument},
+		{"unknown method", "Archive", nil, Unimplemented},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msgs, code, msg := invoke(t, srv, tt.method, tt.msg)
+			if code != tt.code {
+				t.Errorf("Expected %s, got %s %q", tt.code, code, msg)
+			}
+
+			if len(msgs) != 0 {
+				t.Errorf("Expected no response messages, got %d", len(msgs))
+			}
+		})
+	}
+}
+
+func TestServer_List(t *testing.T) {
+	srv, db := testServer(t, nil)
+
+	for i, caption := range []string{"Write report", "Buy milk", "Review report", "Call mom", "Report bug"} {
+		_, err := db.CreateToDo(context.Background(), model.ToDo{Caption: caption, IsCompleted: i%2 == 0})
+		if err != nil {
+			t.Fatalf("CreateToDo failed: %v", err)
+		}
+	}
+
+	req := appendBool(nil, 1, true)
+	req = appendString(req, 2, "REPORT")
+	req = appendVarint(req, 3, 1)
+
+	var captions []string
+
+	for pages := 0; ; pages++ {
+		if pages > 3 {
+			t.Fatal("Too many pages")
+		}
+
+		msgs, code, msg := invoke(t, srv, "List", req)
+		if code != OK {
+			t.Fatalf("List: expected OK, got %s %q", code, msg)
+		}
+
+		todos, token := decodeList(t, msgs[0])
+		for _, todo := range todos {
+			captions = append(captions, todo.Caption)
+		}
+
+		if token == "" {
+			break
+		}
+
+		req = appendString(appendVarint(appendString(appendBool(nil, 1, true), 2, "REPORT"), 3, 1), 4, token)
+	}
+
+	// Completed todos mentioning a report, in ID order.
+	if len(captions) != 3 || captions[0] != "Write report" || captions[1] != "Review report" ||
+		captions[2] != "Report bug" {
+		t.Errorf("Unexpected todos: %v", captions)
+	}
+
+	msgs, _, _ := invoke(t, srv, "List", nil)
+	if todos, token := decodeList(t, msgs[0]); len(todos) != 5 || token != "" {
+		t.Errorf("Expected all 5 todos on one page, got %d and token %q", len(todos), token)
+	}
+}
+
+func TestServer_Watch(t *testing.T) {
+	draining := make(chan struct{})
+
+	srv, db := testServer(t, draining)
+
+	resp := start(t, context.Background(), srv, "Watch", nil)
+
+	if got := resp.Header.Get(httputils.RequestIDHeader); got != "rpc-test-1" {
+		t.Errorf("Expected the request ID to be echoed, got %q", got)
+	}
+
+	ctx := context.Background()
+
+	id, err := db.CreateToDo(ctx, model.ToDo{Caption: "Watched"})
+	if err != nil {
+		t.Fatalf("CreateToDo failed: %v", err)
+	}
+
+	if err := db.DeleteToDo(ctx, id); err != nil {
+		t.Fatalf("DeleteToDo failed: %v", err)
+	}
+
+	for _, want := range []database.EventType{database.EventCreated, database.EventDeleted} {
+		msg, ok := next(t, resp.Body)
+		if !ok {
+			t.Fatalf("Stream ended before the %d event", want)
+		}
+
+		var (
+			typ  database.EventType
+			todo model.ToDo
+		)
+
+		d := fields{b: msg}
+		for d.next() {
+			switch d.field {
+			case 1:
+				typ = database.EventType(d.varint) //nolint:gosec
+			case 2:
+				todo = decodeTodo(t, d.bytes)
+			}
+		}
+
+		// Events of deleted todos carry only the ID.
+		if typ != want || todo.ID != id || (want == database.EventCreated && todo.Caption != "Watched") {
+			t.Errorf("Expected event %d of todo %d, got %d %+v", want, id, typ, todo)
+		}
+	}
+
+	close(draining)
+
+	if _, ok := next(t, resp.Body); ok {
+		t.Fatal("Expected the stream to end when the server drains")
+	}
+
+	if code, _ := status(t, resp); code != Unavailable {
+		t.Errorf("Expected UNAVAILABLE on shutdown, got %s", code)
+	}
+}
+
+func TestServer_RejectsNonGRPC(t *testing.T) {
+	srv, _ := testServer(t, nil)
+
+	tests := []struct {
+		name        string
+		client      *http.Client
+		method      string
+		contentType string
+		want        int
+	}{
+		{"http/1.1", srv.Client(), http.MethodPost, "application/grpc", http.StatusHTTPVersionNotSupported},
+		{"get", testClient(), http.MethodGet, "application/grpc", http.StatusMethodNotAllowed},
+		{"json", testClient(), http.MethodPost, "application/json", http.StatusUnsupportedMediaType},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequestWithContext(context.Background(), tt.method, srv.URL+servicePrefix+"Get", nil)
+			if err != nil {
+				t.Fatalf("Failed to create request: %v", err)
+			}
+
+			req.Header.Set("Content-Type", tt.contentType)
+
+			resp, err := tt.client.Do(req)
+			if err != nil {
+				t.Fatalf("Request failed: %v", err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != tt.want {
+				t.Errorf("Expected status %d, got %d", tt.want, resp.StatusCode)
+			}
+		})
+	}
+}
+
+func TestParseTimeout(t *testing.T) {
+	tests := []struct {
+		value string
+		want  time.Duration
+		ok    bool
+	}{
+		{"250m", 250 * time.Millisecond, true},
+		{"1H", time.Hour, true},
+		{"99999999n", 99999999 * time.Nanosecond, true},
+		{"100", 0, false},
+		{"m", 0, false},
+		{"123456789S", 0, false},
+		{"-1S", 0, false},
+		{"10x", 0, false},
+	}
+
+	for _, tt := range tests {
+		got, err := parseTimeout(tt.value)
+		if (err == nil) != tt.ok || got != tt.want {
+			t.Errorf("parseTimeout(%q) = %v, %v; want %v, ok %v", tt.value, got, err, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestTodoMessage_RoundTrip(t *testing.T) {
+	due := time.Date(2026, 3, 1, 12, 30, 0, 500, time.UTC)
+	todo := model.ToDo{
+		ID:          -3,
+		Caption:     "Привет",
+		Description: "multi\nline",
+		IsCompleted: true,
+		DueAt:       &due,
+		CreatedAt:   time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		UpdatedAt:   time.Date(1960, 1, 1, 0, 0, 0, 1, time.UTC),
+	}
+
+	// Unknown fields of newer clients are skipped.
+	b := appendString(todoMessage(todo).marshal(nil), 15, "unknown")
+
+	got := decodeTodo(t, b)
+	if got.ID != todo.ID || got.Caption != todo.Caption || got.Description != todo.Description ||
+		!got.IsCompleted || !got.DueAt.Equal(due) || !got.CreatedAt.Equal(todo.CreatedAt) ||
+		!got.UpdatedAt.Equal(todo.UpdatedAt) {
+		t.Errorf("Round trip changed the todo: %+v", got)
+	}
+
+	var m todoMessage
+	if err := m.unmarshal(b[:len(b)-3]); err == nil {
+		t.Error("Expected an error for a truncated message")
+	}
+}
+
+func TestEncodeStatusMessage(t *testing.T) {
+	if got := encodeStatusMessage("caption: 100% пусто"); got != "caption: 100%25 %D0%BF%D1%83%D1%81%D1%82%D0%BE" {
+		t.Errorf("Unexpected encoding %q", got)
+	}
+}
@@ -0,0 +1,212 @@
+package rpc
+
+import (
+	"cmp"
+	"context"
+	"encoding/base64"
+	"errors"
+	"slices"
+	"strconv"
+	"strings"
+
+	"ecom-internship/internal/database"
+	"ecom-internship/internal/httputils"
+	"ecom-internship/internal/model"
+	"ecom-internship/internal/validation"
+)
+
+// Page sizes of List.
+const (
+	defaultPageSize = 50
+	maxPageSize     = 1000
+)
+
+// pageTokenPrefix tells page tokens apart from arbitrary strings.
+const pageTokenPrefix = "after:"
+
+// service implements the TodoService methods on the storage.
+type service struct {
+	db database.Database
+	v  *validation.Validator
+}
+
+func (s *service) get(ctx context.Context, req *idRequest) (todoMessage, error) {
+	todo, err := s.db.GetToDoByID(ctx, req.ID)
+
+	return todoMessage(todo), err
+}
+
+// list returns the todos matching the filter, ordered by ID. Pages continue
+// after the last ID of the previous page, so items created or deleted in
+// the meantime do not shift them.
+func (s *service) list(ctx context.Context, req *listRequest) (listResponse, error) {
+	size := req.PageSize
+
+	switch {
+	case size < 0:
+		return listResponse{}, newStatus(InvalidArgument, "page_size must not be negative")
+	case size == 0:
+		size = defaultPageSize
+	case size > maxPageSize:
+		size = maxPageSize
+	}
+
+	after, err := decodePageToken(req.PageToken)
+	if err != nil {
+		return listResponse{}, newStatus(InvalidArgument, "invalid page_token")
+	}
+
+	query := strings.ToLower(req.Query)
+
+	var matched []model.ToDo
+
+	err = s.stream(ctx, func(todo model.ToDo) error {
+		if todo.ID > after && matches(todo, req.Completed, query) {
+			matched = append(matched, todo)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return listResponse{}, err
+	}
+
+	slices.SortFunc(matched, func(a, b model.ToDo) int { return cmp.Compare(a.ID, b.ID) })
+
+	resp := listResponse{ToDos: matched}
+	if len(matched) > size {
+		resp.ToDos = matched[:size]
+		resp.NextPageToken = encodePageToken(matched[size-1].ID)
+	}
+
+	return resp, nil
+}
+
+func matches(todo model.ToDo, completed *bool, query string) bool {
+	if completed != nil && todo.IsCompleted != *completed {
+		return false
+	}
+
+	return query == "" ||
+		strings.Contains(strings.ToLower(todo.Caption), query) ||
+		strings.Contains(strings.ToLower(todo.Description), query)
+}
+
+func (s *service) stream(ctx context.Context, fn func(model.ToDo) error) error {
+	if streamer, ok := s.db.(database.Streamer); ok {
+		return streamer.StreamToDos(ctx, fn)
+	}
+
+	toDos, err := s.db.GetAllToDos(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, toDo := range toDos {
+		if err := fn(toDo); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func encodePageToken(after int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(pageTokenPrefix + strconv.Itoa(after)))
+}
+
+func decodePageToken(token string) (int, error) {
+	if token == "" {
+		return 0, nil
+	}
+
+	b, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return 0, err
+	}
+
+	after, ok := strings.CutPrefix(string(b), pageTokenPrefix)
+	if !ok {
+		return 0, errInvalidMessage
+	}
+
+	return strconv.Atoi(after)
+}
+
+func (s *service) create(ctx context.Context, req *todoRequest) (todoMessage, error) {
+	todo := model.ToDo{
+		ID:          req.ToDo.ID,
+		Caption:     req.ToDo.Caption,
+		Description: req.ToDo.Description,
+		IsCompleted: req.ToDo.IsCompleted,
+		DueAt:       req.ToDo.DueAt,
+	}
+
+	if err := s.v.ToDo(todo); err != nil {
+		return todoMessage{}, err
+	}
+
+	id, err := s.db.CreateToDo(ctx, todo)
+	if err != nil {
+		return todoMessage{}, err
+	}
+
+	return s.get(ctx, &idRequest{ID: id})
+}
+
+func (s *service) update(ctx context.Context, req *todoRequest) (todoMessage, error) {
+	if req.ToDo.ID == 0 {
+		return todoMessage{}, newStatus(InvalidArgument, "todo.id is required")
+	}
+
+	todo := model.ToDo{
+		ID:          req.ToDo.ID,
+		Caption:     req.ToDo.Caption,
+		Description: req.ToDo.Description,
+		IsCompleted: req.ToDo.IsCompleted,
+		DueAt:       req.ToDo.DueAt,
+	}
+
+	if err := s.v.ToDo(todo); err != nil {
+		return todoMessage{}, err
+	}
+
+	if err := s.db.UpdateToDo(ctx, todo); err != nil {
+		return todoMessage{}, err
+	}
+
+	return s.get(ctx, &idRequest{ID: todo.ID})
+}
+
+func (s *service) delete(ctx context.Context, req *idRequest) (emptyMessage, error) {
+	return emptyMessage{}, s.db.DeleteToDo(ctx, req.ID)
+}
+
+// watch streams the storage changes until the client cancels the call or
+// the server starts shutting down, which ends the stream with Unavailable.
+func (s *service) watch(ctx context.Context, _ *emptyMessage, send func(marshaler) error) error {
+	w, ok := s.db.(database.Watcher)
+	if !ok {
+		return errors.ErrUnsupported
+	}
+
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	go func() {
+		select {
+		case <-httputils.Draining(ctx):
+			cancel(newStatus(Unavailable, "server is shutting down"))
+		case <-ctx.Done():
+		}
+	}()
+
+	err := w.WatchToDos(ctx, func(e database.Event) error {
+		return send(watchEvent(e))
+	})
+	if errors.Is(err, context.Canceled) {
+		return context.Cause(ctx)
+	}
+
+	return err
+}
@@ -0,0 +1,96 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"ecom-internship/internal/database"
+	"ecom-internship/internal/validation"
+)
+
+// Code is a gRPC status code.
+type Code int
+
+// Status codes used by the service; see google.golang.org/grpc/codes.
+const (
+	OK                Code = 0
+	Canceled          Code = 1
+	Unknown           Code = 2
+	InvalidArgument   Code = 3
+	DeadlineExceeded  Code = 4
+	NotFound          Code = 5
+	AlreadyExists     Code = 6
+	ResourceExhausted Code = 8
+	Unimplemented     Code = 12
+	Internal          Code = 13
+	Unavailable       Code = 14
+)
+
+var codeNames = map[Code]string{
+	OK:                "OK",
+	Canceled:          "CANCELLED",
+	Unknown:           "UNKNOWN",
+	InvalidArgument:   "INVALID_ARGUMENT",
+	DeadlineExceeded:  "DEADLINE_EXCEEDED",
+	NotFound:          "NOT_FOUND",
+	AlreadyExists:     "ALREADY_EXISTS",
+	ResourceExhausted: "RESOURCE_EXHAUSTED",
+	Unimplemented:     "UNIMPLEMENTED",
+	Internal:          "INTERNAL",
+	Unavailable:       "UNAVAILABLE",
+}
+
+func (c Code) String() string {
+	if name, ok := codeNames[c]; ok {
+		return name
+	}
+
+	return fmt.Sprintf("CODE(%d)", int(c))
+}
+
+// statusError is an error with the status a call ends with.
+type statusError struct {
+	code Code
+	msg  string
+}
+
+func (e *statusError) Error() string {
+	return e.code.String() + ": " + e.msg
+}
+
+// newStatus returns an error ending a call with code and msg.
+func newStatus(code Code, msg string) error {
+	return &statusError{code: code, msg: msg}
+}
+
+// toStatus converts the error a call ended with into its status. Storage
+// errors are mapped to their codes; unexpected ones become Internal without
+// details, which are logged instead.
+func toStatus(err error) *statusError {
+	var se *statusError
+	var verrs validation.Errors
+
+	switch {
+	case err == nil:
+		return &statusError{code: OK}
+	case errors.As(err, &se):
+		return se
+	case errors.Is(err, database.ErrNotFound):
+		return &statusError{code: NotFound, msg: "todo not found"}
+	case errors.Is(err, database.ErrIDAlreadyExists):
+		return &statusError{code: AlreadyExists, msg: "todo with this id already exists"}
+	case errors.As(err, &verrs):
+		return &statusError{code: InvalidArgument, msg: "invalid todo: " + verrs.Error()}
+	case errors.Is(err, database.ErrWatchLagged):
+		return &statusError{code: Unavailable, msg: "watch fell behind the changes, list and watch again"}
+	case errors.Is(err, errors.ErrUnsupported):
+		return &statusError{code: Unimplemented, msg: "not supported by the storage"}
+	case errors.Is(err, context.DeadlineExceeded):
+		return &statusError{code: DeadlineExceeded, msg: "deadline exceeded"}
+	case errors.Is(err, context.Canceled):
+		return &statusError{code: Canceled, msg: "call cancelled"}
+	default:
+		return &statusError{code: Internal, msg: "internal error"}
+	}
+}
@@ -0,0 +1,184 @@
+package rpc
+
+import (
+	"encoding/binary"
+	"errors"
+	"time"
+)
+
+// Protobuf wire types.
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+	wireFixed32 = 5
+)
+
+var errInvalidMessage = errors.New("invalid protobuf message")
+
+func appendTag(b []byte, field, wireType int) []byte {
+	return binary.AppendUvarint(b, uint64(field)<<3|uint64(wireType))
+}
+
+// appendVarint appends a varint field, omitting the zero default as proto3 does.
+func appendVarint(b []byte, field int, v uint64) []byte {
+	if v == 0 {
+		return b
+	}
+
+	return binary.AppendUvarint(appendTag(b, field, wireVarint), v)
+}
+
+func appendBool(b []byte, field int, v bool) []byte {
+	if !v {
+		return b
+	}
+
+	return appendVarint(b, field, 1)
+}
+
+func appendString(b []byte, field int, s string) []byte {
+	if s == "" {
+		return b
+	}
+
+	b = binary.AppendUvarint(appendTag(b, field, wireBytes), uint64(len(s)))
+
+	return append(b, s...)
+}
+
+// appendMessage appends an embedded message written by fn, which is
+// present even when empty.
+func appendMessage(b []byte, field int, fn func([]byte) []byte) []byte {
+	msg := fn(nil)
+	b = binary.AppendUvarint(appendTag(b, field, wireBytes), uint64(len(msg)))
+
+	return append(b, msg...)
+}
+
+// appendTimestamp appends a google.protobuf.Timestamp; the zero time is omitted.
+func appendTimestamp(b []byte, field int, t time.Time) []byte {
+	if t.IsZero() {
+		return b
+	}
+
+	return appendMessage(b, field, func(b []byte) []byte {
+		b = appendVarint(b, 1, uint64(t.Unix()))
+
+		return appendVarint(b, 2, uint64(t.Nanosecond()))
+	})
+}
+
+// fields iterates over the fields of an encoded message:
+//
+//	for d := (fields{b: data}); d.next(); {
+//		switch d.field { ... }
+//	}
+//	if d.err != nil { ... }
+//
+// Unknown fields are skipped, so that older servers accept newer clients.
+type fields struct {
+	b   []byte
+	err error
+
+	field    int
+	wireType int
+	varint   uint64
+	bytes    []byte
+}
+
+// next reads the following field; it returns false at the end of the
+// message or on malformed input, setting err.
+func (d *fields) next() bool {
+	if d.err != nil || len(d.b) == 0 {
+		return false
+	}
+
+	tag, n := binary.Uvarint(d.b)
+	if n <= 0 || tag>>3 == 0 {
+		return d.fail()
+	}
+
+	d.b = d.b[n:]
+	d.field, d.wireType = int(tag>>3), int(tag&7)
+
+	switch d.wireType {
+	case wireVarint:
+		if d.varint, n = binary.Uvarint(d.b); n <= 0 {
+			return d.fail()
+		}
+
+		d.b = d.b[n:]
+	case wireBytes:
+		size, n := binary.Uvarint(d.b)
+		if n <= 0 || size > uint64(len(d.b)-n) {
+			return d.fail()
+		}
+
+		d.bytes = d.b[n : n+int(size)]
+		d.b = d.b[n+int(size):]
+	case wireFixed64:
+		if len(d.b) < 8 {
+			return d.fail()
+		}
+
+		d.b = d.b[8:]
+	case wireFixed32:
+		if len(d.b) < 4 {
+			return d.fail()
+		}
+
+		d.b = d.b[4:]
+	default:
+		return d.fail()
+	}
+
+	return true
+}
+
+func (d *fields) fail() bool {
+	d.err = errInvalidMessage
+
+	return false
+}
+
+// is reports whether the current field has the given wire type; a field
+// with another type makes the message invalid.
+func (d *fields) is(wireType int) bool {
+	if d.wireType != wireType {
+		d.fail()
+
+		return false
+	}
+
+	return true
+}
+
+// decodeTimestamp decodes a google.protobuf.Timestamp.
+func decodeTimestamp(b []byte) (time.Time, error) {
+	var secs, nanos int64
+
+	d := fields{b: b}
+	for d.next() {
+		switch d.field {
+		case 1:
+			if d.is(wireVarint) {
+				secs = int64(d.varint)
+			}
+		case 2:
+			if d.is(wireVarint) {
+				nanos = int64(int32(d.varint))
+			}
+		}
+	}
+
+	if d.err != nil {
+		return time.Time{}, d.err
+	}
+
+	if nanos < 0 || nanos >= int64(time.Second) {
+		return time.Time{}, errInvalidMessage
+	}
+
+	return time.Unix(secs, nanos).UTC(), nil
+}
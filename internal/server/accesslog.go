@@ -0,0 +1,214 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"ecom-internship/internal/httputils"
+	"ecom-internship/internal/logger"
+)
+
+// clfTimeFormat is the timestamp layout of the Apache log formats.
+const clfTimeFormat = "02/Jan/2006:15:04:05 -0700"
+
+// redactedValue replaces the values of sensitive query parameters.
+const redactedValue = "[REDACTED]"
+
+// accessLog writes a record per request. Without an output the records go
+// through the application logger; otherwise they are written to out as JSON
+// lines or in the Apache common or combined format.
+type accessLog struct {
+	format string
+	// redact holds the normalised names of query parameters whose values
+	// are masked in the request line, such as the calendar feed token.
+	redact map[string]bool
+
+	mu  sync.Mutex
+	out io.Writer
+}
+
+// accessRecord is a request record; the fields match the application log attributes.
+type accessRecord struct {
+	Time         time.Time `json:"time"`
+	RequestID    string    `json:"request_id"`
+	Method       string    `json:"method"`
+	Path         string    `json:"path"`
+	Route        string    `json:"route"`
+	Proto        string    `json:"proto"`
+	Status       int       `json:"status"`
+	Bytes        int64     `json:"bytes"`
+	RequestBytes int64     `json:"request_bytes"`
+	RemoteAddr   string    `json:"remote_addr"`
+	ClientIP     string    `json:"client_ip,omitempty"`
+	UserAgent    string    `json:"user_agent"`
+	Referer      string    `json:"referer,omitempty"`
+	ClientCert   string    `json:"client_cert,omitempty"`
+	Duration     string    `json:"duration"`
+}
+
+// newAccessLog creates an access log masking the query parameters named in
+// redactKeys, matched like LOG_REDACT_KEYS.
+func newAccessLog(format string, out io.Writer, redactKeys []string) *accessLog {
+	redact := make(map[string]bool, len(redactKeys))
+	for _, key := range redactKeys {
+		redact[normalizeKey(key)] = true
+	}
+
+	return &accessLog{format: format, redact: redact, out: out}
+}
+
+// middleware logs the request once it is served, with the ID assigned by
+// requestIDMiddleware.
+func (a *accessLog) middleware(log logger.Logger, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body := &countingBody{ReadCloser: r.Body}
+		if r.Body != nil && r.Body != http.NoBody {
+			r.Body = body
+		}
+
+		rec := newResponseRecorder(w)
+		start := time.Now()
+		next.ServeHTTP(rec, r)
+
+		a.write(logger.FromContext(r.Context(), log), accessRecord{
+			Time:         start,
+			RequestID:    httputils.RequestID(r),
+			Method:       r.Method,
+			Path:         r.URL.Path,
+			Route:        routeLabel(r.Pattern),
+			Proto:        r.Proto,
+			Status:       rec.status,
+			Bytes:        rec.bytes,
+			RequestBytes: body.bytes,
+			RemoteAddr:   r.RemoteAddr,
+			ClientIP:     clientIP(r),
+			UserAgent:    r.UserAgent(),
+			Referer:      r.Referer(),
+			ClientCert:   httputils.ClientIdentity(r),
+			Duration:     time.Since(start).String(),
+		}, r)
+	})
+}
+
+func (a *accessLog) write(log logger.Logger, rec accessRecord, r *http.Request) {
+	if a.out == nil {
+		log.Info("request completed",
+			"request_id", rec.RequestID,
+			"method", rec.Method,
+			"path", rec.Path,
+			"route", rec.Route,
+			"status", rec.Status,
+			"bytes", rec.Bytes,
+			"request_bytes", rec.RequestBytes,
+			"remote_addr", rec.RemoteAddr,
+			"client_ip", rec.ClientIP,
+			"user_agent", rec.UserAgent,
+			"client_cert", rec.ClientCert,
+			"duration", rec.Duration,
+		)
+
+		return
+	}
+
+	var line []byte
+
+	switch a.format {
+	case "common", "combined":
+		line = a.appendCLF(nil, rec, r)
+	default:
+		var err error
+		if line, err = json.Marshal(rec); err != nil {
+			log.Error("failed to encode access log record", "error", err)
+
+			return
+		}
+
+		line = append(line, '\n')
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if _, err := a.out.Write(line); err != nil {
+		log.Error("failed to write access log", "error", err)
+	}
+}
+
+// clientIP returns the address of the peer of r or, on a Unix socket, of the
+// client reported by the proxy in X-Forwarded-For; it is empty when unknown.
+func clientIP(r *http.Request) string {
+	if ip := httputils.ClientIP(r, nil); ip.IsValid() {
+		return ip.String()
+	}
+
+	return ""
+}
+
+// appendCLF formats rec as
+//
+//	host ident user [time] "request" status bytes
+//
+// followed by "referer" "user-agent" in the combined format.
+// The user is the client certificate identity.
+func (a *accessLog) appendCLF(b []byte, rec accessRecord, r *http.Request) []byte {
+	b = fmt.Appendf(b, "%s - %s [%s] %q %d %s", dash(rec.ClientIP), dash(rec.ClientCert), rec.Time.Format(clfTimeFormat),
+		r.Method+" "+a.requestURI(r.URL)+" "+r.Proto, rec.Status, clfBytes(rec.Bytes))
+
+	if a.format == "combined" {
+		b = fmt.Appendf(b, " %q %q", dash(rec.Referer), dash(rec.UserAgent))
+	}
+
+	return append(b, '\n')
+}
+
+// requestURI returns the path and query of u with the values of sensitive
+// parameters masked, keeping the order and encoding of the others.
+func (a *accessLog) requestURI(u *url.URL) string {
+	if u.RawQuery == "" {
+		return u.RequestURI()
+	}
+
+	params := strings.Split(u.RawQuery, "&")
+
+	for i, param := range params {
+		raw, _, _ := strings.Cut(param, "=")
+
+		name := raw
+		if unescaped, err := url.QueryUnescape(raw); err == nil {
+			name = unescaped
+		}
+
+		if a.redact[normalizeKey(name)] {
+			params[i] = raw + "=" + redactedValue
+		}
+	}
+
+	return u.EscapedPath() + "?" + strings.Join(params, "&")
+}
+
+func normalizeKey(key string) string {
+	return strings.ReplaceAll(strings.ToLower(key), "-", "_")
+}
+
+func dash(s string) string {
+	if s == "" {
+		return "-"
+	}
+
+	return s
+}
+
+func clfBytes(n int64) string {
+	if n == 0 {
+		return "-"
+	}
+
+	return strconv.FormatInt(n, 10)
+}
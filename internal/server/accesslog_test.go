@@ -0,0 +1,138 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"ecom-internship/internal/logger/std"
+)
+
+func TestAccessLog_Formats(t *testing.T) {
+	log := std.New("error")
+
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body) //nolint:errcheck,gosec
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte("created")) //nolint:errcheck,gosec
+	}
+
+	serve := func(format string) string {
+		var out bytes.Buffer
+
+		mux := http.NewServeMux()
+		mux.Handle("POST /todos/{id}", chain(log, http.HandlerFunc(handler),
+			newAccessLog(format, &out, []string{"token"}).middleware, requestIDMiddleware))
+
+		req := httptest.NewRequest(http.MethodPost, "/todos/7?x=1", strings.NewReader(`{"caption":"a"}`))
+		req.Header.Set("User-Agent", "test-agent")
+		req.Header.Set("Referer", "https://app.example.com/")
+		mux.ServeHTTP(httptest.NewRecorder(), req)
+
+		return out.String()
+	}
+
+	var rec accessRecord
+	if err := json.Unmarshal([]byte(serve("json")), &rec); err != nil {
+		t.Fatalf("Failed to unmarshal access record: %v", err)
+	}
+
+	if rec.Status != http.StatusCreated || rec.Bytes != 7 || rec.RequestBytes != 15 ||
+		rec.Route != "/todos/{id}" || rec.Path != "/todos/7" || rec.RequestID == "" {
+		t.Errorf("Unexpected access record: %+v", rec)
+	}
+
+	clf := regexp.MustCompile(`^192\.0\.2\.1 - - \[\d{2}/\w{3}/\d{4}:\d{2}:\d{2}:\d{2} [+-]\d{4}\] ` +
+		`"POST /todos/7\?x=1 HTTP/1\.1" 201 7`)
+
+	common := serve("common")
+	if !clf.MatchString(common) || strings.Contains(common, "test-agent") {
+		t.Errorf("Unexpected common log line: %q", common)
+	}
+
+	combined := serve("combined")
+	if !clf.MatchString(combined) || !strings.HasSuffix(combined, ` "https://app.example.com/" "test-agent"`+"\n") {
+		t.Errorf("Unexpected combined log line: %q", combined)
+	}
+}
+
+func TestAccessLog_RedactsQuery(t *testing.T) {
+	log := std.New("error")
+
+	for _, format := range []string{"json", "common", "combined"} {
+		var out bytes.Buffer
+
+		h := chain(log, http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}),
+			newAccessLog(format, &out, []string{"authorization", "token"}).middleware, requestIDMiddleware)
+
+		req := httptest.NewRequest(http.MethodGet, "/todos.ics?x=1&Token=secret-feed-token", nil)
+		h.ServeHTTP(httptest.NewRecorder(), req)
+
+		if strings.Contains(out.String(), "secret-feed-token") {
+			t.Errorf("Expected the feed token to be masked in %s, got %q", format, out.String())
+		}
+
+		if format != "json" && !strings.Contains(out.String(), `"GET /todos.ics?x=1&Token=[REDACTED] HTTP/1.1"`) {
+			t.Errorf("Expected other parameters to be kept in %s, got %q", format, out.String())
+		}
+	}
+}
+
+func TestResponseRecorder_Interfaces(t *testing.T) {
+	rec := newResponseRecorder(httptest.NewRecorder())
+
+	var w http.ResponseWriter = rec
+	if _, ok := w.(http.Flusher); !ok {
+		t.Error("Expected the recorder to implement http.Flusher")
+	}
+
+	if _, ok := w.(http.Hijacker); !ok {
+		t.Fatal("Expected the recorder to implement http.Hijacker")
+	}
+
+	// httptest.ResponseRecorder cannot be hijacked, so the error must come from the underlying writer.
+	if _, _, err := rec.Hijack(); err == nil {
+		t.Error("Expected an error hijacking a writer without a connection")
+	}
+
+	srv := httptest.NewServer(chain(std.New("error"), http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		conn, buf, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			t.Errorf("Hijack failed: %v", err)
+
+			return
+		}
+		defer conn.Close()
+
+		buf.WriteString("HTTP/1.1 200 OK\r\nContent-Length: 2\r\nConnection: close\r\n\r\nok") //nolint:errcheck,gosec
+		buf.Flush()                                                                            //nolint:errcheck,gosec
+	}), newAccessLog("json", io.Discard, nil).middleware))
+	defer srv.Close()
+
+	conn, err := net.Dial("tcp", srv.Listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.Write([]byte("GET / HTTP/1.1\r\nHost: test\r\n\r\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := http.ReadResponse(bufio.NewReader(conn), nil)
+	if err != nil {
+		t.Fatalf("Failed to read hijacked response: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if body, _ := io.ReadAll(resp.Body); string(body) != "ok" {
+		t.Errorf("Expected body written over the hijacked connection, got %q", body)
+	}
+}
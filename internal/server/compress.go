@@ -0,0 +1,379 @@
+package server
+
+import (
+	"compress/flate"
+	"compress/gzip"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"ecom-internship/internal/config"
+	"ecom-internship/internal/logger"
+	"ecom-internship/internal/problem"
+)
+
+// Supported content codings.
+const (
+	encodingGzip     = "gzip"
+	encodingDeflate  = "deflate"
+	encodingIdentity = "identity"
+)
+
+// encoder is implemented by both gzip.Writer and flate.Writer.
+type encoder interface {
+	io.WriteCloser
+	Flush() error
+	Reset(w io.Writer)
+}
+
+// compressor negotiates response compression and decompresses request bodies.
+// Encoders are pooled because allocating their internal state dominates the cost
+// of compressing small responses.
+type compressor struct {
+	minSize int
+	gzip    sync.Pool
+	deflate sync.Pool
+}
+
+func newCompressor(cfg *config.CompressionConfig) *compressor {
+	c := &compressor{minSize: cfg.MinSize}
+
+	c.gzip.New = func() any {
+		//nolint:errcheck
+		w, _ := gzip.NewWriterLevel(io.Discard, cfg.Level)
+
+		return w
+	}
+	c.deflate.New = func() any {
+		//nolint:errcheck
+		w, _ := flate.NewWriter(io.Discard, cfg.Level)
+
+		return w
+	}
+
+	return c
+}
+
+func (c *compressor) pool(encoding string) *sync.Pool {
+	if encoding == encodingGzip {
+		return &c.gzip
+	}
+
+	return &c.deflate
+}
+
+func (c *compressor) middleware(log logger.Logger, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !c.decompressRequest(log, w, r) {
+			return
+		}
+
+		addVary(w.Header(), "Accept-Encoding")
+
+		encoding := negotiateEncoding(r.Header.Get("Accept-Encoding"))
+		if encoding == "" || r.Method == http.MethodHead {
+			next.ServeHTTP(w, r)
+
+			return
+		}
+
+		cw := &compressWriter{ResponseWriter: w, c: c, encoding: encoding}
+		defer func() {
+			if err := cw.Close(); err != nil {
+				logger.FromContext(r.Context(), log).Error("failed to finish compressed response", "error", err)
+			}
+		}()
+
+		next.ServeHTTP(cw, r)
+	})
+}
+
+// decompressRequest replaces a gzip or deflate encoded body with a decoding reader.
+// It writes an error response and returns false if the body cannot be decoded.
+func (c *compressor) decompressRequest(log logger.Logger, w http.ResponseWriter, r *http.Request) bool {
+	encoding := strings.ToLower(strings.TrimSpace(r.Header.Get("Content-Encoding")))
+	body := r.Body
+
+	switch encoding {
+	case "", encodingIdentity:
+		return true
+	case encodingGzip, "x-gzip":
+		zr, err := gzip.NewReader(r.Body)
+		if err != nil {
+			logger.FromContext(r.Context(), log).Debug("invalid gzip request body", "error", err)
+			problem.Write(w, r, http.StatusBadRequest, "Invalid gzip request body")
+
+			return false
+		}
+
+		r.Body = readCloser{Reader: zr, close: func() error {
+			//nolint:errcheck
+			zr.Close()
+
+			return body.Close()
+		}}
+	case encodingDeflate:
+		zr := flate.NewReader(r.Body)
+		r.Body = readCloser{Reader: zr, close: func() error {
+			//nolint:errcheck
+			zr.Close()
+
+			return body.Close()
+		}}
+	default:
+		w.Header().Set("Accept-Encoding", "gzip, deflate")
+		problem.Write(w, r, http.StatusUnsupportedMediaType, "Unsupported content encoding")
+
+		return false
+	}
+
+	r.Header.Del("Content-Encoding")
+	r.Header.Del("Content-Length")
+	r.ContentLength = -1
+
+	return true
+}
+
+type readCloser struct {
+	io.Reader
+	close func() error
+}
+
+func (rc readCloser) Close() error {
+	return rc.close()
+}
+
+// negotiateEncoding picks gzip or deflate from Accept-Encoding by q-value,
+// preferring gzip on ties. "*" applies only to codings not listed explicitly,
+// so it never selects a refused one. It returns "" if the response should not
+// be encoded.
+func negotiateEncoding(header string) string {
+	qs := make(map[string]float64)
+
+	for item := range strings.SplitSeq(header, ",") {
+		coding, params, _ := strings.Cut(strings.TrimSpace(item), ";")
+		coding = strings.ToLower(strings.TrimSpace(coding))
+
+		q := 1.0
+
+		if name, value, ok := strings.Cut(strings.TrimSpace(params), "="); ok && strings.TrimSpace(name) == "q" {
+			parsed, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+			if err != nil {
+				continue
+			}
+
+			q = parsed
+		}
+
+		qs[coding] = q
+	}
+
+	var (
+		best  string
+		bestQ float64
+	)
+
+	for _, coding := range []string{encodingGzip, encodingDeflate} {
+		q, ok := qs[coding]
+		if !ok {
+			q = qs["*"]
+		}
+
+		if q > bestQ {
+			best, bestQ = coding, q
+		}
+	}
+
+	return best
+}
+
+// compressWriter buffers the beginning of a response to decide whether to compress it:
+// small, already encoded, incompressible and streamed (flushed early) responses are sent as is.
+type compressWriter struct {
+	http.ResponseWriter
+	c        *compressor
+	encoding string
+
+	status      int
+	wroteHeader bool
+	decided     bool
+	buf         []byte
+	enc         encoder
+}
+
+func (cw *compressWriter) WriteHeader(status int) {
+	if cw.wroteHeader {
+		return
+	}
+
+	// Informational responses are forwarded immediately and do not end the header phase.
+	if status < http.StatusOK {
+		cw.ResponseWriter.WriteHeader(status)
+
+		return
+	}
+
+	cw.status = status
+	cw.wroteHeader = true
+
+	if !bodyAllowed(status) {
+		cw.decide(false)
+	}
+}
+
+func (cw *compressWriter) Write(p []byte) (int, error) {
+	if !cw.wroteHeader {
+		cw.WriteHeader(http.StatusOK)
+	}
+
+	if cw.decided {
+		if cw.enc != nil {
+			return cw.enc.Write(p)
+		}
+
+		return cw.ResponseWriter.Write(p)
+	}
+
+	cw.buf = append(cw.buf, p...)
+
+	if len(cw.buf) >= cw.c.minSize {
+		cw.decide(cw.compressible())
+
+		if err := cw.flushBuffer(); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(p), nil
+}
+
+// Flush sends buffered data; a response flushed before reaching the minimal size
+// is treated as a stream and left uncompressed.
+func (cw *compressWriter) Flush() {
+	if !cw.wroteHeader {
+		cw.WriteHeader(http.StatusOK)
+	}
+
+	if !cw.decided {
+		cw.decide(false)
+	}
+
+	//nolint:errcheck
+	cw.flushBuffer()
+
+	if cw.enc != nil {
+		//nolint:errcheck
+		cw.enc.Flush()
+	}
+
+	//nolint:errcheck
+	http.NewResponseController(cw.ResponseWriter).Flush()
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (cw *compressWriter) Unwrap() http.ResponseWriter {
+	return cw.ResponseWriter
+}
+
+// Close finishes the response and returns the encoder to the pool.
+func (cw *compressWriter) Close() error {
+	if !cw.decided {
+		if !cw.wroteHeader {
+			return nil
+		}
+
+		cw.decide(len(cw.buf) >= cw.c.minSize && cw.compressible())
+	}
+
+	if err := cw.flushBuffer(); err != nil {
+		return err
+	}
+
+	if cw.enc == nil {
+		return nil
+	}
+
+	err := cw.enc.Close()
+	cw.enc.Reset(io.Discard)
+	cw.c.pool(cw.encoding).Put(cw.enc)
+	cw.enc = nil
+
+	return err
+}
+
+func (cw *compressWriter) decide(compress bool) {
+	cw.decided = true
+
+	if compress {
+		h := cw.Header()
+		h.Set("Content-Encoding", cw.encoding)
+		h.Del("Content-Length")
+
+		cw.enc = cw.c.pool(cw.encoding).Get().(encoder) //nolint:forcetypeassert
+		cw.enc.Reset(cw.ResponseWriter)
+	}
+
+	cw.ResponseWriter.WriteHeader(cw.status)
+}
+
+func (cw *compressWriter) flushBuffer() error {
+	if len(cw.buf) == 0 {
+		return nil
+	}
+
+	buf := cw.buf
+	cw.buf = nil
+
+	var err error
+	if cw.enc != nil {
+		_, err = cw.enc.Write(buf)
+	} else {
+		_, err = cw.ResponseWriter.Write(buf)
+	}
+
+	return err
+}
+
+// compressible reports whether the response may benefit from compression.
+func (cw *compressWriter) compressible() bool {
+	h := cw.Header()
+	if h.Get("Content-Encoding") != "" || cw.status == http.StatusPartialContent {
+		return false
+	}
+
+	mediaType, _, err := mime.ParseMediaType(h.Get("Content-Type"))
+	if err != nil {
+		return true
+	}
+
+	return !incompressible(mediaType)
+}
+
+// incompressible lists media types that are already compressed or streamed.
+func incompressible(mediaType string) bool {
+	switch {
+	case mediaType == "image/svg+xml":
+		return false
+	case strings.HasPrefix(mediaType, "image/"),
+		strings.HasPrefix(mediaType, "video/"),
+		strings.HasPrefix(mediaType, "audio/"),
+		strings.HasPrefix(mediaType, "font/woff"):
+		return true
+	}
+
+	switch mediaType {
+	case "application/zip", "application/gzip", "application/x-gzip", "application/zstd",
+		"application/x-7z-compressed", "application/x-bzip2",
+		"text/event-stream":
+		return true
+	}
+
+	return false
+}
+
+func bodyAllowed(status int) bool {
+	return status != http.StatusNoContent && status != http.StatusNotModified
+}
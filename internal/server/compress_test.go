@@ -0,0 +1,176 @@
+package server
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"ecom-internship/internal/config"
+	"ecom-internship/internal/logger/std"
+)
+
+func TestNegotiateEncoding(t *testing.T) {
+	tests := map[string]string{
+		"":                           "",
+		"gzip":                       "gzip",
+		"deflate, gzip":              "gzip",
+		"gzip;q=0.5, deflate":        "deflate",
+		"gzip;q=0, deflate;q=0":      "",
+		"br":                         "",
+		"*":                          "gzip",
+		"gzip;q=0, *":                "deflate",
+		"*;q=0.5, deflate":           "deflate",
+		"gzip;q=0, deflate;q=0, *":   "",
+		"identity, deflate;q=0.1":    "deflate",
+		"GZIP ; q=0.8, deflate;q=.7": "gzip",
+		"gzip;q=bad":                 "",
+	}
+
+	for header, want := range tests {
+		if got := negotiateEncoding(header); got != want {
+			t.Errorf("negotiateEncoding(%q) = %q, want %q", header, got, want)
+		}
+	}
+}
+
+func TestCompressMiddleware(t *testing.T) {
+	c := newCompressor(&config.CompressionConfig{MinSize: 100, Level: gzip.DefaultCompression})
+	large := strings.Repeat(`{"caption":"todo"},`, 50)
+
+	tests := []struct {
+		name           string
+		acceptEncoding string
+		contentType    string
+		body           string
+		flush          bool
+		wantEncoding   string
+	}{
+		{"gzip", "gzip", "application/json", large, false, "gzip"},
+		{"deflate", "deflate", "application/json", large, false, "deflate"},
+		{"not accepted", "", "application/json", large, false, ""},
+		{"below min size", "gzip", "application/json", "{}", false, ""},
+		{"already compressed", "gzip", "image/png", large, false, ""},
+		{"streaming", "gzip", "text/event-stream", large, false, ""},
+		{"flushed early", "gzip", "application/x-ndjson", large, true, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := chain(std.New("error"), http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.Header().Set("Content-Type", tt.contentType)
+
+				if tt.flush {
+					io.WriteString(w, "{}\n")
+					http.NewResponseController(w).Flush()
+				}
+
+				io.WriteString(w, tt.body)
+			}), c.middleware)
+
+			req := httptest.NewRequest(http.MethodGet, "/todos", nil)
+			if tt.acceptEncoding != "" {
+				req.Header.Set("Accept-Encoding", tt.acceptEncoding)
+			}
+
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, req)
+
+			if got := w.Header().Get("Content-Encoding"); got != tt.wantEncoding {
+				t.Fatalf("Expected Content-Encoding %q, got %q", tt.wantEncoding, got)
+			}
+			if w.Header().Get("Vary") != "Accept-Encoding" {
+				t.Errorf("Expected Vary: Accept-Encoding, got %q", w.Header().Get("Vary"))
+			}
+
+			body := w.Body.Bytes()
+
+			switch tt.wantEncoding {
+			case "gzip":
+				zr, err := gzip.NewReader(bytes.NewReader(body))
+				if err != nil {
+					t.Fatalf("Invalid gzip body: %v", err)
+				}
+
+				body, _ = io.ReadAll(zr)
+			case "deflate":
+				body, _ = io.ReadAll(flate.NewReader(bytes.NewReader(body)))
+			}
+
+			want := tt.body
+			if tt.flush {
+				want = "{}\n" + want
+			}
+
+			if string(body) != want {
+				t.Errorf("Unexpected body: %q", body)
+			}
+		})
+	}
+}
+
+func TestCompressMiddleware_NoBody(t *testing.T) {
+	c := newCompressor(&config.CompressionConfig{MinSize: 0, Level: gzip.DefaultCompression})
+
+	h := chain(std.New("error"), http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}), c.middleware)
+
+	req := httptest.NewRequest(http.MethodDelete, "/todos/1", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNoContent || w.Header().Get("Content-Encoding") != "" || w.Body.Len() != 0 {
+		t.Errorf("Expected bare 204, got %d %v %q", w.Code, w.Header(), w.Body.String())
+	}
+}
+
+func TestDecompressRequest(t *testing.T) {
+	c := newCompressor(&config.CompressionConfig{MinSize: 1024, Level: gzip.DefaultCompression})
+
+	var got string
+
+	h := chain(std.New("error"), http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, err := io.ReadAll(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+
+			return
+		}
+
+		got = string(b)
+	}), c.middleware)
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	io.WriteString(zw, `[{"caption":"imported"}]`)
+	zw.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/import", &buf)
+	req.Header.Set("Content-Encoding", "gzip")
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK || got != `[{"caption":"imported"}]` {
+		t.Errorf("Expected decompressed body, got %d %q", w.Code, got)
+	}
+
+	for encoding, status := range map[string]int{"gzip": http.StatusBadRequest, "br": http.StatusUnsupportedMediaType} {
+		req = httptest.NewRequest(http.MethodPost, "/import", strings.NewReader("not compressed"))
+		req.Header.Set("Content-Encoding", encoding)
+
+		w = httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+
+		if w.Code != status {
+			t.Errorf("Expected %d for invalid %s body, got %d", status, encoding, w.Code)
+		}
+	}
+}
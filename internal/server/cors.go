@@ -0,0 +1,228 @@
+package server
+
+import (
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"ecom-internship/internal/config"
+	"ecom-internship/internal/logger"
+)
+
+// CORS request and response headers.
+const (
+	headerOrigin           = "Origin"
+	headerRequestMethod    = "Access-Control-Request-Method"
+	headerRequestHeaders   = "Access-Control-Request-Headers"
+	headerAllowOrigin      = "Access-Control-Allow-Origin"
+	headerAllowMethods     = "Access-Control-Allow-Methods"
+	headerAllowHeaders     = "Access-Control-Allow-Headers"
+	headerAllowCredentials = "Access-Control-Allow-Credentials"
+	headerExposeHeaders    = "Access-Control-Expose-Headers"
+	headerMaxAge           = "Access-Control-Max-Age"
+)
+
+// originPattern is an allowed origin, optionally with a wildcard subdomain like https://*.example.com.
+type originPattern struct {
+	scheme   string
+	host     string
+	wildcard bool
+}
+
+// cors applies the current cross-origin policy, which can be replaced at runtime.
+type cors struct {
+	policy atomic.Pointer[corsPolicy]
+}
+
+func newCORS(cfg *config.CORSConfig) *cors {
+	c := &cors{}
+	c.update(cfg)
+
+	return c
+}
+
+// update replaces the policy for subsequent requests.
+func (c *cors) update(cfg *config.CORSConfig) {
+	c.policy.Store(newCORSPolicy(cfg))
+}
+
+// corsPolicy is a parsed CORSConfig.
+type corsPolicy struct {
+	cfg      *config.CORSConfig
+	any      bool
+	patterns []originPattern
+}
+
+func newCORSPolicy(cfg *config.CORSConfig) *corsPolicy {
+	c := &corsPolicy{cfg: cfg}
+
+	for _, origin := range cfg.AllowedOrigins {
+		if origin == "*" {
+			c.any = true
+
+			continue
+		}
+
+		u, err := url.Parse(strings.ToLower(origin))
+		if err != nil || u.Host == "" {
+			continue
+		}
+
+		p := originPattern{scheme: u.Scheme, host: u.Host}
+		if rest, ok := strings.CutPrefix(u.Host, "*."); ok {
+			p.host, p.wildcard = "."+rest, true
+		}
+
+		c.patterns = append(c.patterns, p)
+	}
+
+	return c
+}
+
+// allowed reports whether requests from origin may read responses.
+func (c *corsPolicy) allowed(origin string) bool {
+	if origin == "" || origin == "null" {
+		return false
+	}
+
+	if c.any {
+		return true
+	}
+
+	u, err := url.Parse(strings.ToLower(origin))
+	if err != nil || u.Host == "" || u.Path != "" {
+		return false
+	}
+
+	for _, p := range c.patterns {
+		if p.scheme != u.Scheme {
+			continue
+		}
+
+		if p.wildcard && strings.HasSuffix(u.Host, p.host) && len(u.Host) > len(p.host) ||
+			!p.wildcard && u.Host == p.host {
+			return true
+		}
+	}
+
+	return false
+}
+
+// setAllowOrigin writes the headers shared by actual and preflight responses.
+func (c *corsPolicy) setAllowOrigin(h http.Header, origin string) {
+	if c.any && !c.cfg.AllowCredentials {
+		h.Set(headerAllowOrigin, "*")
+	} else {
+		h.Set(headerAllowOrigin, origin)
+	}
+
+	if c.cfg.AllowCredentials {
+		h.Set(headerAllowCredentials, "true")
+	}
+}
+
+// middleware adds CORS headers to actual (non-preflight) responses.
+func (c *cors) middleware(_ logger.Logger, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Responses differ per origin, so caches must key on it even when it is rejected.
+		addVary(w.Header(), headerOrigin)
+
+		if p, origin := c.policy.Load(), r.Header.Get(headerOrigin); p.allowed(origin) {
+			p.setAllowOrigin(w.Header(), origin)
+
+			if len(p.cfg.ExposedHeaders) > 0 {
+				w.Header().Set(headerExposeHeaders, strings.Join(p.cfg.ExposedHeaders, ", "))
+			}
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// preflight answers OPTIONS requests for a path served with the given methods.
+// Plain OPTIONS requests get the Allow header; CORS preflights additionally get
+// the allowed methods and headers when origin, method and headers are permitted.
+func (c *cors) preflight(methods []string) http.Handler {
+	allow := strings.Join(append(slices.Clone(methods), http.MethodOptions), ", ")
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h := w.Header()
+		h.Set("Allow", allow)
+
+		origin := r.Header.Get(headerOrigin)
+		method := r.Header.Get(headerRequestMethod)
+
+		if origin != "" && method != "" {
+			addVary(h, headerOrigin)
+			addVary(h, headerRequestMethod)
+			addVary(h, headerRequestHeaders)
+
+			p := c.policy.Load()
+
+			if p.allowed(origin) && p.methodAllowed(method, methods) && p.headersAllowed(r.Header.Get(headerRequestHeaders)) {
+				p.setAllowOrigin(h, origin)
+				h.Set(headerAllowMethods, strings.Join(p.allowedMethods(methods), ", "))
+
+				if len(p.cfg.AllowedHeaders) > 0 {
+					h.Set(headerAllowHeaders, strings.Join(p.cfg.AllowedHeaders, ", "))
+				}
+
+				if p.cfg.MaxAge > 0 {
+					h.Set(headerMaxAge, strconv.Itoa(int(p.cfg.MaxAge.Seconds())))
+				}
+			}
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+func (c *corsPolicy) methodAllowed(method string, routeMethods []string) bool {
+	return slices.Contains(routeMethods, method) && slices.Contains(c.cfg.AllowedMethods, method)
+}
+
+// allowedMethods returns the route methods permitted by the policy.
+func (c *corsPolicy) allowedMethods(routeMethods []string) []string {
+	var res []string
+
+	for _, m := range routeMethods {
+		if slices.Contains(c.cfg.AllowedMethods, m) {
+			res = append(res, m)
+		}
+	}
+
+	return res
+}
+
+func (c *corsPolicy) headersAllowed(requested string) bool {
+	for header := range strings.SplitSeq(requested, ",") {
+		header = strings.TrimSpace(header)
+		if header == "" {
+			continue
+		}
+
+		if !slices.ContainsFunc(c.cfg.AllowedHeaders, func(allowed string) bool {
+			return allowed == "*" && !c.cfg.AllowCredentials || strings.EqualFold(allowed, header)
+		}) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// addVary appends value to the Vary header unless it is already listed.
+func addVary(h http.Header, value string) {
+	for _, v := range h.Values("Vary") {
+		for existing := range strings.SplitSeq(v, ",") {
+			if strings.EqualFold(strings.TrimSpace(existing), value) {
+				return
+			}
+		}
+	}
+
+	h.Add("Vary", value)
+}
@@ -0,0 +1,126 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+
+	"ecom-internship/internal/config"
+	"ecom-internship/internal/database/mem"
+	"ecom-internship/internal/health"
+	"ecom-internship/internal/logger/std"
+	"ecom-internship/internal/metrics"
+	"ecom-internship/internal/tracing"
+)
+
+func newCORSRouter(t *testing.T, cors *config.CORSConfig) http.Handler {
+	t.Helper()
+
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	cfg.CORS = cors
+	log := std.New("error")
+
+	return NewRouter(cfg, log, mem.New(log), metrics.NewRegistry(), tracing.NewTracer(nil), health.New(health.DefaultTimeout), nil)
+}
+
+func TestCORS_Preflight(t *testing.T) {
+	router := newCORSRouter(t, &config.CORSConfig{
+		AllowedOrigins:   []string{"https://app.example.com", "https://*.example.org"},
+		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE"},
+		AllowedHeaders:   []string{"Content-Type", "X-Request-ID"},
+		AllowCredentials: true,
+		MaxAge:           10 * time.Minute,
+	})
+
+	tests := []struct {
+		name    string
+		path    string
+		origin  string
+		method  string
+		headers string
+		allowed bool
+	}{
+		{"exact origin", "/todos/1", "https://app.example.com", "PUT", "content-type", true},
+		{"wildcard subdomain", "/todos", "https://spa.example.org", "POST", "", true},
+		{"nested wildcard subdomain", "/todos", "https://a.b.example.org", "GET", "", true},
+		{"wildcard does not match apex", "/todos", "https://example.org", "GET", "", false},
+		{"scheme mismatch", "/todos", "http://app.example.com", "GET", "", false},
+		{"unknown origin", "/todos", "https://evil.com", "GET", "", false},
+		{"method not served by route", "/todos", "https://app.example.com", "DELETE", "", false},
+		{"header not allowed", "/todos", "https://app.example.com", "POST", "X-Custom", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodOptions, tt.path, nil)
+			req.Header.Set("Origin", tt.origin)
+			req.Header.Set("Access-Control-Request-Method", tt.method)
+			if tt.headers != "" {
+				req.Header.Set("Access-Control-Request-Headers", tt.headers)
+			}
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != http.StatusNoContent {
+				t.Fatalf("Expected status 204, got %d", w.Code)
+			}
+
+			got := w.Header().Get("Access-Control-Allow-Methods") != ""
+			if got != tt.allowed {
+				t.Fatalf("Preflight allowed = %v, want %v (headers %v)", got, tt.allowed, w.Header())
+			}
+
+			if tt.allowed {
+				if w.Header().Get("Access-Control-Allow-Origin") != tt.origin ||
+					w.Header().Get("Access-Control-Allow-Credentials") != "true" ||
+					w.Header().Get("Access-Control-Max-Age") != "600" {
+					t.Errorf("Unexpected preflight headers: %v", w.Header())
+				}
+			}
+
+			for _, vary := range []string{"Origin", "Access-Control-Request-Method", "Access-Control-Request-Headers"} {
+				if !slices.Contains(w.Header().Values("Vary"), vary) {
+					t.Errorf("Expected Vary on %s, got %v", vary, w.Header().Values("Vary"))
+				}
+			}
+		})
+	}
+}
+
+func TestCORS_ActualRequest(t *testing.T) {
+	router := newCORSRouter(t, &config.CORSConfig{
+		AllowedOrigins: []string{"*"},
+		AllowedMethods: []string{"GET"},
+		ExposedHeaders: []string{"X-Request-ID"},
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/todos", nil)
+	req.Header.Set("Origin", "https://anywhere.test")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Header().Get("Access-Control-Allow-Origin") != "*" {
+		t.Errorf("Expected wildcard origin, got %q", w.Header().Get("Access-Control-Allow-Origin"))
+	}
+	if w.Header().Get("Access-Control-Expose-Headers") != "X-Request-ID" {
+		t.Errorf("Expected exposed headers, got %q", w.Header().Get("Access-Control-Expose-Headers"))
+	}
+	if w.Header().Get("Vary") != "Origin" {
+		t.Errorf("Expected Vary: Origin, got %q", w.Header().Get("Vary"))
+	}
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodOptions, "/todos/1", nil))
+
+	if w.Code != http.StatusNoContent || w.Header().Get("Allow") != "GET, PUT, DELETE, OPTIONS" {
+		t.Errorf("Expected Allow header for plain OPTIONS, got %d %q", w.Code, w.Header().Get("Allow"))
+	}
+}
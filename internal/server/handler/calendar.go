@@ -0,0 +1,169 @@
+package handler
+
+import (
+	"crypto/subtle"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+
+	"ecom-internship/internal/database"
+	"ecom-internship/internal/httputils"
+	"ecom-internship/internal/ical"
+	"ecom-internship/internal/logger"
+	"ecom-internship/internal/problem"
+	"ecom-internship/internal/transfer"
+	"ecom-internship/internal/validation"
+)
+
+const (
+	calendarName     = "ToDo"
+	maxUploadMemory  = 1 << 20
+	calendarFormName = "file"
+)
+
+// CalendarFeed returns a handler serving ToDo items as an iCalendar feed.
+// Access requires a per-user secret token passed in the "token" query parameter.
+// The domain identifies this instance in the UIDs of the items.
+func CalendarFeed(log logger.Logger, db database.Database, tokens map[string]string, domain string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := logger.FromContext(r.Context(), log)
+
+		requestID := httputils.RequestID(r)
+
+		user, ok := feedUser(tokens, r.URL.Query().Get("token"))
+		if !ok {
+			log.Debug("invalid calendar feed token",
+				"request_id", requestID)
+			// Unknown tokens are reported as missing feeds to keep them secret.
+			problem.Write(w, r, http.StatusNotFound, "Feed not found")
+
+			return
+		}
+
+		w.Header().Set("Content-Type", ical.ContentType)
+		w.Header().Set("Content-Disposition", mime.FormatMediaType("inline",
+			map[string]string{"filename": "todos.ics"}))
+		w.Header().Set("Cache-Control", "private, no-store")
+		w.Header().Set("Trailer", streamStatusTrailer)
+
+		enc := ical.NewEncoder(w, calendarName, domain)
+
+		err := streamToDos(w, r, db, enc.Encode)
+		if errors.Is(err, errDraining) {
+			// An unterminated calendar is rejected by clients, which then
+			// keep their copy instead of dropping the missing items.
+			log.Info("calendar feed interrupted by shutdown",
+				"request_id", requestID,
+				"user", user)
+			w.Header().Set(streamStatusTrailer, streamInterrupted)
+
+			return
+		}
+
+		if err != nil {
+			// Headers are already sent, so the error can only be logged.
+			log.Error("failed to write calendar feed",
+				"request_id", requestID,
+				"user", user,
+				"error", err)
+
+			return
+		}
+
+		if err := enc.Close(); err != nil {
+			log.Error("failed to finish calendar feed",
+				"request_id", requestID,
+				"user", user,
+				"error", err)
+
+			return
+		}
+
+		w.Header().Set(streamStatusTrailer, streamComplete)
+	}
+}
+
+// feedUser returns the user owning the token, comparing tokens in constant time.
+func feedUser(tokens map[string]string, token string) (string, bool) {
+	if token == "" {
+		return "", false
+	}
+
+	var found string
+
+	for user, expected := range tokens {
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(token)) == 1 {
+			found = user
+		}
+	}
+
+	return found, found != ""
+}
+
+// ImportCalendar returns a handler storing VTODO items from an uploaded .ics file.
+// The file is read from the "file" field of a multipart form or from the raw body.
+// Items exported by the instance with the given domain keep their IDs; items
+// from other calendars are matched by UID, so importing them again updates them.
+func ImportCalendar(log logger.Logger, db database.Database, v *validation.Validator, domain string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := logger.FromContext(r.Context(), log)
+
+		c, ok := negotiate(w, r)
+		if !ok {
+			return
+		}
+
+		requestID := httputils.RequestID(r)
+
+		tx, ok := db.(database.Transactor)
+		if !ok {
+			log.Error("storage does not support transactions",
+				"request_id", requestID)
+			problem.Write(w, r, http.StatusNotImplemented, "Import is not supported by storage")
+
+			return
+		}
+
+		mode, err := transfer.ParseMode(queryOrDefault(r, "mode", string(transfer.ModeMerge)))
+		if err != nil {
+			log.Debug("invalid import mode",
+				"request_id", requestID,
+				"error", err)
+			problem.Write(w, r, http.StatusBadRequest, "Unsupported import mode")
+
+			return
+		}
+
+		body, err := calendarBody(r)
+		if err != nil {
+			log.Debug("failed to read uploaded calendar",
+				"request_id", requestID,
+				"error", err)
+			writeDecodeError(w, r, err)
+
+			return
+		}
+		defer body.Close()
+
+		writeImportResult(log, w, r, c, tx, ical.NewDecoder(body, domain), mode, v)
+	}
+}
+
+func calendarBody(r *http.Request) (io.ReadCloser, error) {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/form-data" {
+		return r.Body, nil //nolint:nilerr
+	}
+
+	if err := r.ParseMultipartForm(maxUploadMemory); err != nil {
+		return nil, err
+	}
+
+	file, _, err := r.FormFile(calendarFormName)
+	if err != nil {
+		return nil, err
+	}
+
+	return file, nil
+}
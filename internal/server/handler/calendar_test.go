@@ -0,0 +1,148 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"ecom-internship/internal/database/mem"
+	"ecom-internship/internal/httputils"
+	"ecom-internship/internal/ical"
+	"ecom-internship/internal/logger/std"
+	"ecom-internship/internal/model"
+)
+
+func TestCalendarFeed(t *testing.T) {
+	logger := std.New("debug")
+	db := &mockDB{
+		todos: map[int]model.ToDo{
+			1: {ID: 1, Caption: "Todo 1"},
+		},
+	}
+
+	handler := CalendarFeed(logger, db, map[string]string{"alice": "alice-secret-token"}, ical.DefaultDomain)
+
+	req := httptest.NewRequest(http.MethodGet, "/todos.ics?token=alice-secret-token", nil)
+	w := httptest.NewRecorder()
+	handler(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", w.Code)
+	}
+	if !strings.Contains(w.Body.String(), "SUMMARY:Todo 1\r\n") {
+		t.Errorf("Expected VTODO in feed, got %q", w.Body.String())
+	}
+	if status := w.Result().Trailer.Get(streamStatusTrailer); status != streamComplete {
+		t.Errorf("Expected complete stream status, got %q", status)
+	}
+
+	// A feed cut short by shutdown is left unterminated.
+	draining := make(chan struct{})
+	close(draining)
+
+	req = httptest.NewRequest(http.MethodGet, "/todos.ics?token=alice-secret-token", nil)
+	req = req.WithContext(httputils.WithDraining(req.Context(), draining))
+	w = httptest.NewRecorder()
+	handler(w, req)
+
+	if strings.Contains(w.Body.String(), "END:VCALENDAR") ||
+		w.Result().Trailer.Get(streamStatusTrailer) != streamInterrupted {
+		t.Errorf("Expected interrupted feed, got %q %v", w.Body.String(), w.Result().Trailer)
+	}
+
+	for _, target := range []string{"/todos.ics", "/todos.ics?token=wrong"} {
+		req = httptest.NewRequest(http.MethodGet, target, nil)
+		w = httptest.NewRecorder()
+		handler(w, req)
+
+		if w.Code != http.StatusNotFound {
+			t.Errorf("Expected status 404 for %s, got %d", target, w.Code)
+		}
+	}
+}
+
+func TestImportCalendar(t *testing.T) {
+	logger := std.New("debug")
+	db := mem.New(logger)
+
+	handler := ImportCalendar(logger, db, newValidator(), ical.DefaultDomain)
+
+	ics := "BEGIN:VCALENDAR\r\nBEGIN:VTODO\r\nUID:todo-7@ecom-internship\r\nSUMMARY:Imported\r\n" +
+		"END:VTODO\r\nEND:VCALENDAR\r\n"
+
+	var body bytes.Buffer
+
+	form := multipart.NewWriter(&body)
+	part, err := form.CreateFormFile("file", "todos.ics")
+	if err != nil {
+		t.Fatalf("CreateFormFile failed: %v", err)
+	}
+	if _, err := part.Write([]byte(ics)); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if err := form.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/import/ics", &body)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	w := httptest.NewRecorder()
+	handler(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", w.Code)
+	}
+	if _, err := db.GetToDoByID(context.Background(), 7); err != nil {
+		t.Errorf("Expected imported todo, got %v", err)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/import/ics", strings.NewReader("not a calendar"))
+	req.Header.Set("Content-Type", "text/calendar")
+	w = httptest.NewRecorder()
+	handler(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for invalid calendar, got %d", w.Code)
+	}
+}
+
+func TestImportCalendar_Idempotent(t *testing.T) {
+	logger := std.New("error")
+	db := mem.New(logger)
+
+	if _, err := db.CreateToDo(context.Background(), model.ToDo{Caption: "Local"}); err != nil {
+		t.Fatalf("CreateToDo failed: %v", err)
+	}
+
+	handler := ImportCalendar(logger, db, newValidator(), ical.DefaultDomain)
+
+	// The second item was exported by another instance with the same ID as the local item.
+	ics := "BEGIN:VCALENDAR\r\n" +
+		"BEGIN:VTODO\r\nUID:4f1c@example.com\r\nSUMMARY:External\r\nEND:VTODO\r\n" +
+		"BEGIN:VTODO\r\nUID:todo-1@other\r\nSUMMARY:Other instance\r\nEND:VTODO\r\n" +
+		"END:VCALENDAR\r\n"
+
+	for range 2 {
+		req := httptest.NewRequest(http.MethodPost, "/import/ics", strings.NewReader(ics))
+		req.Header.Set("Content-Type", "text/calendar")
+		w := httptest.NewRecorder()
+		handler(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+	}
+
+	todos, err := db.GetAllToDos(context.Background())
+	if err != nil {
+		t.Fatalf("GetAllToDos failed: %v", err)
+	}
+
+	if len(todos) != 3 || todos[0].Caption != "Local" {
+		t.Errorf("Expected the local item and two imported ones, got %+v", todos)
+	}
+}
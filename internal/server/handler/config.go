@@ -0,0 +1,51 @@
+package handler
+
+import (
+	"net/http"
+
+	"ecom-internship/internal/codec"
+	"ecom-internship/internal/config"
+	"ecom-internship/internal/logger"
+	"ecom-internship/internal/problem"
+)
+
+// ReloadFunc re-reads the configuration and applies the reloadable settings,
+// returning all changed settings.
+type ReloadFunc func() ([]config.Change, error)
+
+type reloadResponse struct {
+	Applied         []config.Change `json:"applied"`
+	RestartRequired []config.Change `json:"restart_required"`
+}
+
+// ReloadConfig returns a handler that reloads the configuration and reports
+// which changes were applied and which need a restart. An invalid
+// configuration is rejected as a whole and nothing is applied.
+func ReloadConfig(log logger.Logger, reload ReloadFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := logger.FromContext(r.Context(), log)
+
+		changes, err := reload()
+		if err != nil {
+			problem.Write(w, r, http.StatusUnprocessableEntity, err.Error())
+
+			return
+		}
+
+		resp := reloadResponse{Applied: []config.Change{}, RestartRequired: []config.Change{}}
+
+		for _, c := range changes {
+			if c.Reloadable {
+				resp.Applied = append(resp.Applied, c)
+			} else {
+				resp.RestartRequired = append(resp.RestartRequired, c)
+			}
+		}
+
+		w.Header().Set("Cache-Control", "no-store")
+
+		if err := writeResponse(w, codec.JSON, http.StatusOK, resp); err != nil {
+			log.Error("failed to encode reload result", "error", err)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"ecom-internship/internal/config"
+	"ecom-internship/internal/logger/std"
+)
+
+func TestReloadConfig(t *testing.T) {
+	logger := std.New("debug")
+
+	reload := func() ([]config.Change, error) {
+		return []config.Change{
+			{Key: "LOG_LEVEL", Old: "info", New: "debug", Reloadable: true},
+			{Key: "PORT", Old: "8080", New: "8081"},
+		}, nil
+	}
+
+	w := httptest.NewRecorder()
+	ReloadConfig(logger, reload).ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/config/reload", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+
+	var resp reloadResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+
+	if len(resp.Applied) != 1 || resp.Applied[0].Key != "LOG_LEVEL" ||
+		len(resp.RestartRequired) != 1 || resp.RestartRequired[0].Key != "PORT" {
+		t.Errorf("Unexpected response: %+v", resp)
+	}
+
+	failing := func() ([]config.Change, error) {
+		return nil, errors.New("invalid log level")
+	}
+
+	w = httptest.NewRecorder()
+	ReloadConfig(logger, failing).ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/config/reload", nil))
+
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("Expected status 422, got %d", w.Code)
+	}
+}
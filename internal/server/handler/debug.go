@@ -0,0 +1,67 @@
+package handler
+
+import (
+	"net/http"
+
+	"ecom-internship/internal/buildinfo"
+	"ecom-internship/internal/codec"
+	"ecom-internship/internal/config"
+	"ecom-internship/internal/database"
+	"ecom-internship/internal/logger"
+	"ecom-internship/internal/problem"
+)
+
+// BuildInfo returns a handler reporting the version of the running binary.
+func BuildInfo(log logger.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := logger.FromContext(r.Context(), log)
+
+		if err := writeResponse(w, codec.JSON, http.StatusOK, buildinfo.Get()); err != nil {
+			log.Error("failed to encode build info", "error", err)
+		}
+	}
+}
+
+// EffectiveConfig returns a handler writing the last loaded configuration
+// with secrets redacted, in the format of the configuration file.
+func EffectiveConfig(log logger.Logger, current func() *config.Config) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := logger.FromContext(r.Context(), log)
+
+		w.Header().Set("Content-Type", codec.JSON.ContentType())
+		w.Header().Set("Cache-Control", "no-store")
+
+		if err := current().Print(w); err != nil {
+			log.Error("failed to encode config", "error", err)
+		}
+	}
+}
+
+// StorageStats returns a handler reporting the item count, largest ID and
+// memory usage of the storage.
+func StorageStats(log logger.Logger, db database.Database) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := logger.FromContext(r.Context(), log)
+
+		s, ok := db.(database.StatsReporter)
+		if !ok {
+			problem.Write(w, r, http.StatusNotImplemented, "Storage does not report stats")
+
+			return
+		}
+
+		stats, err := s.Stats(r.Context())
+		if err != nil {
+			log.Error("failed to get storage stats", "error", err)
+			problem.Write(w, r, http.StatusInternalServerError, "Internal server error")
+
+			return
+		}
+
+		w.Header().Set("Cache-Control", "no-store")
+
+		if err := writeResponse(w, codec.JSON, http.StatusOK, stats); err != nil {
+			log.Error("failed to encode storage stats", "error", err)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package handler
+
+import (
+	"encoding/json"
+	"mime"
+	"net/http"
+
+	"ecom-internship/internal/graphql"
+	"ecom-internship/internal/httputils"
+	"ecom-internship/internal/logger"
+)
+
+// GraphQL returns a handler executing GraphQL requests sent as JSON in POST
+// bodies. Responses are JSON with status 200 whenever the request could be
+// read, errors of the query included; malformed requests get GraphQL errors
+// with a 4xx status.
+func GraphQL(log logger.Logger, schema *graphql.Schema) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := logger.FromContext(r.Context(), log)
+
+		requestID := httputils.RequestID(r)
+
+		req, status, msg := readGraphQLRequest(r)
+		if status != http.StatusOK {
+			log.Debug("invalid graphql request",
+				"request_id", requestID,
+				"error", msg)
+			writeGraphQL(w, status, map[string]any{
+				"errors": []map[string]any{{"message": msg, "extensions": map[string]any{"code": "BAD_REQUEST"}}},
+			})
+
+			return
+		}
+
+		resp := schema.Execute(r.Context(), req)
+
+		for _, e := range resp.Errors {
+			// Causes of internal errors are hidden from clients.
+			if cause := e.Unwrap(); cause != nil {
+				log.Error("graphql field failed",
+					"request_id", requestID,
+					"operation", req.OperationName,
+					"path", e.Path,
+					"error", cause)
+			}
+		}
+
+		writeGraphQL(w, http.StatusOK, resp)
+	}
+}
+
+// readGraphQLRequest decodes the request body; on failure it returns the
+// status and message to respond with.
+func readGraphQLRequest(r *http.Request) (graphql.Request, int, string) {
+	var req graphql.Request
+
+	if mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type")); err != nil || mediaType != "application/json" {
+		return req, http.StatusUnsupportedMediaType, "Content-Type must be application/json"
+	}
+
+	dec := json.NewDecoder(r.Body)
+	dec.UseNumber()
+
+	if err := dec.Decode(&req); err != nil {
+		if bodyTooLarge(err) {
+			return req, http.StatusRequestEntityTooLarge, "Request body is too large"
+		}
+
+		return req, http.StatusBadRequest, "Request body must be a JSON object with a query"
+	}
+
+	if req.Query == "" {
+		return req, http.StatusBadRequest, "Must provide query string"
+	}
+
+	return req, http.StatusOK, ""
+}
+
+func writeGraphQL(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	// The status is sent; a failed write means the client went away.
+	json.NewEncoder(w).Encode(v) //nolint:errcheck,errchkjson,gosec
+}
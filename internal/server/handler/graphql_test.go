@@ -0,0 +1,83 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"ecom-internship/internal/config"
+	"ecom-internship/internal/database/mem"
+	"ecom-internship/internal/graphql"
+	"ecom-internship/internal/logger/std"
+)
+
+func TestGraphQL(t *testing.T) {
+	logger := std.New("error")
+	schema := graphql.New(&config.GraphQLConfig{MaxDepth: 10, MaxComplexity: 1000}, mem.New(logger), newValidator())
+
+	handler := GraphQL(logger, schema)
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		status      int
+		contains    string
+	}{
+		{
+			name:        "mutation",
+			contentType: "application/json",
+			body:        `{"query":"mutation($c: String!) { createTodo(input: {caption: $c}) { id caption } }","variables":{"c":"Buy milk"}}`,
+			status:      http.StatusOK,
+			contains:    `{"data":{"createTodo":{"id":"1","caption":"Buy milk"}}}`,
+		},
+		{
+			name:        "query error",
+			contentType: "application/json; charset=utf-8",
+			body:        `{"query":"{ todo(id: 42) { id } }"}`,
+			status:      http.StatusOK,
+			contains:    `"code":"NOT_FOUND"`,
+		},
+		{
+			name:        "unsupported content type",
+			contentType: "text/plain",
+			body:        `{ todos { totalCount } }`,
+			status:      http.StatusUnsupportedMediaType,
+			contains:    `"code":"BAD_REQUEST"`,
+		},
+		{
+			name:        "malformed body",
+			contentType: "application/json",
+			body:        `{"query":`,
+			status:      http.StatusBadRequest,
+			contains:    `"code":"BAD_REQUEST"`,
+		},
+		{
+			name:        "missing query",
+			contentType: "application/json",
+			body:        `{"variables":{}}`,
+			status:      http.StatusBadRequest,
+			contains:    "Must provide query string",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", tt.contentType)
+			w := httptest.NewRecorder()
+			handler(w, req)
+
+			if w.Code != tt.status {
+				t.Errorf("Expected status %d, got %d", tt.status, w.Code)
+			}
+			if ct := w.Header().Get("Content-Type"); ct != "application/json" {
+				t.Errorf("Expected json content type, got %s", ct)
+			}
+			if !strings.Contains(w.Body.String(), tt.contains) {
+				t.Errorf("Expected body to contain %s, got %s", tt.contains, w.Body.String())
+			}
+		})
+	}
+}
@@ -2,42 +2,94 @@
 package handler
 
 import (
-	"encoding/json"
+	"context"
+	"encoding/xml"
 	"errors"
 	"net/http"
 	"strconv"
+	"time"
 
+	"ecom-internship/internal/codec"
 	"ecom-internship/internal/database"
 	"ecom-internship/internal/httputils"
 	"ecom-internship/internal/logger"
 	"ecom-internship/internal/model"
+	"ecom-internship/internal/problem"
+	"ecom-internship/internal/validation"
 )
 
-type apiError struct {
-	Code    int    `json:"code"`
-	Message string `json:"message"`
+// writeDecodeError reports a request body that could not be decoded.
+func writeDecodeError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, codec.ErrUnsupportedMediaType):
+		problem.Write(w, r, http.StatusUnsupportedMediaType, "Unsupported media type")
+	case bodyTooLarge(err):
+		problem.Write(w, r, http.StatusRequestEntityTooLarge, "Request body is too large")
+	default:
+		problem.Write(w, r, http.StatusBadRequest, "Invalid request body")
+	}
+}
+
+// bodyTooLarge reports whether err comes from reading a body beyond the
+// limit set by http.MaxBytesReader.
+func bodyTooLarge(err error) bool {
+	var mbe *http.MaxBytesError
+
+	return errors.As(err, &mbe)
+}
+
+// writeStorageError reports a failed storage operation. Operations cut short
+// by the handler deadline are reported as 503, so clients may retry.
+func writeStorageError(w http.ResponseWriter, r *http.Request, err error) {
+	if errors.Is(err, context.DeadlineExceeded) {
+		problem.Write(w, r, http.StatusServiceUnavailable, "Request timed out")
+
+		return
+	}
+
+	problem.Write(w, r, http.StatusInternalServerError, "Internal server error")
 }
 
-func writeError(w http.ResponseWriter, status int, message string) {
-	w.WriteHeader(status)
-	err := json.NewEncoder(w).Encode(apiError{
-		Code:    status,
-		Message: message,
-	})
+// writeValidationError reports all invalid fields of a ToDo payload.
+func writeValidationError(w http.ResponseWriter, r *http.Request, err error) {
+	var errs validation.Errors
+	if !errors.As(err, &errs) {
+		problem.Write(w, r, http.StatusBadRequest, "Invalid request body")
 
-	if err != nil {
-		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
 	}
+
+	fieldErrors := make([]problem.FieldError, 0, len(errs))
+	for _, fe := range errs {
+		fieldErrors = append(fieldErrors, problem.FieldError{Pointer: fieldPointer(fe.Field), Detail: fe.Message})
+	}
+
+	problem.WriteDetails(w, r, problem.Validation(r, http.StatusBadRequest, "Invalid todo", fieldErrors))
+}
+
+// fieldPointer returns a JSON Pointer to a top-level member.
+func fieldPointer(field string) string {
+	if field == "" {
+		return ""
+	}
+
+	return "#/" + field
 }
 
 type allToDosResponse struct {
-	ToDos []model.ToDo `json:"todos"`
+	XMLName xml.Name     `json:"-"     xml:"todos"`
+	ToDos   []model.ToDo `json:"todos" xml:"todo"`
 }
 
 // GetAllToDos returns a handler for retrieving all ToDo items.
 func GetAllToDos(log logger.Logger, db database.Database) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		log := logger.FromContext(r.Context(), log)
+
+		c, ok := negotiate(w, r)
+		if !ok {
+			return
+		}
 
 		requestID := httputils.RequestID(r)
 
@@ -46,7 +98,7 @@ func GetAllToDos(log logger.Logger, db database.Database) http.HandlerFunc {
 			log.Error("failed get all todos",
 				"request_id", requestID,
 				"error", err)
-			writeError(w, http.StatusInternalServerError, "Internal server error")
+			writeStorageError(w, r, err)
 
 			return
 		}
@@ -55,11 +107,10 @@ func GetAllToDos(log logger.Logger, db database.Database) http.HandlerFunc {
 			ToDos: toDos,
 		}
 
-		if err = json.NewEncoder(w).Encode(response); err != nil {
+		if err = writeResponse(w, c, http.StatusOK, response); err != nil {
 			log.Error("failed to encode response",
 				"request_id", requestID,
 				"error", err)
-			writeError(w, http.StatusInternalServerError, "Internal server error")
 		}
 	}
 }
@@ -67,7 +118,12 @@ func GetAllToDos(log logger.Logger, db database.Database) http.HandlerFunc {
 // GetToDoByID returns a handler for retrieving a ToDo item by ID.
 func GetToDoByID(log logger.Logger, db database.Database) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		log := logger.FromContext(r.Context(), log)
+
+		c, ok := negotiate(w, r)
+		if !ok {
+			return
+		}
 
 		requestID := httputils.RequestID(r)
 
@@ -78,7 +134,7 @@ func GetToDoByID(log logger.Logger, db database.Database) http.HandlerFunc {
 				"request_id", requestID,
 				"error", err,
 				"id", idFromPath)
-			writeError(w, http.StatusBadRequest, "Invalid id")
+			problem.Write(w, r, http.StatusBadRequest, "Invalid id")
 
 			return
 		}
@@ -89,49 +145,48 @@ func GetToDoByID(log logger.Logger, db database.Database) http.HandlerFunc {
 				log.Debug("invalid id",
 					"request_id", requestID,
 					"error", err)
-				writeError(w, http.StatusNotFound, "ToDo id not found")
+				problem.Write(w, r, http.StatusNotFound, "ToDo id not found")
 			} else {
 				log.Error("error get todo by id",
 					"request_id", requestID,
 					"error", err)
 
-				writeError(w, http.StatusInternalServerError, "Internal server error")
+				writeStorageError(w, r, err)
 			}
 
 			return
 		}
 
-		if err = json.NewEncoder(w).Encode(toDo); err != nil {
+		if err = writeResponse(w, c, http.StatusOK, toDo); err != nil {
 			log.Error("failed to encode response",
 				"request_id", requestID,
 				"error", err)
-
-			writeError(w, http.StatusInternalServerError, "Internal server error")
 		}
 	}
 }
 
 // CreateToDo returns a handler for creating a new ToDo item.
-func CreateToDo(log logger.Logger, db database.Database) http.HandlerFunc {
+func CreateToDo(log logger.Logger, db database.Database, v *validation.Validator) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		log := logger.FromContext(r.Context(), log)
 
 		requestID := httputils.RequestID(r)
 
 		var toDo model.ToDo
-		if err := json.NewDecoder(r.Body).Decode(&toDo); err != nil {
+		if err := decodeRequest(r, &toDo); err != nil {
 			log.Error("failed to decode request",
 				"request_id", requestID,
 				"error", err)
-			writeError(w, http.StatusBadRequest, "Invalid request body")
+			writeDecodeError(w, r, err)
 
 			return
 		}
 
-		if len(toDo.Caption) == 0 {
-			log.Debug("empty caption",
-				"request_id", requestID)
-			writeError(w, http.StatusBadRequest, "Empty caption provided")
+		if err := v.ToDo(toDo); err != nil {
+			log.Debug("invalid todo",
+				"request_id", requestID,
+				"error", err)
+			writeValidationError(w, r, err)
 
 			return
 		}
@@ -139,12 +194,12 @@ func CreateToDo(log logger.Logger, db database.Database) http.HandlerFunc {
 		id, err := db.CreateToDo(r.Context(), toDo)
 		if err != nil {
 			if errors.Is(err, database.ErrIDAlreadyExists) {
-				writeError(w, http.StatusConflict, "ToDo with this ID already exists")
+				problem.Write(w, r, http.StatusConflict, "ToDo with this ID already exists")
 			} else {
 				log.Error("error create todo",
 					"request_id", requestID,
 					"error", err)
-				writeError(w, http.StatusInternalServerError, "Internal server error")
+				writeStorageError(w, r, err)
 			}
 
 			return
@@ -157,15 +212,17 @@ func CreateToDo(log logger.Logger, db database.Database) http.HandlerFunc {
 }
 
 type updateToDoRequest struct {
-	Caption     string `json:"caption"`
-	Description string `json:"description"`
-	IsCompleted bool   `json:"is_completed"`
+	XMLName     xml.Name   `json:"-"                xml:"todo"`
+	Caption     string     `json:"caption"          xml:"caption"`
+	Description string     `json:"description"      xml:"description"`
+	IsCompleted bool       `json:"is_completed"     xml:"is_completed"`
+	DueAt       *time.Time `json:"due_at,omitempty" xml:"due_at,omitempty"`
 }
 
 // UpdateToDo returns a handler for updating an existing ToDo item.
-func UpdateToDo(log logger.Logger, db database.Database) http.HandlerFunc {
+func UpdateToDo(log logger.Logger, db database.Database, v *validation.Validator) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		log := logger.FromContext(r.Context(), log)
 
 		requestID := httputils.RequestID(r)
 
@@ -176,25 +233,17 @@ func UpdateToDo(log logger.Logger, db database.Database) http.HandlerFunc {
 				"request_id", requestID,
 				"error", err,
 				"id", idFromPath)
-			writeError(w, http.StatusBadRequest, "Invalid id")
+			problem.Write(w, r, http.StatusBadRequest, "Invalid id")
 
 			return
 		}
 
 		var update updateToDoRequest
-		if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+		if err := decodeRequest(r, &update); err != nil {
 			log.Error("failed to decode request",
 				"request_id", requestID,
 				"error", err)
-			writeError(w, http.StatusBadRequest, "Invalid request body")
-
-			return
-		}
-
-		if len(update.Caption) == 0 {
-			log.Debug("empty caption",
-				"request_id", requestID)
-			writeError(w, http.StatusBadRequest, "Empty caption provided")
+			writeDecodeError(w, r, err)
 
 			return
 		}
@@ -204,6 +253,16 @@ func UpdateToDo(log logger.Logger, db database.Database) http.HandlerFunc {
 			Caption:     update.Caption,
 			Description: update.Description,
 			IsCompleted: update.IsCompleted,
+			DueAt:       update.DueAt,
+		}
+
+		if err := v.ToDo(todo); err != nil {
+			log.Debug("invalid todo",
+				"request_id", requestID,
+				"error", err)
+			writeValidationError(w, r, err)
+
+			return
 		}
 
 		err = db.UpdateToDo(r.Context(), todo)
@@ -212,12 +271,12 @@ func UpdateToDo(log logger.Logger, db database.Database) http.HandlerFunc {
 				log.Debug("invalid id",
 					"request_id", requestID,
 					"error", err)
-				writeError(w, http.StatusNotFound, "ToDo id not found")
+				problem.Write(w, r, http.StatusNotFound, "ToDo id not found")
 			} else {
 				log.Error("failed to update todo",
 					"request_id", requestID,
 					"error", err)
-				writeError(w, http.StatusInternalServerError, "Internal server error")
+				writeStorageError(w, r, err)
 			}
 
 			return
@@ -230,7 +289,7 @@ func UpdateToDo(log logger.Logger, db database.Database) http.HandlerFunc {
 // DeleteToDo returns a handler for deleting a ToDo item by ID.
 func DeleteToDo(log logger.Logger, db database.Database) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		log := logger.FromContext(r.Context(), log)
 
 		requestID := httputils.RequestID(r)
 
@@ -242,7 +301,7 @@ func DeleteToDo(log logger.Logger, db database.Database) http.HandlerFunc {
 				"error", err,
 				"id", idFromPath)
 
-			writeError(w, http.StatusBadRequest, "Invalid id")
+			problem.Write(w, r, http.StatusBadRequest, "Invalid id")
 
 			return
 		}
@@ -252,12 +311,12 @@ func DeleteToDo(log logger.Logger, db database.Database) http.HandlerFunc {
 				log.Debug("invalid id",
 					"request_id", requestID,
 					"error", err)
-				writeError(w, http.StatusNotFound, "ToDo id not found")
+				problem.Write(w, r, http.StatusNotFound, "ToDo id not found")
 			} else {
 				log.Error("failed to update todo",
 					"request_id", requestID,
 					"error", err)
-				writeError(w, http.StatusInternalServerError, "Internal server error")
+				writeStorageError(w, r, err)
 			}
 
 			return
@@ -5,13 +5,19 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
+	"ecom-internship/internal/config"
 	"ecom-internship/internal/database"
+	"ecom-internship/internal/database/mem"
 	"ecom-internship/internal/logger/std"
 	"ecom-internship/internal/model"
+	"ecom-internship/internal/validation"
 )
 
 type mockDB struct {
@@ -22,6 +28,14 @@ type mockDB struct {
 
 var ErrDb = errors.New("database error")
 
+func newValidator() *validation.Validator {
+	return validation.New(&config.ValidationConfig{
+		MaxCaptionLength:   200,
+		MaxDescriptionSize: 4096,
+		MaxDueIn:           24 * time.Hour,
+	})
+}
+
 //nolint:revive
 func (m *mockDB) GetAllToDos(ctx context.Context) ([]model.ToDo, error) {
 	if m.shouldErr {
@@ -190,7 +204,7 @@ func TestCreateToDo(t *testing.T) {
 	logger := std.New("debug")
 	db := &mockDB{todos: make(map[int]model.ToDo)}
 
-	handler := CreateToDo(logger, db)
+	handler := CreateToDo(logger, db, newValidator())
 
 	todo := model.ToDo{
 		Caption:     "New Todo",
@@ -284,7 +298,7 @@ func TestUpdateToDo(t *testing.T) {
 		},
 	}
 
-	handler := UpdateToDo(logger, db)
+	handler := UpdateToDo(logger, db, newValidator())
 
 	update := updateToDoRequest{
 		Caption:     "Updated",
@@ -462,3 +476,91 @@ func TestResponseStructures(t *testing.T) {
 		t.Error("Response should have 'todos' field")
 	}
 }
+
+func TestContentNegotiation(t *testing.T) {
+	logger := std.New("debug")
+	db := &mockDB{
+		todos: map[int]model.ToDo{
+			1: {ID: 1, Caption: "Todo 1"},
+		},
+	}
+
+	handler := GetToDoByID(logger, db)
+
+	req := httptest.NewRequest(http.MethodGet, "/todos/1", nil)
+	req.SetPathValue("id", "1")
+	req.Header.Set("Accept", "application/json;q=0.5, application/xml")
+	w := httptest.NewRecorder()
+	handler(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", w.Code)
+	}
+	if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "application/xml") {
+		t.Errorf("Expected xml content type, got %s", ct)
+	}
+	if !strings.Contains(w.Body.String(), "<todo><id>1</id><caption>Todo 1</caption>") {
+		t.Errorf("Unexpected xml body: %s", w.Body.String())
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/todos/1", nil)
+	req.SetPathValue("id", "1")
+	req.Header.Set("Accept", "text/html")
+	w = httptest.NewRecorder()
+	handler(w, req)
+
+	if w.Code != http.StatusNotAcceptable {
+		t.Errorf("Expected status 406, got %d", w.Code)
+	}
+
+	create := CreateToDo(logger, db, newValidator())
+
+	req = httptest.NewRequest(http.MethodPost, "/todos", strings.NewReader("caption: From YAML\n"))
+	req.Header.Set("Content-Type", "application/yaml")
+	w = httptest.NewRecorder()
+	create(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Errorf("Expected status 201 for yaml body, got %d", w.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/todos", strings.NewReader("caption=x"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w = httptest.NewRecorder()
+	create(w, req)
+
+	if w.Code != http.StatusUnsupportedMediaType {
+		t.Errorf("Expected status 415, got %d", w.Code)
+	}
+}
+
+func TestHandlers_Limits(t *testing.T) {
+	logger := std.New("error")
+	db := mem.New(logger)
+
+	t.Run("body too large", func(t *testing.T) {
+		body := `{"caption":"` + strings.Repeat("a", 100) + `"}`
+		req := httptest.NewRequest(http.MethodPost, "/todos", io.NopCloser(strings.NewReader(body)))
+		req.Header.Set("Content-Type", "application/json")
+
+		w := httptest.NewRecorder()
+		req.Body = http.MaxBytesReader(w, req.Body, 32)
+		CreateToDo(logger, db, newValidator()).ServeHTTP(w, req)
+
+		if w.Code != http.StatusRequestEntityTooLarge {
+			t.Errorf("Expected status 413, got %d", w.Code)
+		}
+	})
+
+	t.Run("deadline exceeded", func(t *testing.T) {
+		ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+		defer cancel()
+
+		w := httptest.NewRecorder()
+		GetAllToDos(logger, db).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/todos", nil).WithContext(ctx))
+
+		if w.Code != http.StatusServiceUnavailable {
+			t.Errorf("Expected status 503, got %d", w.Code)
+		}
+	})
+}
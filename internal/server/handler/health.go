@@ -0,0 +1,57 @@
+package handler
+
+import (
+	"net/http"
+	"sync/atomic"
+
+	"ecom-internship/internal/codec"
+	"ecom-internship/internal/health"
+	"ecom-internship/internal/logger"
+)
+
+// Healthz returns a liveness handler that succeeds while the process can serve HTTP.
+func Healthz(log logger.Logger, hc *health.Checker) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := logger.FromContext(r.Context(), log)
+
+		writeHealthReport(log, w, hc.Live())
+	}
+}
+
+// Readyz returns a readiness handler reporting each dependency check
+// and failing once shutdown has begun. Probes are frequent, so only changes
+// between ready and not ready are logged.
+func Readyz(log logger.Logger, hc *health.Checker) http.HandlerFunc {
+	var ready atomic.Bool
+
+	ready.Store(true)
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := logger.FromContext(r.Context(), log)
+
+		report := hc.Ready(r.Context())
+
+		if ok := report.OK(); ready.Swap(ok) != ok {
+			if ok {
+				log.Info("readiness restored")
+			} else {
+				log.Warn("readiness check failed", "checks", report.Checks)
+			}
+		}
+
+		writeHealthReport(log, w, report)
+	}
+}
+
+func writeHealthReport(log logger.Logger, w http.ResponseWriter, report health.Report) {
+	status := http.StatusOK
+	if !report.OK() {
+		status = http.StatusServiceUnavailable
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+
+	if err := writeResponse(w, codec.JSON, status, report); err != nil {
+		log.Error("failed to encode health report", "error", err)
+	}
+}
@@ -0,0 +1,87 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"ecom-internship/internal/codec"
+	"ecom-internship/internal/health"
+	"ecom-internship/internal/logger"
+	"ecom-internship/internal/logger/std"
+)
+
+func TestHealthEndpoints(t *testing.T) {
+	logger := std.New("debug")
+	hc := health.New(health.DefaultTimeout)
+
+	storageErr := error(nil)
+	hc.Register("storage", func(context.Context) error { return storageErr })
+
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		setup   func()
+		status  int
+	}{
+		{"live", Healthz(logger, hc), func() {}, http.StatusOK},
+		{"ready", Readyz(logger, hc), func() {}, http.StatusOK},
+		{"storage down", Readyz(logger, hc), func() { storageErr = errors.New("down") }, http.StatusServiceUnavailable},
+		{"shutting down", Readyz(logger, hc), func() { storageErr = nil; hc.SetShuttingDown() }, http.StatusServiceUnavailable},
+		{"live while shutting down", Healthz(logger, hc), func() {}, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setup()
+
+			w := httptest.NewRecorder()
+			tt.handler(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+			if w.Code != tt.status {
+				t.Errorf("Expected status %d, got %d", tt.status, w.Code)
+			}
+			if w.Header().Get("Content-Type") != codec.JSON.ContentType() {
+				t.Errorf("Expected JSON, got %s", w.Header().Get("Content-Type"))
+			}
+
+			var report health.Report
+			if err := json.Unmarshal(w.Body.Bytes(), &report); err != nil {
+				t.Fatalf("Failed to unmarshal report: %v", err)
+			}
+			if report.OK() != (tt.status == http.StatusOK) {
+				t.Errorf("Unexpected report: %+v", report)
+			}
+		})
+	}
+}
+
+// warnCounter counts the warnings logged.
+type warnCounter struct {
+	logger.Logger
+	warns atomic.Int32
+}
+
+func (l *warnCounter) Warn(string, ...any) {
+	l.warns.Add(1)
+}
+
+func TestReadyz_LogsStateChanges(t *testing.T) {
+	log := &warnCounter{Logger: std.New("error")}
+	hc := health.New(health.DefaultTimeout)
+	hc.Register("storage", func(context.Context) error { return errors.New("down") })
+
+	h := Readyz(log, hc)
+
+	for range 5 {
+		h(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	}
+
+	if n := log.warns.Load(); n != 1 {
+		t.Errorf("Expected one warning for repeated failures, got %d", n)
+	}
+}
@@ -0,0 +1,95 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+
+	"ecom-internship/internal/codec"
+	"ecom-internship/internal/logger"
+	"ecom-internship/internal/problem"
+)
+
+var validLogLevels = map[string]bool{"debug": true, "info": true, "warn": true, "error": true}
+
+// LogLevels returns a handler reporting the default and per-component log levels.
+func LogLevels(log logger.Logger, lc logger.LevelController) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := logger.FromContext(r.Context(), log)
+
+		w.Header().Set("Cache-Control", "no-store")
+
+		if err := writeResponse(w, codec.JSON, http.StatusOK, lc.Levels()); err != nil {
+			log.Error("failed to encode log levels", "error", err)
+		}
+	}
+}
+
+// SetLogLevels returns a handler changing log levels without a restart.
+// The body is merged into the current levels: an omitted default keeps it
+// and an empty component level removes the override of that component.
+// The levels are reset to the configured ones on the next configuration reload.
+func SetLogLevels(log logger.Logger, lc logger.LevelController) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := logger.FromContext(r.Context(), log)
+
+		var req logger.Levels
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			problem.Write(w, r, http.StatusBadRequest, "Invalid JSON: "+err.Error())
+
+			return
+		}
+
+		if invalid := invalidLevels(req); len(invalid) > 0 {
+			problem.Write(w, r, http.StatusUnprocessableEntity,
+				"Invalid log levels: "+strings.Join(invalid, ", ")+"; expected debug, info, warn or error")
+
+			return
+		}
+
+		levels := lc.Levels()
+		if levels.Components == nil {
+			levels.Components = make(map[string]string)
+		}
+
+		if req.Default != "" {
+			levels.Default = req.Default
+		}
+
+		for component, level := range req.Components {
+			if level == "" {
+				delete(levels.Components, component)
+			} else {
+				levels.Components[component] = level
+			}
+		}
+
+		lc.SetLevels(levels)
+		log.Info("log levels changed", "default", levels.Default, "components", levels.Components)
+
+		w.Header().Set("Cache-Control", "no-store")
+
+		if err := writeResponse(w, codec.JSON, http.StatusOK, lc.Levels()); err != nil {
+			log.Error("failed to encode log levels", "error", err)
+		}
+	}
+}
+
+func invalidLevels(req logger.Levels) []string {
+	var invalid []string
+
+	if req.Default != "" && !validLogLevels[req.Default] {
+		invalid = append(invalid, fmt.Sprintf("default %q", req.Default))
+	}
+
+	for _, component := range slices.Sorted(maps.Keys(req.Components)) {
+		if level := req.Components[component]; level != "" && !validLogLevels[level] {
+			invalid = append(invalid, fmt.Sprintf("%s %q", component, level))
+		}
+	}
+
+	return invalid
+}
@@ -0,0 +1,53 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"ecom-internship/internal/logger"
+	"ecom-internship/internal/logger/std"
+)
+
+func TestSetLogLevels(t *testing.T) {
+	log := std.New("info")
+	log.SetLevels(logger.Levels{Default: "info", Components: map[string]string{"database": "warn", "server": "error"}})
+
+	set := func(body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		SetLogLevels(log, log).ServeHTTP(w, httptest.NewRequest(http.MethodPut, "/log/levels", strings.NewReader(body)))
+
+		return w
+	}
+
+	w := set(`{"components":{"database":"debug","server":""}}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var got logger.Levels
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+
+	if got.Default != "info" || len(got.Components) != 1 || got.Components["database"] != "debug" {
+		t.Errorf("Unexpected levels: %+v", got)
+	}
+
+	if w := set(`{"default":"verbose"}`); w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("Expected status 422 for an unknown level, got %d", w.Code)
+	}
+
+	if w := set(`{`); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for malformed JSON, got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	LogLevels(log, log).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/log/levels", nil))
+
+	if !strings.Contains(w.Body.String(), `"default":"info"`) {
+		t.Errorf("Unexpected levels: %s", w.Body.String())
+	}
+}
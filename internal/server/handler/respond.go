@@ -0,0 +1,40 @@
+package handler
+
+import (
+	"net/http"
+
+	"ecom-internship/internal/codec"
+	"ecom-internship/internal/problem"
+)
+
+// negotiate selects the response codec from the Accept header.
+// If none of the accepted media types is supported it writes 406 Not Acceptable.
+func negotiate(w http.ResponseWriter, r *http.Request) (codec.Codec, bool) {
+	c, err := codec.Negotiate(r.Header.Get("Accept"))
+	if err != nil {
+		problem.Write(w, r, http.StatusNotAcceptable, "None of the accepted media types is supported")
+
+		return nil, false
+	}
+
+	return c, true
+}
+
+// writeResponse writes the status and v encoded with c.
+func writeResponse(w http.ResponseWriter, c codec.Codec, status int, v any) error {
+	w.Header().Set("Content-Type", c.ContentType())
+	w.WriteHeader(status)
+
+	return c.Encode(w, v)
+}
+
+// decodeRequest decodes the request body with the codec matching its Content-Type.
+// It returns codec.ErrUnsupportedMediaType for unknown media types.
+func decodeRequest(r *http.Request, v any) error {
+	c, err := codec.ForContentType(r.Header.Get("Content-Type"))
+	if err != nil {
+		return err
+	}
+
+	return c.Decode(r.Body, v)
+}
@@ -0,0 +1,290 @@
+package handler
+
+import (
+	"errors"
+	"mime"
+	"net/http"
+
+	"ecom-internship/internal/codec"
+	"ecom-internship/internal/database"
+	"ecom-internship/internal/httputils"
+	"ecom-internship/internal/logger"
+	"ecom-internship/internal/model"
+	"ecom-internship/internal/problem"
+	"ecom-internship/internal/transfer"
+	"ecom-internship/internal/validation"
+)
+
+// streamStatusTrailer is the trailer telling whether a streamed response is
+// complete; its status code is sent before that is known.
+const streamStatusTrailer = "X-Stream-Status"
+
+// Values of the stream status trailer.
+const (
+	streamComplete    = "complete"
+	streamInterrupted = "interrupted"
+)
+
+// streamFlushItems is the number of items written between flushes of a
+// streamed response.
+const streamFlushItems = 64
+
+// errDraining stops streaming when the server starts shutting down.
+var errDraining = errors.New("server is shutting down")
+
+// Export returns a handler streaming all ToDo items in the requested format.
+// If the server starts shutting down, the export stops without closing the
+// document and the trailer reports it as interrupted.
+func Export(log logger.Logger, db database.Database) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := logger.FromContext(r.Context(), log)
+
+		requestID := httputils.RequestID(r)
+
+		format, err := transfer.ParseFormat(queryOrDefault(r, "format", string(transfer.FormatJSON)))
+		if err != nil {
+			log.Debug("invalid export format",
+				"request_id", requestID,
+				"error", err)
+			problem.Write(w, r, http.StatusBadRequest, "Unsupported format")
+
+			return
+		}
+
+		w.Header().Set("Content-Type", format.ContentType())
+		w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment",
+			map[string]string{"filename": "todos." + string(format)}))
+		w.Header().Set("Trailer", streamStatusTrailer)
+
+		enc, err := transfer.NewEncoder(format, w)
+		if err != nil {
+			log.Error("failed to create encoder",
+				"request_id", requestID,
+				"error", err)
+			problem.Write(w, r, http.StatusInternalServerError, "Internal server error")
+
+			return
+		}
+
+		err = streamToDos(w, r, db, enc.Encode)
+		if errors.Is(err, errDraining) {
+			log.Info("export interrupted by shutdown",
+				"request_id", requestID)
+			w.Header().Set(streamStatusTrailer, streamInterrupted)
+
+			return
+		}
+
+		if err != nil {
+			// Headers are already sent, so the error can only be logged.
+			log.Error("failed to export todos",
+				"request_id", requestID,
+				"error", err)
+
+			return
+		}
+
+		if err := enc.Close(); err != nil {
+			log.Error("failed to finish export",
+				"request_id", requestID,
+				"error", err)
+
+			return
+		}
+
+		w.Header().Set(streamStatusTrailer, streamComplete)
+	}
+}
+
+// streamToDos calls fn for every ToDo item; it returns errDraining once the
+// server starts shutting down. The response is flushed every streamFlushItems
+// items, so HTTP/2 flow control paces the stream by what the client has read
+// rather than by the size of the write buffers.
+func streamToDos(w http.ResponseWriter, r *http.Request, db database.Database, fn func(model.ToDo) error) error {
+	draining := httputils.Draining(r.Context())
+	rc := http.NewResponseController(w)
+	next := fn
+	n := 0
+
+	fn = func(todo model.ToDo) error {
+		select {
+		case <-draining:
+			return errDraining
+		default:
+		}
+
+		if err := next(todo); err != nil {
+			return err
+		}
+
+		if n++; n%streamFlushItems != 0 {
+			return nil
+		}
+
+		if err := rc.Flush(); err != nil && !errors.Is(err, http.ErrNotSupported) {
+			return err
+		}
+
+		return nil
+	}
+
+	if streamer, ok := db.(database.Streamer); ok {
+		return streamer.StreamToDos(r.Context(), fn)
+	}
+
+	toDos, err := db.GetAllToDos(r.Context())
+	if err != nil {
+		return err
+	}
+
+	for _, toDo := range toDos {
+		if err := fn(toDo); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Import returns a handler storing ToDo items from the request body
+// in a single transaction.
+func Import(log logger.Logger, db database.Database, v *validation.Validator) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := logger.FromContext(r.Context(), log)
+
+		c, ok := negotiate(w, r)
+		if !ok {
+			return
+		}
+
+		requestID := httputils.RequestID(r)
+
+		tx, ok := db.(database.Transactor)
+		if !ok {
+			log.Error("storage does not support transactions",
+				"request_id", requestID)
+			problem.Write(w, r, http.StatusNotImplemented, "Import is not supported by storage")
+
+			return
+		}
+
+		format, err := transfer.ParseFormat(queryOrDefault(r, "format", formatFromContentType(r)))
+		if err != nil {
+			log.Debug("invalid import format",
+				"request_id", requestID,
+				"error", err)
+			problem.Write(w, r, http.StatusBadRequest, "Unsupported format")
+
+			return
+		}
+
+		mode, err := transfer.ParseMode(queryOrDefault(r, "mode", string(transfer.ModeMerge)))
+		if err != nil {
+			log.Debug("invalid import mode",
+				"request_id", requestID,
+				"error", err)
+			problem.Write(w, r, http.StatusBadRequest, "Unsupported import mode")
+
+			return
+		}
+
+		dec, err := transfer.NewDecoder(format, r.Body)
+		if err != nil {
+			log.Error("failed to create decoder",
+				"request_id", requestID,
+				"error", err)
+			problem.Write(w, r, http.StatusInternalServerError, "Internal server error")
+
+			return
+		}
+
+		writeImportResult(log, w, r, c, tx, dec, mode, v)
+	}
+}
+
+// writeImportResult runs the import and writes its summary or an error.
+func writeImportResult(log logger.Logger,
+	w http.ResponseWriter,
+	r *http.Request,
+	c codec.Codec,
+	tx database.Transactor,
+	dec transfer.Decoder,
+	mode transfer.Mode,
+	v *validation.Validator,
+) {
+	requestID := httputils.RequestID(r)
+
+	result, err := transfer.Import(r.Context(), tx, dec, mode, v)
+	switch {
+	case err == nil:
+	case errors.Is(err, transfer.ErrInvalidRows):
+		log.Debug("import contains invalid rows",
+			"request_id", requestID,
+			"errors", len(result.Errors))
+
+		fieldErrors := make([]problem.FieldError, 0, len(result.Errors))
+		for _, rowErr := range result.Errors {
+			fieldErrors = append(fieldErrors, problem.FieldError{
+				Pointer: fieldPointer(rowErr.Field),
+				Row:     rowErr.Row,
+				Detail:  rowErr.Message,
+			})
+		}
+
+		problem.WriteDetails(w, r, problem.Validation(r, http.StatusUnprocessableEntity,
+			"Import contains invalid rows, nothing was stored", fieldErrors))
+
+		return
+	case bodyTooLarge(err):
+		log.Debug("import body too large",
+			"request_id", requestID,
+			"error", err)
+		problem.Write(w, r, http.StatusRequestEntityTooLarge, "Request body is too large")
+
+		return
+	case errors.Is(err, transfer.ErrMalformedInput):
+		log.Debug("failed to decode import",
+			"request_id", requestID,
+			"error", err)
+		problem.Write(w, r, http.StatusBadRequest, "Invalid request body")
+
+		return
+	default:
+		log.Error("failed to import todos",
+			"request_id", requestID,
+			"error", err)
+		writeStorageError(w, r, err)
+
+		return
+	}
+
+	if err := writeResponse(w, c, http.StatusOK, result); err != nil {
+		log.Error("failed to encode response",
+			"request_id", requestID,
+			"error", err)
+	}
+}
+
+func queryOrDefault(r *http.Request, key, defaultValue string) string {
+	if value := r.URL.Query().Get(key); value != "" {
+		return value
+	}
+
+	return defaultValue
+}
+
+func formatFromContentType(r *http.Request) string {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil {
+		return string(transfer.FormatJSON)
+	}
+
+	switch mediaType {
+	case "text/csv":
+		return string(transfer.FormatCSV)
+	case "application/x-ndjson", "application/ndjson":
+		return string(transfer.FormatNDJSON)
+	default:
+		return string(transfer.FormatJSON)
+	}
+}
@@ -0,0 +1,131 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"ecom-internship/internal/database/mem"
+	"ecom-internship/internal/httputils"
+	"ecom-internship/internal/logger/std"
+	"ecom-internship/internal/model"
+)
+
+func TestExport(t *testing.T) {
+	logger := std.New("debug")
+	db := &mockDB{
+		todos: map[int]model.ToDo{
+			1: {ID: 1, Caption: "Todo 1"},
+		},
+	}
+
+	handler := Export(logger, db)
+
+	req := httptest.NewRequest(http.MethodGet, "/export?format=csv", nil)
+	w := httptest.NewRecorder()
+	handler(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", w.Code)
+	}
+	if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/csv") {
+		t.Errorf("Expected csv content type, got %s", ct)
+	}
+	if !strings.Contains(w.Body.String(), "1,Todo 1,") {
+		t.Errorf("Expected exported row, got %q", w.Body.String())
+	}
+	if status := w.Result().Trailer.Get(streamStatusTrailer); status != streamComplete {
+		t.Errorf("Expected complete stream status, got %q", status)
+	}
+
+	draining := make(chan struct{})
+	close(draining)
+
+	req = httptest.NewRequest(http.MethodGet, "/export", nil)
+	req = req.WithContext(httputils.WithDraining(req.Context(), draining))
+	w = httptest.NewRecorder()
+	handler(w, req)
+
+	// The JSON array is not closed, so clients cannot mistake it for a full export.
+	if strings.HasSuffix(w.Body.String(), "]") || w.Result().Trailer.Get(streamStatusTrailer) != streamInterrupted {
+		t.Errorf("Expected interrupted export, got %q %v", w.Body.String(), w.Result().Trailer)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/export?format=xml", nil)
+	w = httptest.NewRecorder()
+	handler(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for unknown format, got %d", w.Code)
+	}
+}
+
+func TestExport_Flushes(t *testing.T) {
+	db := &mockDB{todos: map[int]model.ToDo{}}
+	for id := 1; id <= streamFlushItems; id++ {
+		db.todos[id] = model.ToDo{ID: id, Caption: "Todo"}
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/export", nil)
+	w := httptest.NewRecorder()
+	Export(std.New("error"), db)(w, req)
+
+	if !w.Flushed {
+		t.Error("Expected a long export to be flushed while streaming")
+	}
+}
+
+func TestImport(t *testing.T) {
+	logger := std.New("debug")
+	db := mem.New(logger)
+
+	handler := Import(logger, db, newValidator())
+
+	body := "{\"id\":3,\"caption\":\"Imported\"}\n"
+	req := httptest.NewRequest(http.MethodPost, "/import?mode=replace", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	w := httptest.NewRecorder()
+	handler(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", w.Code)
+	}
+	if _, err := db.GetToDoByID(context.Background(), 3); err != nil {
+		t.Errorf("Expected imported todo, got %v", err)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/import", strings.NewReader(`[{"caption":""}]`))
+	w = httptest.NewRecorder()
+	handler(w, req)
+
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("Expected status 422 for invalid rows, got %d", w.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/import", strings.NewReader(`{invalid`))
+	w = httptest.NewRecorder()
+	handler(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for malformed body, got %d", w.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/import?mode=upsert", strings.NewReader(`[]`))
+	w = httptest.NewRecorder()
+	handler(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for unknown mode, got %d", w.Code)
+	}
+
+	handler = Import(logger, &mockDB{todos: make(map[int]model.ToDo)}, newValidator())
+	req = httptest.NewRequest(http.MethodPost, "/import", strings.NewReader(`[]`))
+	w = httptest.NewRecorder()
+	handler(w, req)
+
+	if w.Code != http.StatusNotImplemented {
+		t.Errorf("Expected status 501 for storage without transactions, got %d", w.Code)
+	}
+}
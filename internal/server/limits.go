@@ -0,0 +1,72 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+
+	"ecom-internship/internal/config"
+	"ecom-internship/internal/logger"
+	"ecom-internship/internal/problem"
+)
+
+// routeLimits bounds the handler time and request body size of every
+// request by its route pattern, falling back to the server-wide values.
+type routeLimits struct {
+	cfg atomic.Pointer[config.ServerConfig]
+}
+
+func newRouteLimits(cfg *config.ServerConfig) *routeLimits {
+	l := &routeLimits{}
+	l.update(cfg)
+
+	return l
+}
+
+// update changes the limits of subsequent requests.
+func (l *routeLimits) update(cfg *config.ServerConfig) {
+	l.cfg.Store(cfg)
+}
+
+// middleware sets the handler deadline on the request context and limits the
+// body with http.MaxBytesReader. Requests declaring a larger Content-Length
+// are rejected before the handler runs; handlers report bodies exceeding the
+// limit while being read as 413 too.
+func (l *routeLimits) middleware(log logger.Logger, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cfg := l.cfg.Load()
+
+		maxBody := cfg.MaxBodySize
+		if n, ok := cfg.RouteMaxBodySizes[r.Pattern]; ok {
+			maxBody = n
+		}
+
+		if maxBody > 0 && r.Body != nil && r.Body != http.NoBody {
+			if r.ContentLength > maxBody {
+				logger.FromContext(r.Context(), log).Debug("request body too large",
+					"route", r.Pattern,
+					"content_length", r.ContentLength,
+					"limit", maxBody)
+				problem.Write(w, r, http.StatusRequestEntityTooLarge, "Request body is too large")
+
+				return
+			}
+
+			r.Body = http.MaxBytesReader(w, r.Body, maxBody)
+		}
+
+		timeout := cfg.HandlerTimeout
+		if d, ok := cfg.RouteTimeouts[r.Pattern]; ok {
+			timeout = d
+		}
+
+		if timeout > 0 {
+			ctx, cancel := context.WithTimeout(r.Context(), timeout)
+			defer cancel()
+
+			r = r.WithContext(ctx)
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,97 @@
+package server
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"ecom-internship/internal/config"
+	"ecom-internship/internal/logger/std"
+)
+
+func TestRouteLimits(t *testing.T) {
+	limits := newRouteLimits(&config.ServerConfig{
+		HandlerTimeout:    time.Second,
+		RouteTimeouts:     map[string]time.Duration{"POST /import": time.Minute, "GET /events": 0},
+		MaxBodySize:       8,
+		RouteMaxBodySizes: map[string]int64{"POST /import": 64},
+	})
+
+	var (
+		deadline    time.Time
+		hasDeadline bool
+	)
+
+	mux := http.NewServeMux()
+	h := chain(std.New("error"), http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		deadline, hasDeadline = r.Context().Deadline()
+
+		if _, err := io.ReadAll(r.Body); err != nil {
+			w.WriteHeader(http.StatusRequestEntityTooLarge)
+		}
+	}), limits.middleware)
+
+	for _, pattern := range []string{"POST /todos", "POST /import", "GET /events"} {
+		mux.Handle(pattern, h)
+	}
+
+	serve := func(method, path, body string, chunked bool) int {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		if chunked {
+			req.ContentLength = -1
+		}
+
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+
+		return w.Code
+	}
+
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		body     string
+		chunked  bool
+		status   int
+		deadline time.Duration
+	}{
+		{"within default limit", http.MethodPost, "/todos", "small", false, http.StatusOK, time.Second},
+		{"declared too large", http.MethodPost, "/todos", "too large body", false, http.StatusRequestEntityTooLarge, 0},
+		{"read too large", http.MethodPost, "/todos", "too large body", true, http.StatusRequestEntityTooLarge, time.Second},
+		{"route overrides", http.MethodPost, "/import", "too large body", false, http.StatusOK, time.Minute},
+		{"route without deadline", http.MethodGet, "/events", "", false, http.StatusOK, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hasDeadline = false
+			start := time.Now()
+
+			if status := serve(tt.method, tt.path, tt.body, tt.chunked); status != tt.status {
+				t.Errorf("Expected status %d, got %d", tt.status, status)
+			}
+
+			if tt.deadline == 0 {
+				if hasDeadline {
+					t.Errorf("Expected no handler deadline, got %v", deadline)
+				}
+
+				return
+			}
+
+			if !hasDeadline || deadline.Before(start.Add(tt.deadline)) || deadline.After(time.Now().Add(tt.deadline)) {
+				t.Errorf("Expected deadline in %v, got %v (set %v)", tt.deadline, deadline.Sub(start), hasDeadline)
+			}
+		})
+	}
+
+	limits.update(&config.ServerConfig{})
+
+	if status := serve(http.MethodPost, "/todos", "too large body", false); status != http.StatusOK || hasDeadline {
+		t.Errorf("Expected limits to be disabled after update, got %d (deadline %v)", status, hasDeadline)
+	}
+}
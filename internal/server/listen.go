@@ -0,0 +1,151 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"os"
+	"strconv"
+)
+
+// listenFDsStart is the first file descriptor passed by systemd socket activation.
+const listenFDsStart = 3
+
+var (
+	errNoListeners        = errors.New("no listeners configured")
+	errNoActivatedSockets = errors.New("socket activation enabled but no sockets were passed")
+)
+
+// listeners holds the sockets a server accepts connections on besides its TCP address.
+type listeners struct {
+	unixSocket string
+	unixMode   fs.FileMode
+	activation bool
+}
+
+// listen opens the TCP listener on addr unless it is empty, the Unix socket
+// and the activated sockets. On failure the already opened ones are closed.
+func (l listeners) listen(addr string) ([]net.Listener, error) {
+	var res []net.Listener
+
+	fail := func(err error) ([]net.Listener, error) {
+		for _, ln := range res {
+			ln.Close() //nolint:errcheck,gosec
+		}
+
+		return nil, err
+	}
+
+	if addr != "" {
+		ln, err := net.Listen("tcp", addr)
+		if err != nil {
+			return fail(err)
+		}
+
+		res = append(res, ln)
+	}
+
+	if l.unixSocket != "" {
+		ln, err := listenUnix(l.unixSocket, l.unixMode)
+		if err != nil {
+			return fail(err)
+		}
+
+		res = append(res, ln)
+	}
+
+	if l.activation {
+		n, err := listenFDs(os.Getenv("LISTEN_PID"), os.Getenv("LISTEN_FDS"))
+		if err != nil {
+			return fail(err)
+		}
+
+		// Child processes must not inherit the activation.
+		os.Unsetenv("LISTEN_PID")     //nolint:errcheck,gosec
+		os.Unsetenv("LISTEN_FDS")     //nolint:errcheck,gosec
+		os.Unsetenv("LISTEN_FDNAMES") //nolint:errcheck,gosec
+
+		activated, err := fileListeners(listenFDsStart, n)
+		if err != nil {
+			return fail(err)
+		}
+
+		res = append(res, activated...)
+	}
+
+	if len(res) == 0 {
+		return nil, errNoListeners
+	}
+
+	return res, nil
+}
+
+// listenUnix listens on a Unix socket at path with the given permissions. A
+// socket left by a previous process is removed; any other file is kept and
+// reported. The socket file is removed when the listener is closed.
+func listenUnix(path string, mode fs.FileMode) (net.Listener, error) {
+	if fi, err := os.Lstat(path); err == nil {
+		if fi.Mode().Type() != fs.ModeSocket {
+			return nil, fmt.Errorf("unix socket %s: file exists and is not a socket", path)
+		}
+
+		if err := os.Remove(path); err != nil {
+			return nil, fmt.Errorf("unix socket %s: remove stale socket: %w", path, err)
+		}
+	}
+
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.Chmod(path, mode); err != nil {
+		ln.Close() //nolint:errcheck,gosec
+
+		return nil, fmt.Errorf("unix socket %s: %w", path, err)
+	}
+
+	return ln, nil
+}
+
+// listenFDs returns the number of sockets passed by systemd, checking that
+// they were passed to this process rather than inherited from a parent.
+func listenFDs(pid, fds string) (int, error) {
+	if pid != strconv.Itoa(os.Getpid()) {
+		return 0, errNoActivatedSockets
+	}
+
+	n, err := strconv.Atoi(fds)
+	if err != nil || n < 1 {
+		return 0, errNoActivatedSockets
+	}
+
+	return n, nil
+}
+
+// fileListeners creates listeners from n consecutive file descriptors
+// starting at start. The descriptors themselves are closed, as the
+// listeners hold duplicates of them.
+func fileListeners(start, n int) ([]net.Listener, error) {
+	res := make([]net.Listener, 0, n)
+
+	for fd := start; fd < start+n; fd++ {
+		f := os.NewFile(uintptr(fd), "LISTEN_FD_"+strconv.Itoa(fd))
+
+		ln, err := net.FileListener(f)
+		f.Close() //nolint:errcheck,gosec
+
+		if err != nil {
+			for _, ln := range res {
+				ln.Close() //nolint:errcheck,gosec
+			}
+
+			return nil, fmt.Errorf("activated socket %d: %w", fd, err)
+		}
+
+		res = append(res, ln)
+	}
+
+	return res, nil
+}
@@ -0,0 +1,260 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"ecom-internship/internal/config"
+	"ecom-internship/internal/logger/std"
+)
+
+func TestServer_UnixSocket(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "api.sock")
+
+	// A socket left by a crashed process must not prevent the start.
+	stale, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatalf("Listen failed: %v", err)
+	}
+
+	stale.(*net.UnixListener).SetUnlinkOnClose(false)
+	stale.Close() //nolint:errcheck,gosec
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /ping", func(w http.ResponseWriter, _ *http.Request) {
+		io.WriteString(w, "pong") //nolint:errcheck,gosec
+	})
+
+	srv, err := New(&config.ServerConfig{UnixSocket: path, UnixSocketMode: 0o600}, mux, std.New("error"))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	started := make(chan error, 1)
+
+	go func() { started <- srv.Start() }()
+
+	client := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "unix", path)
+		},
+	}}
+
+	var resp *http.Response
+
+	for deadline := time.Now().Add(5 * time.Second); ; {
+		resp, err = client.Get("http://unix/ping")
+		if err == nil || time.Now().After(deadline) {
+			break
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if err != nil {
+		t.Fatalf("request over unix socket failed: %v", err)
+	}
+
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close() //nolint:errcheck,gosec
+
+	if string(body) != "pong" {
+		t.Errorf("body = %q, want pong", body)
+	}
+
+	fi, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Stat failed: %v", err)
+	}
+
+	if perm := fi.Mode().Perm(); perm != 0o600 {
+		t.Errorf("socket mode = %o, want 600", perm)
+	}
+
+	if err := srv.Stop(context.Background()); err != nil {
+		t.Fatalf("Stop failed: %v", err)
+	}
+
+	if err := <-started; !errors.Is(err, http.ErrServerClosed) {
+		t.Errorf("Start returned %v, want ErrServerClosed", err)
+	}
+
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("socket file not removed after Stop: %v", err)
+	}
+}
+
+func TestServer_UnixSocketClients(t *testing.T) {
+	ln, err := net.Listen("unix", filepath.Join(t.TempDir(), "api.sock"))
+	if err != nil {
+		t.Fatalf("Listen failed: %v", err)
+	}
+
+	// No TRUSTED_PROXIES: a proxy on the Unix socket is trusted anyway.
+	rl := newRateLimiter(&config.RateLimitConfig{
+		Key:        "ip",
+		WriteRate:  1,
+		WriteBurst: 1,
+		IdleTTL:    time.Minute,
+		MaxClients: 10,
+	})
+
+	var out bytes.Buffer
+
+	h := chain(std.New("error"), http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		io.WriteString(w, clientKey(&config.RateLimitConfig{Key: "ip"}, r)) //nolint:errcheck,gosec
+	}), rl.middleware, newAccessLog("common", &out, nil).middleware)
+
+	srv := &http.Server{Handler: h, ReadHeaderTimeout: time.Second}
+
+	go srv.Serve(ln) //nolint:errcheck
+
+	defer srv.Close() //nolint:errcheck
+
+	client := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "unix", ln.Addr().String())
+		},
+	}}
+
+	post := func(xff string) (int, string) {
+		req, _ := http.NewRequest(http.MethodPost, "http://unix/todos", nil)
+		if xff != "" {
+			req.Header.Set("X-Forwarded-For", xff)
+		}
+
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatalf("request over unix socket failed: %v", err)
+		}
+		defer resp.Body.Close()
+
+		body, _ := io.ReadAll(resp.Body)
+
+		return resp.StatusCode, string(body)
+	}
+
+	tests := []struct {
+		xff    string
+		status int
+		key    string
+	}{
+		{"198.51.100.1", http.StatusCreated, "ip:198.51.100.1"},
+		{"203.0.113.9, 198.51.100.2", http.StatusCreated, "ip:198.51.100.2"},
+		{"198.51.100.1", http.StatusTooManyRequests, ""},
+		{"", http.StatusCreated, "ip:unknown"},
+	}
+
+	for _, tt := range tests {
+		status, body := post(tt.xff)
+		if status != tt.status {
+			t.Errorf("X-Forwarded-For %q: status = %d, want %d", tt.xff, status, tt.status)
+		}
+		if tt.key != "" && body != tt.key {
+			t.Errorf("X-Forwarded-For %q: client key = %q, want %q", tt.xff, body, tt.key)
+		}
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	hosts := []string{"198.51.100.1", "198.51.100.2", "198.51.100.1", "-"}
+
+	if len(lines) != len(hosts) {
+		t.Fatalf("Expected %d access log lines, got %q", len(hosts), out.String())
+	}
+
+	for i, host := range hosts {
+		if !strings.HasPrefix(lines[i], host+" - - [") {
+			t.Errorf("Expected access log host %s, got %q", host, lines[i])
+		}
+	}
+}
+
+func TestListenUnix_KeepsOtherFiles(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "api.sock")
+
+	if err := os.WriteFile(path, []byte("data"), 0o600); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+
+	if _, err := listenUnix(path, 0o660); err == nil {
+		t.Fatal("expected an error for a regular file at the socket path")
+	}
+
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("regular file was removed: %v", err)
+	}
+}
+
+func TestListenFDs(t *testing.T) {
+	pid := strconv.Itoa(os.Getpid())
+
+	tests := []struct {
+		name    string
+		pid     string
+		fds     string
+		want    int
+		wantErr bool
+	}{
+		{"passed to this process", pid, "2", 2, false},
+		{"passed to another process", "1", "2", 0, true},
+		{"not activated", "", "", 0, true},
+		{"no sockets", pid, "0", 0, true},
+		{"invalid count", pid, "two", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n, err := listenFDs(tt.pid, tt.fds)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("listenFDs() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if n != tt.want {
+				t.Errorf("listenFDs() = %d, want %d", n, tt.want)
+			}
+		})
+	}
+}
+
+func TestFileListeners(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen failed: %v", err)
+	}
+	defer ln.Close()
+
+	f, err := ln.(*net.TCPListener).File()
+	if err != nil {
+		t.Fatalf("File failed: %v", err)
+	}
+	defer f.Close()
+
+	// fileListeners closes the descriptor, so it gets one not owned by f,
+	// standing in for a descriptor passed by systemd.
+	fd, err := syscall.Dup(int(f.Fd()))
+	if err != nil {
+		t.Fatalf("Dup failed: %v", err)
+	}
+
+	lns, err := fileListeners(fd, 1)
+	if err != nil {
+		t.Fatalf("fileListeners failed: %v", err)
+	}
+	defer lns[0].Close()
+
+	if got, want := lns[0].Addr().String(), ln.Addr().String(); got != want {
+		t.Errorf("listener address = %s, want %s", got, want)
+	}
+}
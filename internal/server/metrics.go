@@ -0,0 +1,67 @@
+package server
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"ecom-internship/internal/logger"
+	"ecom-internship/internal/metrics"
+)
+
+// unmatchedRoute labels requests that did not match any registered pattern.
+const unmatchedRoute = "unmatched"
+
+// httpMetrics holds the RED metrics of the HTTP API.
+type httpMetrics struct {
+	requests *metrics.CounterVec
+	duration *metrics.HistogramVec
+	inFlight *metrics.GaugeVec
+}
+
+func newHTTPMetrics(reg *metrics.Registry) *httpMetrics {
+	return &httpMetrics{
+		requests: reg.NewCounterVec("http_requests_total",
+			"Number of HTTP requests by route, method and status code.", "route", "method", "status"),
+		duration: reg.NewHistogramVec("http_request_duration_seconds",
+			"Latency of HTTP requests by route and method.", metrics.DefBuckets, "route", "method"),
+		inFlight: reg.NewGaugeVec("http_requests_in_flight",
+			"Number of HTTP requests currently being served.", "route"),
+	}
+}
+
+// middleware records request metrics labelled by the matched route pattern
+// rather than the raw path, keeping label cardinality bounded.
+func (m *httpMetrics) middleware(_ logger.Logger, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route := routeLabel(r.Pattern)
+
+		inFlight := m.inFlight.With(route)
+		inFlight.Inc()
+		defer inFlight.Dec()
+
+		rec := newResponseRecorder(w)
+		start := time.Now()
+
+		defer func() {
+			m.duration.With(route, r.Method).Observe(time.Since(start).Seconds())
+			m.requests.With(route, r.Method, strconv.Itoa(rec.status)).Inc()
+		}()
+
+		next.ServeHTTP(rec, r)
+	})
+}
+
+// routeLabel strips the method from a ServeMux pattern like "GET /todos/{id}".
+func routeLabel(pattern string) string {
+	if pattern == "" {
+		return unmatchedRoute
+	}
+
+	if _, path, ok := strings.Cut(pattern, " "); ok {
+		return path
+	}
+
+	return pattern
+}
@@ -0,0 +1,63 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"ecom-internship/internal/config"
+	"ecom-internship/internal/database/mem"
+	"ecom-internship/internal/health"
+	"ecom-internship/internal/logger/std"
+	"ecom-internship/internal/metrics"
+	"ecom-internship/internal/tracing"
+)
+
+func TestMetricsMiddleware(t *testing.T) {
+	log := std.New("error")
+	reg := metrics.NewRegistry()
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	router := NewRouter(cfg, log, mem.New(log), reg, tracing.NewTracer(nil), health.New(health.DefaultTimeout), nil)
+
+	for _, path := range []string{"/todos/1", "/todos/2", "/todos"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	w := httptest.NewRecorder()
+	NewAdminRouter(log, reg, nil, nil, nil, nil).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	if ct := w.Header().Get("Content-Type"); ct != metrics.ContentType {
+		t.Errorf("Expected %s, got %s", metrics.ContentType, ct)
+	}
+
+	body := w.Body.String()
+	for _, want := range []string{
+		`http_requests_total{route="/todos/{id}",method="GET",status="404"} 2`,
+		`http_requests_total{route="/todos",method="GET",status="200"} 1`,
+		`http_request_duration_seconds_count{route="/todos/{id}",method="GET"} 2`,
+		`http_requests_in_flight{route="/todos"} 0`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("Expected %q in metrics:\n%s", want, body)
+		}
+	}
+}
+
+func TestRouteLabel(t *testing.T) {
+	tests := map[string]string{
+		"":                "unmatched",
+		"GET /todos/{id}": "/todos/{id}",
+		"/metrics":        "/metrics",
+	}
+
+	for pattern, want := range tests {
+		if got := routeLabel(pattern); got != want {
+			t.Errorf("routeLabel(%q) = %q, want %q", pattern, got, want)
+		}
+	}
+}
@@ -1,53 +1,79 @@
 package server
 
 import (
+	"crypto/subtle"
 	"net/http"
-	"time"
+	"strings"
 
 	"ecom-internship/internal/httputils"
 	"ecom-internship/internal/logger"
+	"ecom-internship/internal/problem"
 )
 
-func loggingMiddleware(log logger.Logger, next http.Handler) http.Handler {
+// panicRecoveryMiddleware answers requests whose handler or middleware panics
+// with 500. http.ErrAbortHandler is passed on, and a response already started
+// is aborted, so that clients do not take it for a complete one.
+func panicRecoveryMiddleware(log logger.Logger, next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		requestID := httputils.GenerateRequestID()
+		rec := newResponseRecorder(w)
+
+		defer func() {
+			err := recover()
+			if err == nil {
+				return
+			}
 
-		ctx := httputils.WithRequestID(r.Context(), requestID)
-		r = r.WithContext(ctx)
+			if err == http.ErrAbortHandler { //nolint:errorlint,err113
+				panic(err)
+			}
 
-		start := time.Now()
-		next.ServeHTTP(w, r)
-		duration := time.Since(start)
-
-		log.Info("request completed",
-			"request_id", requestID,
-			"method", r.Method,
-			"path", r.URL.Path,
-			"remote_addr", r.RemoteAddr,
-			"user_agent", r.UserAgent(),
-			"duration", duration.String(),
-		)
+			requestID := httputils.RequestID(r)
+
+			logger.FromContext(r.Context(), log).Error("recovered from panic",
+				"request_id", requestID,
+				"error", err,
+				"path", r.URL.Path,
+				"method", r.Method,
+				"remote_addr", r.RemoteAddr,
+			)
+
+			if rec.wroteHeader {
+				panic(http.ErrAbortHandler)
+			}
+
+			problem.Write(w, r, http.StatusInternalServerError, "Internal server error")
+		}()
+
+		next.ServeHTTP(rec, r)
 	})
 }
 
-func panicRecoveryMiddleware(log logger.Logger, next http.Handler) http.Handler {
+// requestIDMiddleware assigns the request ID, taken from a valid X-Request-ID
+// header or generated, and echoes it in the response. It is the outermost
+// middleware, so that responses written by any other one carry the ID.
+func requestIDMiddleware(_ logger.Logger, next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		defer func() {
-			if err := recover(); err != nil {
-				requestID := httputils.RequestID(r)
-
-				log.Error("recovered from panic",
-					"request_id", requestID,
-					"error", err,
-					"path", r.URL.Path,
-					"method", r.Method,
-					"remote_addr", r.RemoteAddr,
-				)
-
-				w.Header().Set("Content-Type", "application/json; charset=utf-8")
-				w.WriteHeader(http.StatusInternalServerError)
-			}
-		}()
+		requestID := r.Header.Get(httputils.RequestIDHeader)
+		if !httputils.ValidRequestID(requestID) {
+			requestID = httputils.GenerateRequestID()
+		}
+
+		w.Header().Set(httputils.RequestIDHeader, requestID)
+
+		next.ServeHTTP(w, r.WithContext(httputils.WithRequestID(r.Context(), requestID)))
+	})
+}
+
+// adminAuth requires the bearer token in the Authorization header.
+func adminAuth(token string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="admin"`)
+			problem.Write(w, r, http.StatusUnauthorized, "Missing or invalid admin token")
+
+			return
+		}
 
 		next.ServeHTTP(w, r)
 	})
@@ -0,0 +1,158 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"ecom-internship/internal/config"
+	"ecom-internship/internal/httputils"
+	"ecom-internship/internal/logger/std"
+	"ecom-internship/internal/problem"
+)
+
+func TestPanicRecoveryMiddleware(t *testing.T) {
+	log := std.New("debug")
+
+	h := chain(log, http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		panic("boom")
+	}), panicRecoveryMiddleware, requestIDMiddleware)
+
+	req := httptest.NewRequest(http.MethodGet, "/todos", nil)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("Expected status 500, got %d", w.Code)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != problem.ContentTypeJSON {
+		t.Errorf("Expected %s, got %s", problem.ContentTypeJSON, ct)
+	}
+
+	var p problem.Details
+	if err := json.Unmarshal(w.Body.Bytes(), &p); err != nil {
+		t.Fatalf("Failed to unmarshal problem: %v", err)
+	}
+	if p.Status != http.StatusInternalServerError || p.Instance != "/todos" || p.RequestID == "" {
+		t.Errorf("Unexpected problem: %+v", p)
+	}
+}
+
+func TestPanicRecoveryMiddleware_Aborts(t *testing.T) {
+	log := std.New("error")
+
+	tests := []struct {
+		name string
+		h    http.HandlerFunc
+	}{
+		{"abort handler", func(http.ResponseWriter, *http.Request) { panic(http.ErrAbortHandler) }},
+		{"after headers", func(w http.ResponseWriter, _ *http.Request) {
+			w.Write([]byte("partial")) //nolint:errcheck,gosec
+			panic("boom")
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := chain(log, tt.h, panicRecoveryMiddleware)
+			w := httptest.NewRecorder()
+
+			defer func() {
+				if err := recover(); err != http.ErrAbortHandler { //nolint:errorlint,err113
+					t.Errorf("Expected http.ErrAbortHandler, got %v", err)
+				}
+
+				if strings.Contains(w.Body.String(), "Internal server error") {
+					t.Errorf("Expected no problem after the response started, got %q", w.Body.String())
+				}
+			}()
+
+			h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/todos", nil))
+		})
+	}
+}
+
+func TestRequestIDMiddleware(t *testing.T) {
+	log := std.New("error")
+
+	var seen string
+
+	h := chain(log, http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		seen = httputils.RequestID(r)
+	}), requestIDMiddleware)
+
+	tests := []struct {
+		name     string
+		incoming string
+		keep     bool
+	}{
+		{"valid incoming", "client-req-1", true},
+		{"missing", "", false},
+		{"invalid charset", "bad id\r\nX-Injected: 1", false},
+		{"too long", strings.Repeat("a", 200), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/todos", nil)
+			if tt.incoming != "" {
+				req.Header.Set(httputils.RequestIDHeader, tt.incoming)
+			}
+
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, req)
+
+			echoed := w.Header().Get(httputils.RequestIDHeader)
+			if echoed == "" || echoed != seen {
+				t.Errorf("Expected echoed ID %q to match context ID %q", echoed, seen)
+			}
+			if (echoed == tt.incoming) != tt.keep {
+				t.Errorf("Incoming ID %q kept = %v, want %v", tt.incoming, echoed == tt.incoming, tt.keep)
+			}
+		})
+	}
+}
+
+func TestNewAdmin_Token(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /debug/config", func(w http.ResponseWriter, _ *http.Request) {
+		w.Write([]byte("config")) //nolint:errcheck,gosec
+	})
+
+	srv, err := NewAdmin(&config.ServerConfig{AdminToken: "secret-admin-token"}, mux, std.New("error"))
+	if err != nil {
+		t.Fatalf("NewAdmin failed: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		header string
+		want   int
+	}{
+		{"missing", "", http.StatusUnauthorized},
+		{"wrong", "Bearer other", http.StatusUnauthorized},
+		{"not bearer", "secret-admin-token", http.StatusUnauthorized},
+		{"valid", "Bearer secret-admin-token", http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/debug/config", nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+
+			w := httptest.NewRecorder()
+			srv.server.Handler.ServeHTTP(w, req)
+
+			if w.Code != tt.want {
+				t.Errorf("Expected status %d, got %d", tt.want, w.Code)
+			}
+			if tt.want == http.StatusUnauthorized && w.Header().Get("WWW-Authenticate") == "" {
+				t.Error("Expected WWW-Authenticate header")
+			}
+		})
+	}
+}
@@ -0,0 +1,139 @@
+package server
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"math"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"ecom-internship/internal/config"
+	"ecom-internship/internal/httputils"
+	"ecom-internship/internal/logger"
+	"ecom-internship/internal/problem"
+	"ecom-internship/internal/ratelimit"
+)
+
+// Headers identifying the client when rate limits are keyed by API key or user.
+// They are expected to be set or verified by an authenticating gateway and are
+// only honoured from trusted proxies; a verified client certificate takes
+// precedence over the user header.
+const (
+	apiKeyHeader = "X-API-Key"
+	userHeader   = "X-User-ID"
+)
+
+// rateLimiter applies separate limits to reads and writes.
+type rateLimiter struct {
+	cfg   atomic.Pointer[config.RateLimitConfig]
+	read  *ratelimit.Limiter
+	write *ratelimit.Limiter
+}
+
+func newRateLimiter(cfg *config.RateLimitConfig) *rateLimiter {
+	rl := &rateLimiter{
+		read:  ratelimit.New(cfg.ReadRate, cfg.ReadBurst, cfg.IdleTTL, cfg.MaxClients),
+		write: ratelimit.New(cfg.WriteRate, cfg.WriteBurst, cfg.IdleTTL, cfg.MaxClients),
+	}
+
+	rl.cfg.Store(cfg)
+
+	return rl
+}
+
+// update applies new limits to subsequent requests keeping the state of known clients.
+func (rl *rateLimiter) update(cfg *config.RateLimitConfig) {
+	rl.read.SetLimits(cfg.ReadRate, cfg.ReadBurst, cfg.IdleTTL, cfg.MaxClients)
+	rl.write.SetLimits(cfg.WriteRate, cfg.WriteBurst, cfg.IdleTTL, cfg.MaxClients)
+	rl.cfg.Store(cfg)
+}
+
+func (rl *rateLimiter) middleware(log logger.Logger, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cfg := rl.cfg.Load()
+
+		limiter, rate := rl.write, cfg.WriteRate
+		if isReadMethod(r.Method) {
+			limiter, rate = rl.read, cfg.ReadRate
+		}
+
+		if rate <= 0 {
+			next.ServeHTTP(w, r)
+
+			return
+		}
+
+		key := clientKey(cfg, r)
+		d := limiter.Allow(key, time.Now())
+
+		w.Header().Set("RateLimit-Limit", strconv.Itoa(d.Limit))
+		w.Header().Set("RateLimit-Remaining", strconv.Itoa(d.Remaining))
+		w.Header().Set("RateLimit-Reset", ceilSeconds(d.Reset))
+
+		if !d.Allowed {
+			logger.FromContext(r.Context(), log).Warn("rate limit exceeded",
+				"request_id", httputils.RequestID(r),
+				"client", key,
+				"method", r.Method,
+				"path", r.URL.Path,
+			)
+
+			w.Header().Set("Retry-After", ceilSeconds(d.RetryAfter))
+			problem.Write(w, r, http.StatusTooManyRequests, "Rate limit exceeded, retry later")
+
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// clientKey identifies the client according to the configured strategy,
+// falling back to the client IP when the identifying header is absent or was
+// not sent by a trusted proxy. Otherwise clients could get a fresh bucket for
+// every request by changing the header.
+func clientKey(cfg *config.RateLimitConfig, r *http.Request) string {
+	fromProxy := httputils.FromTrustedProxy(r, cfg.TrustedProxies)
+
+	switch cfg.Key {
+	case "api_key":
+		if key := r.Header.Get(apiKeyHeader); key != "" && fromProxy {
+			return "api_key:" + hashAPIKey(key)
+		}
+	case "user":
+		if identity := httputils.ClientIdentity(r); identity != "" {
+			return "user:" + identity
+		}
+
+		if user := r.Header.Get(userHeader); user != "" && fromProxy {
+			return "user:" + user
+		}
+	}
+
+	ip := httputils.ClientIP(r, cfg.TrustedProxies)
+	if !ip.IsValid() {
+		// A proxy on the Unix socket did not send X-Forwarded-For.
+		return "ip:unknown"
+	}
+
+	return "ip:" + ip.String()
+}
+
+// hashAPIKey returns a truncated SHA-256 of an API key, so that the secret
+// itself is neither kept in the limiter nor logged.
+func hashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+
+	return hex.EncodeToString(sum[:8])
+}
+
+func isReadMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
+}
+
+// ceilSeconds formats d as whole seconds rounded up, as rate limit headers require.
+func ceilSeconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
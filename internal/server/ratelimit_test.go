@@ -0,0 +1,107 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"testing"
+	"time"
+
+	"ecom-internship/internal/config"
+	"ecom-internship/internal/logger/std"
+)
+
+func TestClientKey_HidesAPIKey(t *testing.T) {
+	cfg := &config.RateLimitConfig{
+		Key:            "api_key",
+		TrustedProxies: []netip.Prefix{netip.MustParsePrefix("192.0.2.0/24")},
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/todos", nil)
+	req.Header.Set(apiKeyHeader, "secret-api-key")
+
+	key := clientKey(cfg, req)
+	if !strings.HasPrefix(key, "api_key:") || strings.Contains(key, "secret-api-key") {
+		t.Errorf("Expected a hashed API key, got %q", key)
+	}
+
+	req.Header.Set(apiKeyHeader, "other-api-key")
+
+	if other := clientKey(cfg, req); other == key {
+		t.Errorf("Expected different keys to get different buckets, got %q", other)
+	}
+}
+
+func TestRateLimitMiddleware(t *testing.T) {
+	rl := newRateLimiter(&config.RateLimitConfig{
+		Key:        "api_key",
+		ReadRate:   0,
+		ReadBurst:  1,
+		WriteRate:  1,
+		WriteBurst: 2,
+		IdleTTL:    time.Minute,
+		MaxClients: 10,
+		// httptest requests come from 192.0.2.1.
+		TrustedProxies: []netip.Prefix{netip.MustParsePrefix("192.0.2.0/24")},
+	})
+
+	h := chain(std.New("error"), http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+	}), rl.middleware)
+
+	post := func(apiKey string, remote ...string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/todos", nil)
+		if len(remote) > 0 {
+			req.RemoteAddr = remote[0]
+		}
+		if apiKey != "" {
+			req.Header.Set(apiKeyHeader, apiKey)
+		}
+
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+
+		return w
+	}
+
+	for range 2 {
+		if w := post("client-a"); w.Code != http.StatusCreated {
+			t.Fatalf("Expected request within burst to pass, got %d", w.Code)
+		}
+	}
+
+	w := post("client-a")
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("Expected 429, got %d", w.Code)
+	}
+	if w.Header().Get("Retry-After") != "1" || w.Header().Get("RateLimit-Remaining") != "0" ||
+		w.Header().Get("RateLimit-Limit") != "2" {
+		t.Errorf("Unexpected rate limit headers: %v", w.Header())
+	}
+
+	if w := post("client-b"); w.Code != http.StatusCreated {
+		t.Errorf("Expected other API key to have its own bucket, got %d", w.Code)
+	}
+
+	// Keys sent directly by clients are ignored, so rotating them does not help.
+	for i, key := range []string{"key-1", "key-2", "key-3"} {
+		want := http.StatusCreated
+		if i == 2 {
+			want = http.StatusTooManyRequests
+		}
+
+		if w := post(key, "203.0.113.7:5000"); w.Code != want {
+			t.Errorf("Expected status %d for untrusted key %s, got %d", want, key, w.Code)
+		}
+	}
+
+	for range 5 {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/todos", nil))
+
+		if w.Code != http.StatusCreated || w.Header().Get("RateLimit-Limit") != "" {
+			t.Fatalf("Expected unlimited reads, got %d", w.Code)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package server
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"net/http"
+)
+
+// responseRecorder captures the status code and body size written by a handler.
+// It implements http.Flusher and http.Hijacker when the underlying writer does,
+// so streaming handlers keep working behind it.
+type responseRecorder struct {
+	http.ResponseWriter
+	status      int
+	bytes       int64
+	wroteHeader bool
+}
+
+func newResponseRecorder(w http.ResponseWriter) *responseRecorder {
+	return &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+}
+
+func (rr *responseRecorder) WriteHeader(status int) {
+	if !rr.wroteHeader && status >= http.StatusOK {
+		rr.status = status
+		rr.wroteHeader = true
+	}
+
+	rr.ResponseWriter.WriteHeader(status)
+}
+
+func (rr *responseRecorder) Write(b []byte) (int, error) {
+	rr.wroteHeader = true
+
+	n, err := rr.ResponseWriter.Write(b)
+	rr.bytes += int64(n)
+
+	return n, err
+}
+
+// Flush sends buffered data to the client; it is a no-op if the underlying writer cannot flush.
+func (rr *responseRecorder) Flush() {
+	rr.wroteHeader = true
+
+	http.NewResponseController(rr.ResponseWriter).Flush() //nolint:errcheck,gosec
+}
+
+// Hijack takes over the connection if the underlying writer supports it.
+func (rr *responseRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return http.NewResponseController(rr.ResponseWriter).Hijack()
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (rr *responseRecorder) Unwrap() http.ResponseWriter {
+	return rr.ResponseWriter
+}
+
+// countingBody counts the bytes of the request body read by a handler.
+type countingBody struct {
+	io.ReadCloser
+	bytes int64
+}
+
+func (b *countingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.bytes += int64(n)
+
+	return n, err
+}
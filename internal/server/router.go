@@ -1,30 +1,147 @@
 package server
 
 import (
+	"io"
 	"net/http"
+	"net/http/pprof"
+	"strings"
 
+	"ecom-internship/internal/config"
 	"ecom-internship/internal/database"
+	"ecom-internship/internal/graphql"
+	"ecom-internship/internal/health"
 	"ecom-internship/internal/logger"
+	"ecom-internship/internal/metrics"
 	"ecom-internship/internal/server/handler"
+	"ecom-internship/internal/tracing"
+	"ecom-internship/internal/validation"
 )
 
+// Router is the API handler. Its rate limits, CORS policy, request
+// timeouts and body size limits can be replaced at runtime with Reload.
+type Router struct {
+	*http.ServeMux
+
+	limiter  *rateLimiter
+	cors     *cors
+	timeouts *timeouts
+	limits   *routeLimits
+}
+
+// Reload applies the reloadable settings of cfg to subsequent requests.
+func (rt *Router) Reload(cfg *config.Config) {
+	rt.limiter.update(cfg.RateLimit)
+	rt.cors.update(cfg.CORS)
+	rt.timeouts.update(cfg.Server)
+	rt.limits.update(cfg.Server)
+}
+
 // NewRouter creates and configures the HTTP router with middleware.
-func NewRouter(log logger.Logger, db database.Database) *http.ServeMux {
+// Access log records are written to accessOut, or to log if it is nil.
+func NewRouter(cfg *config.Config, log logger.Logger, db database.Database,
+	reg *metrics.Registry, tracer *tracing.Tracer, hc *health.Checker, accessOut io.Writer,
+) *Router {
 	mux := http.NewServeMux()
+	api := newRoutes(mux)
+	v := validation.New(cfg.Validation)
+
+	rt := &Router{
+		ServeMux: mux,
+		limiter:  newRateLimiter(cfg.RateLimit),
+		cors:     newCORS(cfg.CORS),
+		timeouts: newTimeouts(cfg.Server),
+		limits:   newRouteLimits(cfg.Server),
+	}
 
 	middlewares := []func(logger.Logger, http.Handler) http.Handler{
+		// Inside the compressor, so that decompressed bodies are limited,
+		// and the access log, so that rejected requests are logged.
+		rt.limits.middleware,
+		rt.limiter.middleware,
+		newAccessLog(cfg.AccessLog.Format, accessOut, cfg.Logger.RedactKeys).middleware,
+		tracingMiddleware(tracer),
+		newHTTPMetrics(reg).middleware,
+		newCompressor(cfg.Compression).middleware,
+		rt.cors.middleware,
+		rt.timeouts.middleware,
+		// Directly inside the request ID, so that panics of any other
+		// middleware are answered with a problem carrying the ID.
 		panicRecoveryMiddleware,
-		loggingMiddleware,
+		requestIDMiddleware,
+	}
+
+	// Probes are polled frequently, so they skip request logging, tracing and metrics.
+	mux.Handle("GET /healthz", chain(log, handler.Healthz(log, hc), panicRecoveryMiddleware))
+	mux.Handle("GET /readyz", chain(log, handler.Readyz(log, hc), panicRecoveryMiddleware))
+
+	api.Handle("GET /todos", chain(log, handler.GetAllToDos(log, db), middlewares...))
+	api.Handle("GET /todos/{id}", chain(log, handler.GetToDoByID(log, db), middlewares...))
+
+	api.Handle("POST /todos", chain(log, handler.CreateToDo(log, db, v), middlewares...))
+
+	api.Handle("PUT /todos/{id}", chain(log, handler.UpdateToDo(log, db, v), middlewares...))
+
+	api.Handle("DELETE /todos/{id}", chain(log, handler.DeleteToDo(log, db), middlewares...))
+
+	api.Handle("GET /export", chain(log, handler.Export(log, db), middlewares...))
+	api.Handle("POST /import", chain(log, handler.Import(log, db, v), middlewares...))
+
+	api.Handle("GET /todos.ics", chain(log, handler.CalendarFeed(log, db, cfg.Calendar.FeedTokens, cfg.Calendar.UIDDomain), middlewares...))
+	api.Handle("POST /import/ics", chain(log, handler.ImportCalendar(log, db, v, cfg.Calendar.UIDDomain), middlewares...))
+
+	api.Handle("POST /graphql", chain(log, handler.GraphQL(log, graphql.New(cfg.GraphQL, db, v)), middlewares...))
+
+	for path, methods := range api.methods {
+		mux.Handle(http.MethodOptions+" "+path, chain(log, rt.cors.preflight(methods), middlewares...))
 	}
 
-	mux.Handle("GET /todos", chain(log, handler.GetAllToDos(log, db), middlewares...))
-	mux.Handle("GET /todos/{id}", chain(log, handler.GetToDoByID(log, db), middlewares...))
+	return rt
+}
+
+// routes records the methods registered for each path to answer OPTIONS requests.
+type routes struct {
+	mux     *http.ServeMux
+	methods map[string][]string
+}
+
+func newRoutes(mux *http.ServeMux) *routes {
+	return &routes{mux: mux, methods: make(map[string][]string)}
+}
+
+// Handle registers h for a "METHOD /path" pattern.
+func (rt *routes) Handle(pattern string, h http.Handler) {
+	method, path, _ := strings.Cut(pattern, " ")
+	rt.methods[path] = append(rt.methods[path], method)
+	rt.mux.Handle(pattern, h)
+}
 
-	mux.Handle("POST /todos", chain(log, handler.CreateToDo(log, db), middlewares...))
+// NewAdminRouter creates the router of the admin listener: metrics, configuration
+// reload, log levels and debugging endpoints. The log level endpoints are
+// registered only when lc is not nil.
+func NewAdminRouter(log logger.Logger, reg *metrics.Registry, db database.Database,
+	reload handler.ReloadFunc, current func() *config.Config, lc logger.LevelController,
+) *http.ServeMux {
+	mux := http.NewServeMux()
+
+	mux.Handle("GET /metrics", reg.Handler())
+	mux.Handle("POST /config/reload", chain(log, handler.ReloadConfig(log, reload), panicRecoveryMiddleware))
+
+	if lc != nil {
+		mux.Handle("GET /log/levels", chain(log, handler.LogLevels(log, lc), panicRecoveryMiddleware))
+		mux.Handle("PUT /log/levels", chain(log, handler.SetLogLevels(log, lc), panicRecoveryMiddleware))
+	}
 
-	mux.Handle("PUT /todos/{id}", chain(log, handler.UpdateToDo(log, db), middlewares...))
+	mux.Handle("GET /debug/buildinfo", chain(log, handler.BuildInfo(log), panicRecoveryMiddleware))
+	mux.Handle("GET /debug/config", chain(log, handler.EffectiveConfig(log, current), panicRecoveryMiddleware))
+	mux.Handle("GET /debug/storage", chain(log, handler.StorageStats(log, db), panicRecoveryMiddleware))
 
-	mux.Handle("DELETE /todos/{id}", chain(log, handler.DeleteToDo(log, db), middlewares...))
+	// The index serves named profiles, e.g. /debug/pprof/goroutine?debug=2 for a goroutine dump.
+	mux.HandleFunc("GET /debug/pprof/", pprof.Index)
+	mux.HandleFunc("GET /debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("GET /debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("GET /debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("POST /debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("GET /debug/pprof/trace", pprof.Trace)
 
 	return mux
 }
@@ -0,0 +1,157 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"ecom-internship/internal/config"
+	"ecom-internship/internal/database/mem"
+	"ecom-internship/internal/httputils"
+	"ecom-internship/internal/logger/std"
+	"ecom-internship/internal/metrics"
+	"ecom-internship/internal/model"
+	"ecom-internship/internal/problem"
+)
+
+func TestRouter_Reload(t *testing.T) {
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	router := newCORSRouter(t, cfg.CORS).(*Router)
+
+	post := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/todos", strings.NewReader(`{"caption":"a"}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Origin", "https://app.example.com")
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		return w
+	}
+
+	if w := post(); w.Code != http.StatusCreated || w.Header().Get("Access-Control-Allow-Origin") != "" {
+		t.Fatalf("Unexpected response before reload: %d %v", w.Code, w.Header())
+	}
+
+	next := *cfg
+	next.CORS = &config.CORSConfig{AllowedOrigins: []string{"https://app.example.com"}}
+	next.RateLimit = &config.RateLimitConfig{
+		Key: "ip", ReadRate: 10, ReadBurst: 10, WriteRate: 1, WriteBurst: 1, IdleTTL: time.Minute, MaxClients: 10,
+	}
+	router.Reload(&next)
+
+	if w := post(); w.Code != http.StatusCreated ||
+		w.Header().Get("Access-Control-Allow-Origin") != "https://app.example.com" ||
+		w.Header().Get("RateLimit-Limit") != "1" {
+		t.Errorf("Expected reloaded CORS policy and limits, got %d %v", w.Code, w.Header())
+	}
+
+	if w := post(); w.Code != http.StatusTooManyRequests {
+		t.Errorf("Expected reloaded write limit to apply, got %d", w.Code)
+	}
+}
+
+func TestRouter_RequestIDOnMiddlewareErrors(t *testing.T) {
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	router := newCORSRouter(t, cfg.CORS)
+
+	// The compressor rejects the body before the access log and handlers run.
+	req := httptest.NewRequest(http.MethodPost, "/todos", strings.NewReader("not gzip"))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Content-Encoding", "gzip")
+	req.Header.Set(httputils.RequestIDHeader, "client-req-9")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	var p problem.Details
+	if err := json.Unmarshal(w.Body.Bytes(), &p); err != nil {
+		t.Fatalf("Failed to unmarshal problem: %v", err)
+	}
+
+	if w.Code != http.StatusBadRequest || w.Header().Get(httputils.RequestIDHeader) != "client-req-9" ||
+		p.RequestID != "client-req-9" {
+		t.Errorf("Expected the request ID on the rejected request, got %d %v %+v", w.Code, w.Header(), p)
+	}
+}
+
+func TestTimeouts_Reload(t *testing.T) {
+	timeouts := newTimeouts(&config.ServerConfig{WriteTimeout: time.Minute})
+
+	srv := httptest.NewServer(chain(std.New("error"), http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		time.Sleep(100 * time.Millisecond)
+		w.Write([]byte("done")) //nolint:errcheck
+	}), timeouts.middleware))
+	defer srv.Close()
+
+	get := func() error {
+		resp, err := srv.Client().Get(srv.URL)
+		if err == nil {
+			resp.Body.Close()
+		}
+
+		return err
+	}
+
+	if err := get(); err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+
+	timeouts.update(&config.ServerConfig{WriteTimeout: 10 * time.Millisecond})
+
+	if err := get(); err == nil {
+		t.Error("Expected reloaded write timeout to abort the response")
+	}
+}
+
+func TestAdminRouter_Debug(t *testing.T) {
+	log := std.New("error")
+
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	db := mem.New(log)
+	if _, err := db.CreateToDo(context.Background(), model.ToDo{Caption: "a"}); err != nil {
+		t.Fatalf("CreateToDo failed: %v", err)
+	}
+
+	router := NewAdminRouter(log, metrics.NewRegistry(), db, nil, func() *config.Config { return cfg }, nil)
+
+	get := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+
+		return w
+	}
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"/debug/pprof/", "goroutine"},
+		{"/debug/pprof/goroutine?debug=2", "goroutine "},
+		{"/debug/buildinfo", `"go_version"`},
+		{"/debug/config", `"admin_address": "127.0.0.1"`},
+		{"/debug/storage", `"count":1,"max_id":1`},
+	}
+
+	for _, tt := range tests {
+		if w := get(tt.path); w.Code != http.StatusOK || !strings.Contains(w.Body.String(), tt.want) {
+			t.Errorf("GET %s: expected 200 with %q, got %d: %.200s", tt.path, tt.want, w.Code, w.Body.String())
+		}
+	}
+}
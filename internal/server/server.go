@@ -3,23 +3,37 @@ package server
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"net"
 	"net/http"
+	"sync"
 
 	"ecom-internship/internal/config"
+	"ecom-internship/internal/httputils"
 	"ecom-internship/internal/logger"
 )
 
 // Server represents the HTTP server.
 type Server struct {
-	server *http.Server
-	log    logger.Logger
+	server   *http.Server
+	redirect *http.Server // nil unless HTTPS is served with an HTTP redirect listener
+	log      logger.Logger
+	// listeners are the sockets served besides server.Addr.
+	listeners listeners
+
+	// draining is closed by Stop and exposed to handlers through httputils.Draining.
+	draining  chan struct{}
+	drainOnce sync.Once
 }
 
-// New creates a new HTTP server instance.
-func New(cfg *config.ServerConfig, router *http.ServeMux, log logger.Logger) *Server {
-	return &Server{
+// New creates a new HTTP server instance listening on the TCP port, the Unix
+// socket and the sockets passed by systemd, as configured. When a certificate
+// is configured it serves HTTPS on all of them, optionally verifying client
+// certificates, and may redirect plain HTTP requests from the redirect port.
+func New(cfg *config.ServerConfig, router http.Handler, log logger.Logger) (*Server, error) {
+	s := &Server{
 		server: &http.Server{
-			Addr:           ":" + cfg.Port,
 			Handler:        router,
 			ReadTimeout:    cfg.ReadTimeout,
 			WriteTimeout:   cfg.WriteTimeout,
@@ -27,19 +41,237 @@ func New(cfg *config.ServerConfig, router *http.ServeMux, log logger.Logger) *Se
 			MaxHeaderBytes: 1 << 20,
 		},
 		log: log,
+		listeners: listeners{
+			unixSocket: cfg.UnixSocket,
+			unixMode:   cfg.UnixSocketMode,
+			activation: cfg.SocketActivation,
+		},
+		draining: make(chan struct{}),
+	}
+
+	if cfg.Port != "" {
+		s.server.Addr = ":" + cfg.Port
+	}
+
+	s.server.Protocols = protocols(cfg)
+	s.server.HTTP2 = &http.HTTP2Config{
+		MaxConcurrentStreams:          cfg.HTTP2MaxConcurrentStreams,
+		MaxReadFrameSize:              cfg.HTTP2MaxReadFrameSize,
+		MaxReceiveBufferPerConnection: cfg.HTTP2ConnectionWindow,
+		MaxReceiveBufferPerStream:     cfg.HTTP2StreamWindow,
+	}
+
+	s.server.BaseContext = s.baseContext
+
+	if cfg.TLSCertFile == "" {
+		return s, nil
+	}
+
+	reloader, err := newTLSReloader(cfg, log)
+	if err != nil {
+		return nil, err
+	}
+
+	s.server.TLSConfig = reloader.TLSConfig()
+
+	if cfg.RedirectPort != "" {
+		s.redirect = &http.Server{
+			Addr:              ":" + cfg.RedirectPort,
+			Handler:           redirectHandler(cfg.Port),
+			ReadHeaderTimeout: cfg.ReadTimeout,
+			IdleTimeout:       cfg.IdleTimeout,
+		}
+	}
+
+	return s, nil
+}
+
+// protocols returns the protocols served: HTTP/1.1 always, HTTP/2 over TLS
+// when enabled and HTTP/2 with prior knowledge over cleartext (h2c) when
+// enabled for internal networks.
+func protocols(cfg *config.ServerConfig) *http.Protocols {
+	p := new(http.Protocols)
+	p.SetHTTP1(true)
+	p.SetHTTP2(cfg.HTTP2)
+	p.SetUnencryptedHTTP2(cfg.HTTP2Cleartext)
+
+	return p
+}
+
+// NewAdmin creates the admin HTTP server listening on the admin address and
+// port. Requests must carry the admin token when one is configured, and when
+// the API requires client certificates the admin listener is served over TLS
+// requiring them too.
+func NewAdmin(cfg *config.ServerConfig, router http.Handler, log logger.Logger) (*Server, error) {
+	if cfg.AdminToken != "" {
+		router = adminAuth(cfg.AdminToken, router)
+	}
+
+	s := &Server{
+		server: &http.Server{
+			Addr:              net.JoinHostPort(cfg.AdminAddress, cfg.AdminPort),
+			Handler:           router,
+			ReadHeaderTimeout: cfg.ReadTimeout,
+			IdleTimeout:       cfg.IdleTimeout,
+		},
+		log:      log,
+		draining: make(chan struct{}),
+	}
+
+	s.server.BaseContext = s.baseContext
+
+	if !cfg.AdminMutualTLS() {
+		return s, nil
+	}
+
+	reloader, err := newTLSReloader(cfg, log)
+	if err != nil {
+		return nil, err
+	}
+
+	s.server.TLSConfig = reloader.TLSConfig()
+
+	return s, nil
+}
+
+// NewGRPC creates the server of the gRPC API on the gRPC port. gRPC requires
+// HTTP/2: it is negotiated over TLS when a certificate is configured and
+// accepted with prior knowledge (h2c) otherwise. Streams are long-lived, so
+// unlike the API no read and write timeouts apply; calls carry their
+// deadlines in grpc-timeout.
+func NewGRPC(cfg *config.ServerConfig, handler http.Handler, log logger.Logger) (*Server, error) {
+	s := &Server{
+		server: &http.Server{
+			Addr:              ":" + cfg.GRPCPort,
+			Handler:           handler,
+			ReadHeaderTimeout: cfg.ReadTimeout,
+			IdleTimeout:       cfg.IdleTimeout,
+			MaxHeaderBytes:    1 << 20,
+			HTTP2: &http.HTTP2Config{
+				MaxConcurrentStreams:          cfg.HTTP2MaxConcurrentStreams,
+				MaxReadFrameSize:              cfg.HTTP2MaxReadFrameSize,
+				MaxReceiveBufferPerConnection: cfg.HTTP2ConnectionWindow,
+				MaxReceiveBufferPerStream:     cfg.HTTP2StreamWindow,
+			},
+		},
+		log:      log,
+		draining: make(chan struct{}),
+	}
+
+	s.server.BaseContext = s.baseContext
+
+	s.server.Protocols = new(http.Protocols)
+	s.server.Protocols.SetHTTP2(cfg.TLSCertFile != "")
+	s.server.Protocols.SetUnencryptedHTTP2(cfg.TLSCertFile == "")
+
+	if cfg.TLSCertFile == "" {
+		return s, nil
+	}
+
+	// h2 is offered in ALPN even when the API is limited to HTTP/1.1.
+	h2 := *cfg
+	h2.HTTP2 = true
+
+	reloader, err := newTLSReloader(&h2, log)
+	if err != nil {
+		return nil, err
 	}
+
+	s.server.TLSConfig = reloader.TLSConfig()
+
+	return s, nil
+}
+
+func (s *Server) baseContext(net.Listener) context.Context {
+	return httputils.WithDraining(context.Background(), s.draining)
 }
 
-// Start begins listening for HTTP requests.
+// Start begins listening for HTTP requests on all listeners. It returns when
+// the server is stopped or any listener fails, closing the others then.
 func (s *Server) Start() error {
-	s.log.Info("starting HTTP server", "port", s.server.Addr)
+	lns, err := s.listeners.listen(s.server.Addr)
+	if err != nil {
+		return err
+	}
+
+	errs := make(chan error, len(lns)+1)
+
+	if s.redirect != nil {
+		ln, err := net.Listen("tcp", s.redirect.Addr)
+		if err != nil {
+			for _, ln := range lns {
+				ln.Close() //nolint:errcheck,gosec
+			}
+
+			return fmt.Errorf("redirect listener: %w", err)
+		}
+
+		s.log.Info("starting HTTP to HTTPS redirect server", "port", s.redirect.Addr)
+
+		go func() { errs <- s.redirect.Serve(ln) }()
+	}
+
+	for _, ln := range lns {
+		go func() { errs <- s.serve(ln) }()
+	}
 
-	return s.server.ListenAndServe()
+	err = <-errs
+	if !errors.Is(err, http.ErrServerClosed) {
+		s.server.Close() //nolint:errcheck,gosec
+
+		if s.redirect != nil {
+			s.redirect.Close() //nolint:errcheck,gosec
+		}
+	}
+
+	return err
+}
+
+func (s *Server) serve(ln net.Listener) error {
+	addr := ln.Addr()
+
+	if s.server.TLSConfig == nil {
+		s.log.Info("starting HTTP server", "network", addr.Network(), "address", addr.String())
+
+		return s.server.Serve(ln)
+	}
+
+	s.log.Info("starting HTTPS server", "network", addr.Network(), "address", addr.String())
+
+	// Certificates come from TLSConfig, which reloads them from disk.
+	return s.server.ServeTLS(ln, "", "")
 }
 
-// Stop gracefully shuts down the server.
+// Stop closes all listeners, signals long-lived handlers through
+// httputils.Draining and waits for in-flight requests until ctx is done.
+// Connections still active then are closed and the context error is returned.
 func (s *Server) Stop(ctx context.Context) error {
 	s.log.Info("shutting down server")
 
-	return s.server.Shutdown(ctx)
+	s.drainOnce.Do(func() { close(s.draining) })
+
+	var wg sync.WaitGroup
+
+	if s.redirect != nil {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			if err := s.redirect.Shutdown(ctx); err != nil {
+				s.log.Error("failed to shutdown redirect server", "error", err)
+				s.redirect.Close() //nolint:errcheck,gosec
+			}
+		}()
+	}
+
+	err := s.server.Shutdown(ctx)
+	if err != nil {
+		s.log.Error("failed to drain connections in time, closing them", "error", err)
+		s.server.Close() //nolint:errcheck,gosec
+	}
+
+	wg.Wait()
+
+	return err
 }
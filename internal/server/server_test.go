@@ -0,0 +1,188 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"ecom-internship/internal/config"
+	"ecom-internship/internal/httputils"
+	"ecom-internship/internal/logger/std"
+)
+
+func TestServer_StopDrains(t *testing.T) {
+	started := make(chan struct{}, 1)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /stream", func(w http.ResponseWriter, r *http.Request) {
+		started <- struct{}{}
+
+		select {
+		case <-httputils.Draining(r.Context()):
+			io.WriteString(w, "goodbye") //nolint:errcheck,gosec
+		case <-time.After(5 * time.Second):
+		}
+	})
+	mux.HandleFunc("GET /slow", func(http.ResponseWriter, *http.Request) {
+		started <- struct{}{}
+
+		time.Sleep(5 * time.Second)
+	})
+
+	tests := []struct {
+		path    string
+		drained bool
+	}{
+		{"/stream", true},
+		{"/slow", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			srv, err := New(&config.ServerConfig{Port: "0"}, mux, std.New("error"))
+			if err != nil {
+				t.Fatalf("New failed: %v", err)
+			}
+
+			ln, err := net.Listen("tcp", "127.0.0.1:0")
+			if err != nil {
+				t.Fatalf("Listen failed: %v", err)
+			}
+
+			go srv.server.Serve(ln) //nolint:errcheck
+
+			type result struct {
+				body string
+				err  error
+			}
+
+			res := make(chan result, 1)
+
+			go func() {
+				resp, err := http.Get("http://" + ln.Addr().String() + tt.path)
+				if err != nil {
+					res <- result{err: err}
+
+					return
+				}
+				defer resp.Body.Close()
+
+				body, err := io.ReadAll(resp.Body)
+				res <- result{body: string(body), err: err}
+			}()
+
+			<-started
+
+			ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+			defer cancel()
+
+			err = srv.Stop(ctx)
+			if tt.drained && err != nil {
+				t.Errorf("Expected the stream to finish on draining, got %v", err)
+			}
+			if !tt.drained && !errors.Is(err, context.DeadlineExceeded) {
+				t.Errorf("Expected the drain deadline to be exceeded, got %v", err)
+			}
+
+			select {
+			case r := <-res:
+				if tt.drained && r.body != "goodbye" {
+					t.Errorf("Expected a goodbye message, got %q (%v)", r.body, r.err)
+				}
+				if !tt.drained && r.err == nil {
+					t.Errorf("Expected the connection to be closed, got %q", r.body)
+				}
+			case <-time.After(2 * time.Second):
+				t.Error("Expected the request to finish once the server stopped")
+			}
+		})
+	}
+}
+
+func TestServer_HTTP2CleartextStreaming(t *testing.T) {
+	log := std.New("error")
+	read := make(chan struct{})
+
+	// Each chunk must reach the client before the next one is written, also
+	// through the middlewares wrapping the response writer.
+	stream := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rc := http.NewResponseController(w)
+
+		for _, chunk := range []string{"first\n", "second\n"} {
+			io.WriteString(w, chunk) //nolint:errcheck,gosec
+
+			if err := rc.Flush(); err != nil {
+				t.Errorf("Flush failed: %v", err)
+			}
+
+			select {
+			case <-read:
+			case <-r.Context().Done():
+				return
+			}
+		}
+	})
+
+	mux := http.NewServeMux()
+	mux.Handle("GET /stream", chain(log, stream,
+		newAccessLog("json", io.Discard, nil).middleware,
+		newCompressor(&config.CompressionConfig{MinSize: 0, Level: -1}).middleware))
+
+	srv, err := New(&config.ServerConfig{Port: "0", HTTP2Cleartext: true}, mux, log)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen failed: %v", err)
+	}
+
+	go srv.server.Serve(ln) //nolint:errcheck
+	defer srv.server.Close()
+
+	protocols := new(http.Protocols)
+	protocols.SetUnencryptedHTTP2(true)
+
+	client := &http.Client{Transport: &http.Transport{Protocols: protocols}, Timeout: 5 * time.Second}
+
+	resp, err := client.Get("http://" + ln.Addr().String() + "/stream")
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.ProtoMajor != 2 {
+		t.Errorf("Expected HTTP/2 with prior knowledge, got %s", resp.Proto)
+	}
+
+	br := bufio.NewReader(resp.Body)
+
+	for _, want := range []string{"first\n", "second\n"} {
+		line, err := br.ReadString('\n')
+		if err != nil || line != want {
+			t.Fatalf("Expected chunk %q, got %q (%v)", want, line, err)
+		}
+
+		read <- struct{}{}
+	}
+}
+
+func TestNewGRPC_HTTP2Only(t *testing.T) {
+	srv, err := NewGRPC(&config.ServerConfig{GRPCPort: "50051"}, http.NotFoundHandler(), std.New("error"))
+	if err != nil {
+		t.Fatalf("NewGRPC failed: %v", err)
+	}
+
+	// gRPC clients connect with prior knowledge without TLS and never speak HTTP/1.1.
+	p := srv.server.Protocols
+	if p.HTTP1() || !p.UnencryptedHTTP2() || srv.server.Addr != ":50051" || srv.server.WriteTimeout != 0 {
+		t.Errorf("Unexpected server settings: protocols %v, addr %q, write timeout %v",
+			p, srv.server.Addr, srv.server.WriteTimeout)
+	}
+}
@@ -0,0 +1,61 @@
+package server
+
+import (
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"ecom-internship/internal/config"
+	"ecom-internship/internal/logger"
+)
+
+// timeouts sets read and write deadlines on every request from the current
+// configuration, the write deadline by route pattern. http.Server applies its
+// own timeouts when a request is read; the deadlines set here replace them,
+// so reloaded values take effect without restarting the listener.
+type timeouts struct {
+	cfg atomic.Pointer[config.ServerConfig]
+}
+
+func newTimeouts(cfg *config.ServerConfig) *timeouts {
+	t := &timeouts{}
+	t.update(cfg)
+
+	return t
+}
+
+// update changes the timeouts of subsequent requests.
+func (t *timeouts) update(cfg *config.ServerConfig) {
+	t.cfg.Store(cfg)
+}
+
+func (t *timeouts) middleware(_ logger.Logger, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cfg := t.cfg.Load()
+		rc := http.NewResponseController(w)
+		now := time.Now()
+
+		// Errors only mean the writer does not support deadlines, e.g. in tests.
+		// Without a body the connection is already watched for the client
+		// going away, and a read deadline would cancel the request when it
+		// expires, cutting off streamed responses.
+		if d := cfg.ReadTimeout; d > 0 && r.Body != http.NoBody {
+			rc.SetReadDeadline(now.Add(d)) //nolint:errcheck
+		}
+
+		write := cfg.WriteTimeout
+		if d, ok := cfg.RouteWriteTimeouts[r.Pattern]; ok {
+			write = d
+		}
+
+		// The zero time clears the deadline set by http.Server.
+		var deadline time.Time
+		if write > 0 {
+			deadline = now.Add(write)
+		}
+
+		rc.SetWriteDeadline(deadline) //nolint:errcheck
+
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,75 @@
+package server
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"ecom-internship/internal/config"
+	"ecom-internship/internal/logger/std"
+)
+
+func TestTimeouts_StreamingRoutes(t *testing.T) {
+	const timeout = 300 * time.Millisecond
+
+	cfg := &config.ServerConfig{
+		ReadTimeout:        timeout,
+		WriteTimeout:       timeout,
+		HandlerTimeout:     timeout,
+		RouteTimeouts:      map[string]time.Duration{"GET /export": 0},
+		RouteWriteTimeouts: map[string]time.Duration{"GET /export": 0},
+	}
+
+	// The response takes longer than every timeout.
+	h := chain(std.New("error"), http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rc := http.NewResponseController(w)
+
+		for range 8 {
+			select {
+			case <-r.Context().Done():
+				return
+			case <-time.After(timeout / 3):
+			}
+
+			io.WriteString(w, "chunk\n") //nolint:errcheck,gosec
+			rc.Flush()                   //nolint:errcheck,gosec
+		}
+	}), newRouteLimits(cfg).middleware, newTimeouts(cfg).middleware)
+
+	mux := http.NewServeMux()
+	mux.Handle("GET /export", h)
+	mux.Handle("GET /todos", h)
+
+	srv := httptest.NewUnstartedServer(mux)
+	srv.Config.ReadTimeout = cfg.ReadTimeout
+	srv.Config.WriteTimeout = cfg.WriteTimeout
+	srv.Start()
+	defer srv.Close()
+
+	tests := []struct {
+		path     string
+		complete bool
+	}{
+		{"/export", true},
+		{"/todos", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			resp, err := http.Get(srv.URL + tt.path)
+			if err != nil {
+				t.Fatalf("GET failed: %v", err)
+			}
+			defer resp.Body.Close()
+
+			body, _ := io.ReadAll(resp.Body)
+
+			if n := strings.Count(string(body), "chunk\n"); (n == 8) != tt.complete {
+				t.Errorf("Expected complete response %t, got %d chunks", tt.complete, n)
+			}
+		})
+	}
+}
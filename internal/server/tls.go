@@ -0,0 +1,215 @@
+package server
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"ecom-internship/internal/config"
+	"ecom-internship/internal/logger"
+)
+
+// errNoCACertificates is returned when the client CA bundle has no usable certificates.
+var errNoCACertificates = errors.New("no certificates found in client CA bundle")
+
+// clientAuthTypes maps TLS_CLIENT_AUTH values to crypto/tls policies.
+var clientAuthTypes = map[string]tls.ClientAuthType{
+	"none":     tls.NoClientCert,
+	"optional": tls.VerifyClientCertIfGiven,
+	"require":  tls.RequireAndVerifyClientCert,
+}
+
+// tlsReloader serves the certificate, key and client CA bundle from disk and
+// reloads them when their modification times change, so rotated certificates
+// are picked up without a restart. Files are checked at most once per interval
+// during handshakes; if a reload fails the previous configuration stays in use.
+type tlsReloader struct {
+	files      []string
+	certFile   string
+	keyFile    string
+	caFile     string
+	clientAuth tls.ClientAuthType
+	nextProtos []string
+	interval   time.Duration
+	log        logger.Logger
+
+	mu       sync.RWMutex
+	config   *tls.Config
+	modTimes []time.Time
+	// lastCheck is the time of the last check in Unix nanoseconds; handshakes
+	// compare it without locking and only the one claiming a check stats files.
+	lastCheck atomic.Int64
+}
+
+func newTLSReloader(cfg *config.ServerConfig, log logger.Logger) (*tlsReloader, error) {
+	r := &tlsReloader{
+		certFile:   cfg.TLSCertFile,
+		keyFile:    cfg.TLSKeyFile,
+		caFile:     cfg.TLSClientCAFile,
+		clientAuth: clientAuthTypes[cfg.TLSClientAuth],
+		nextProtos: []string{"http/1.1"},
+		interval:   cfg.TLSReloadInterval,
+		log:        log,
+	}
+
+	// ALPN offers HTTP/2 first; clients without it fall back to HTTP/1.1.
+	if cfg.HTTP2 {
+		r.nextProtos = []string{"h2", "http/1.1"}
+	}
+
+	r.files = []string{r.certFile, r.keyFile}
+	if r.caFile != "" {
+		r.files = append(r.files, r.caFile)
+	}
+
+	modTimes, err := r.stat()
+	if err != nil {
+		return nil, err
+	}
+
+	tlsConfig, err := r.load()
+	if err != nil {
+		return nil, err
+	}
+
+	r.config, r.modTimes = tlsConfig, modTimes
+	r.lastCheck.Store(time.Now().UnixNano())
+
+	return r, nil
+}
+
+// TLSConfig returns the server configuration delegating to the current files.
+func (r *tlsReloader) TLSConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		GetConfigForClient: r.getConfigForClient,
+		GetCertificate: func(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+			cfg, err := r.getConfigForClient(hello)
+			if err != nil {
+				return nil, err
+			}
+
+			return &cfg.Certificates[0], nil
+		},
+	}
+}
+
+func (r *tlsReloader) getConfigForClient(*tls.ClientHelloInfo) (*tls.Config, error) {
+	r.reloadIfChanged()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.config, nil
+}
+
+func (r *tlsReloader) reloadIfChanged() {
+	now := time.Now()
+
+	last := r.lastCheck.Load()
+	if now.Sub(time.Unix(0, last)) < r.interval || !r.lastCheck.CompareAndSwap(last, now.UnixNano()) {
+		return
+	}
+
+	modTimes, err := r.stat()
+	if err != nil {
+		r.log.Error("failed to check TLS files, keeping current certificate", "error", err)
+
+		return
+	}
+
+	r.mu.RLock()
+	changed := !slices.EqualFunc(modTimes, r.modTimes, time.Time.Equal)
+	r.mu.RUnlock()
+
+	if !changed {
+		return
+	}
+
+	tlsConfig, err := r.load()
+	if err != nil {
+		r.log.Error("failed to reload TLS files, keeping current certificate", "error", err)
+
+		return
+	}
+
+	r.mu.Lock()
+	r.config, r.modTimes = tlsConfig, modTimes
+	r.mu.Unlock()
+
+	r.log.Info("reloaded TLS certificate", "cert_file", r.certFile)
+}
+
+func (r *tlsReloader) stat() ([]time.Time, error) {
+	modTimes := make([]time.Time, len(r.files))
+
+	for i, file := range r.files {
+		info, err := os.Stat(file)
+		if err != nil {
+			return nil, err
+		}
+
+		modTimes[i] = info.ModTime()
+	}
+
+	return modTimes, nil
+}
+
+func (r *tlsReloader) load() (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("load key pair: %w", err)
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion:   tls.VersionTLS12,
+		Certificates: []tls.Certificate{cert},
+		NextProtos:   r.nextProtos,
+		ClientAuth:   r.clientAuth,
+	}
+
+	if r.caFile != "" {
+		pem, err := os.ReadFile(r.caFile)
+		if err != nil {
+			return nil, fmt.Errorf("read client CA bundle: %w", err)
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, errNoCACertificates
+		}
+
+		tlsConfig.ClientCAs = pool
+	}
+
+	return tlsConfig, nil
+}
+
+// redirectHandler redirects plain HTTP requests to the HTTPS port, keeping path
+// and query. An empty port stands for the default one, so that the target
+// never ends with a bare colon.
+func redirectHandler(httpsPort string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host := r.Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+
+		if httpsPort != "" && httpsPort != "443" {
+			host = net.JoinHostPort(host, httpsPort)
+		}
+
+		target := "https://" + host + r.URL.RequestURI()
+
+		// 308 keeps the method and body of non-GET requests.
+		http.Redirect(w, r, target, http.StatusPermanentRedirect)
+	})
+}
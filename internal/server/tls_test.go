@@ -0,0 +1,329 @@
+package server
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"ecom-internship/internal/config"
+	"ecom-internship/internal/httputils"
+	"ecom-internship/internal/logger/std"
+)
+
+type testCert struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+// newTestCert issues a certificate signed by parent, or a self-signed CA if parent is nil.
+func newTestCert(t *testing.T, cn string, parent *testCert, usage x509.ExtKeyUsage) *testCert {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey failed: %v", err)
+	}
+
+	serial, _ := rand.Int(rand.Reader, big.NewInt(1<<62))
+	tmpl := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: cn},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+		DNSNames:     []string{"localhost"},
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+	}
+
+	signer, signerKey := tmpl, key
+	if parent == nil {
+		tmpl.IsCA = true
+		tmpl.BasicConstraintsValid = true
+		tmpl.KeyUsage = x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature
+	} else {
+		signer, signerKey = parent.cert, parent.key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, signer, &key.PublicKey, signerKey)
+	if err != nil {
+		t.Fatalf("CreateCertificate failed: %v", err)
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("ParseCertificate failed: %v", err)
+	}
+
+	return &testCert{cert: cert, key: key}
+}
+
+func (c *testCert) certPEM() []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: c.cert.Raw})
+}
+
+func (c *testCert) keyPEM(t *testing.T) []byte {
+	t.Helper()
+
+	der, err := x509.MarshalECPrivateKey(c.key)
+	if err != nil {
+		t.Fatalf("MarshalECPrivateKey failed: %v", err)
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der})
+}
+
+func (c *testCert) tlsCertificate() tls.Certificate {
+	return tls.Certificate{Certificate: [][]byte{c.cert.Raw}, PrivateKey: c.key}
+}
+
+func writeFile(t *testing.T, path string, data []byte, modTime time.Time) {
+	t.Helper()
+
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatalf("Chtimes failed: %v", err)
+	}
+}
+
+func TestTLS_MutualAuthAndReload(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCert(t, "test-ca", nil, x509.ExtKeyUsageAny)
+	serverCert := newTestCert(t, "server-v1", ca, x509.ExtKeyUsageServerAuth)
+	clientCert := newTestCert(t, "billing-service", ca, x509.ExtKeyUsageClientAuth)
+
+	cfg := &config.ServerConfig{
+		Port:              "0",
+		TLSCertFile:       filepath.Join(dir, "tls.crt"),
+		TLSKeyFile:        filepath.Join(dir, "tls.key"),
+		TLSClientCAFile:   filepath.Join(dir, "ca.crt"),
+		TLSClientAuth:     "require",
+		TLSReloadInterval: time.Nanosecond,
+	}
+
+	start := time.Now().Add(-time.Minute)
+	writeFile(t, cfg.TLSCertFile, serverCert.certPEM(), start)
+	writeFile(t, cfg.TLSKeyFile, serverCert.keyPEM(t), start)
+	writeFile(t, cfg.TLSClientCAFile, ca.certPEM(), start)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /whoami", func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, httputils.ClientIdentity(r))
+	})
+
+	srv, err := New(cfg, mux, std.New("error"))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	// Required client certificates protect the admin listener too.
+	if admin, err := NewAdmin(cfg, mux, std.New("error")); err != nil || admin.server.TLSConfig == nil {
+		t.Errorf("Expected the admin server to require client certificates, got %v", err)
+	}
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen failed: %v", err)
+	}
+
+	go srv.server.Serve(tls.NewListener(ln, srv.server.TLSConfig))
+	defer srv.server.Close()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+
+	get := func(certs []tls.Certificate) (string, string, error) {
+		client := &http.Client{Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{RootCAs: roots, Certificates: certs, MinVersion: tls.VersionTLS12},
+		}}
+
+		resp, err := client.Get("https://" + ln.Addr().String() + "/whoami")
+		if err != nil {
+			return "", "", err
+		}
+		defer resp.Body.Close()
+
+		body, _ := io.ReadAll(resp.Body)
+
+		return string(body), resp.TLS.PeerCertificates[0].Subject.CommonName, nil
+	}
+
+	if _, _, err := get(nil); err == nil {
+		t.Error("Expected handshake without client certificate to fail")
+	}
+
+	identity, serverCN, err := get([]tls.Certificate{clientCert.tlsCertificate()})
+	if err != nil {
+		t.Fatalf("Request with client certificate failed: %v", err)
+	}
+	if identity != "billing-service" || serverCN != "server-v1" {
+		t.Errorf("Unexpected identity %q or server certificate %q", identity, serverCN)
+	}
+
+	rotated := newTestCert(t, "server-v2", ca, x509.ExtKeyUsageServerAuth)
+	writeFile(t, cfg.TLSCertFile, rotated.certPEM(), time.Now())
+	writeFile(t, cfg.TLSKeyFile, rotated.keyPEM(t), time.Now())
+
+	if _, serverCN, err = get([]tls.Certificate{clientCert.tlsCertificate()}); err != nil || serverCN != "server-v2" {
+		t.Errorf("Expected rotated certificate server-v2, got %q (%v)", serverCN, err)
+	}
+
+	writeFile(t, cfg.TLSKeyFile, []byte("broken"), time.Now().Add(time.Second))
+
+	if _, serverCN, err = get([]tls.Certificate{clientCert.tlsCertificate()}); err != nil || serverCN != "server-v2" {
+		t.Errorf("Expected previous certificate to be kept after a failed reload, got %q (%v)", serverCN, err)
+	}
+}
+
+func TestTLS_HTTP2(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCert(t, "test-ca", nil, x509.ExtKeyUsageAny)
+	serverCert := newTestCert(t, "server", ca, x509.ExtKeyUsageServerAuth)
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+
+	tests := []struct {
+		http2     bool
+		wantProto int
+	}{
+		{true, 2},
+		{false, 1},
+	}
+
+	for _, tt := range tests {
+		cfg := &config.ServerConfig{
+			Port:              "0",
+			TLSCertFile:       filepath.Join(dir, "tls.crt"),
+			TLSKeyFile:        filepath.Join(dir, "tls.key"),
+			TLSClientAuth:     "none",
+			TLSReloadInterval: time.Minute,
+			HTTP2:             tt.http2,
+		}
+
+		writeFile(t, cfg.TLSCertFile, serverCert.certPEM(), time.Now())
+		writeFile(t, cfg.TLSKeyFile, serverCert.keyPEM(t), time.Now())
+
+		srv, err := New(cfg, http.NewServeMux(), std.New("error"))
+		if err != nil {
+			t.Fatalf("New failed: %v", err)
+		}
+
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatalf("Listen failed: %v", err)
+		}
+
+		go srv.server.ServeTLS(ln, "", "") //nolint:errcheck
+
+		client := &http.Client{Transport: &http.Transport{
+			TLSClientConfig:   &tls.Config{RootCAs: roots, MinVersion: tls.VersionTLS12},
+			ForceAttemptHTTP2: true,
+		}}
+
+		resp, err := client.Get("https://" + ln.Addr().String() + "/")
+		srv.server.Close()
+
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		resp.Body.Close()
+
+		if resp.ProtoMajor != tt.wantProto {
+			t.Errorf("http2=%v: negotiated %s, want HTTP/%d", tt.http2, resp.Proto, tt.wantProto)
+		}
+	}
+}
+
+func TestTLS_InvalidFiles(t *testing.T) {
+	cfg := &config.ServerConfig{
+		Port:              "8443",
+		TLSCertFile:       filepath.Join(t.TempDir(), "missing.crt"),
+		TLSKeyFile:        filepath.Join(t.TempDir(), "missing.key"),
+		TLSReloadInterval: time.Second,
+	}
+
+	if _, err := New(cfg, http.NewServeMux(), std.New("error")); err == nil {
+		t.Error("Expected error for missing certificate files")
+	}
+}
+
+func TestStart_RedirectListenerFails(t *testing.T) {
+	dir := t.TempDir()
+	cert := newTestCert(t, "server", nil, x509.ExtKeyUsageServerAuth)
+
+	busy, err := net.Listen("tcp", ":0")
+	if err != nil {
+		t.Fatalf("Listen failed: %v", err)
+	}
+	defer busy.Close()
+
+	_, port, _ := net.SplitHostPort(busy.Addr().String())
+
+	cfg := &config.ServerConfig{
+		Port:              "0",
+		TLSCertFile:       filepath.Join(dir, "tls.crt"),
+		TLSKeyFile:        filepath.Join(dir, "tls.key"),
+		TLSClientAuth:     "none",
+		TLSReloadInterval: time.Minute,
+		RedirectPort:      port,
+	}
+
+	writeFile(t, cfg.TLSCertFile, cert.certPEM(), time.Now())
+	writeFile(t, cfg.TLSKeyFile, cert.keyPEM(t), time.Now())
+
+	srv, err := New(cfg, http.NewServeMux(), std.New("error"))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- srv.Start() }()
+
+	select {
+	case err := <-done:
+		if err == nil || errors.Is(err, http.ErrServerClosed) {
+			t.Errorf("Expected the redirect listener error, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		srv.server.Close()
+		t.Fatal("Expected Start to fail when the redirect port is taken")
+	}
+}
+
+func TestRedirectHandler(t *testing.T) {
+	tests := []struct {
+		port   string
+		target string
+		want   string
+	}{
+		{"8443", "http://example.com:8080/todos?x=1", "https://example.com:8443/todos?x=1"},
+		{"443", "http://example.com/todos/1", "https://example.com/todos/1"},
+		{"", "http://example.com:8080/todos", "https://example.com/todos"},
+	}
+
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		redirectHandler(tt.port).ServeHTTP(w, httptest.NewRequest(http.MethodPost, tt.target, nil))
+
+		if w.Code != http.StatusPermanentRedirect || w.Header().Get("Location") != tt.want {
+			t.Errorf("Expected 308 to %s, got %d %s", tt.want, w.Code, w.Header().Get("Location"))
+		}
+	}
+}
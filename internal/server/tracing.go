@@ -0,0 +1,54 @@
+package server
+
+import (
+	"net/http"
+
+	"ecom-internship/internal/logger"
+	"ecom-internship/internal/tracing"
+)
+
+// tracingMiddleware starts a server span continuing the caller's trace
+// and stores a logger carrying the trace and span IDs in the request context.
+func tracingMiddleware(t *tracing.Tracer) func(logger.Logger, http.Handler) http.Handler {
+	return func(log logger.Logger, next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := r.Context()
+			if parent, ok := tracing.Extract(r.Header); ok {
+				ctx = tracing.ContextWithRemoteParent(ctx, parent)
+			}
+
+			route := routeLabel(r.Pattern)
+
+			ctx, span := t.Start(ctx, r.Method+" "+route, tracing.KindServer)
+			defer span.End()
+
+			sc := span.SpanContext()
+			ctx = logger.WithContext(ctx, logger.FromContext(ctx, log).With(
+				"trace_id", sc.TraceID.String(),
+				"span_id", sc.SpanID.String(),
+			))
+
+			rec := newResponseRecorder(w)
+			next.ServeHTTP(rec, r.WithContext(ctx))
+
+			span.SetAttributes(
+				"http.request.method", r.Method,
+				"http.route", route,
+				"url.path", r.URL.Path,
+				"http.response.status_code", rec.status,
+				"user_agent.original", r.UserAgent(),
+			)
+
+			if rec.status >= http.StatusInternalServerError {
+				span.SetError(errStatus(rec.status))
+			}
+		})
+	}
+}
+
+// errStatus describes a failed response for span status messages.
+type errStatus int
+
+func (e errStatus) Error() string {
+	return http.StatusText(int(e))
+}
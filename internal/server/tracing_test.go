@@ -0,0 +1,47 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"ecom-internship/internal/logger"
+	"ecom-internship/internal/logger/std"
+	"ecom-internship/internal/tracing"
+)
+
+func TestTracingMiddleware(t *testing.T) {
+	log := std.New("error")
+
+	var (
+		sc       tracing.SpanContext
+		scopedLg logger.Logger
+	)
+
+	h := chain(log, http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		sc = tracing.SpanFromContext(r.Context()).SpanContext()
+		scopedLg = logger.FromContext(r.Context(), nil)
+	}), tracingMiddleware(tracing.NewTracer(nil)))
+
+	req := httptest.NewRequest(http.MethodGet, "/todos", nil)
+	req.Header.Set(tracing.TraceparentHeader, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	h.ServeHTTP(httptest.NewRecorder(), req)
+
+	if sc.TraceID.String() != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("Expected incoming trace to be continued, got %s", sc.TraceID)
+	}
+	if sc.SpanID.String() == "00f067aa0ba902b7" || !sc.SpanID.IsValid() {
+		t.Errorf("Expected a new server span, got %s", sc.SpanID)
+	}
+	if scopedLg == nil || scopedLg == logger.Logger(log) {
+		t.Error("Expected request-scoped logger in context")
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/todos", nil)
+	req.Header.Set(tracing.TraceparentHeader, "garbage")
+	h.ServeHTTP(httptest.NewRecorder(), req)
+
+	if !sc.TraceID.IsValid() || sc.TraceID.String() == "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("Expected new trace for invalid traceparent, got %s", sc.TraceID)
+	}
+}
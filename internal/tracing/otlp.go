@@ -0,0 +1,320 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"ecom-internship/internal/httputils"
+	"ecom-internship/internal/logger"
+)
+
+const (
+	// queueSize bounds the spans waiting for export; further spans are dropped.
+	queueSize = 2048
+	// batchSize is the maximal number of spans sent in one request.
+	batchSize = 512
+	// exportTimeout bounds a single export request.
+	exportTimeout = 10 * time.Second
+	// scopeName identifies the instrumentation in exported data.
+	scopeName = "ecom-internship/internal/tracing"
+)
+
+// OTLPExporter sends spans in batches to an OTLP/HTTP collector using the JSON encoding.
+type OTLPExporter struct {
+	endpoint string
+	service  string
+	interval time.Duration
+	client   *http.Client
+	log      logger.Logger
+
+	queue   chan *Span
+	flush   chan chan struct{}
+	done    chan struct{}
+	stopped chan struct{}
+	once    sync.Once
+}
+
+// NewOTLPExporter starts an exporter posting to endpoint, e.g. http://localhost:4318/v1/traces,
+// every interval or whenever a batch is full.
+func NewOTLPExporter(endpoint, service string, interval time.Duration, log logger.Logger) *OTLPExporter {
+	e := &OTLPExporter{
+		endpoint: endpoint,
+		service:  service,
+		interval: interval,
+		client:   &http.Client{Timeout: exportTimeout, Transport: &httputils.Transport{}},
+		log:      log,
+		queue:    make(chan *Span, queueSize),
+		flush:    make(chan chan struct{}),
+		done:     make(chan struct{}),
+		stopped:  make(chan struct{}),
+	}
+
+	go e.run()
+
+	return e
+}
+
+// Export enqueues a finished span without blocking.
+func (e *OTLPExporter) Export(span *Span) {
+	select {
+	case <-e.done:
+	case e.queue <- span:
+	default:
+		e.log.Warn("span queue is full, dropping span", "trace_id", span.sc.TraceID.String())
+	}
+}
+
+// Flush sends all queued spans and waits until they are exported.
+func (e *OTLPExporter) Flush(ctx context.Context) error {
+	ack := make(chan struct{})
+
+	select {
+	case e.flush <- ack:
+	case <-e.stopped:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	select {
+	case <-ack:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Shutdown exports the remaining spans and stops the exporter.
+func (e *OTLPExporter) Shutdown(ctx context.Context) error {
+	e.once.Do(func() {
+		close(e.done)
+	})
+
+	select {
+	case <-e.stopped:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (e *OTLPExporter) run() {
+	defer close(e.stopped)
+
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+
+	batch := make([]*Span, 0, batchSize)
+
+	send := func() {
+		if len(batch) > 0 {
+			e.send(batch)
+			batch = batch[:0]
+		}
+	}
+
+	drain := func() {
+		for {
+			select {
+			case span := <-e.queue:
+				batch = append(batch, span)
+				if len(batch) == batchSize {
+					send()
+				}
+			default:
+				send()
+
+				return
+			}
+		}
+	}
+
+	for {
+		select {
+		case span := <-e.queue:
+			batch = append(batch, span)
+			if len(batch) == batchSize {
+				send()
+			}
+		case <-ticker.C:
+			send()
+		case ack := <-e.flush:
+			drain()
+			close(ack)
+		case <-e.done:
+			drain()
+
+			return
+		}
+	}
+}
+
+func (e *OTLPExporter) send(spans []*Span) {
+	body, err := json.Marshal(e.payload(spans))
+	if err != nil {
+		e.log.Error("failed to encode spans", "error", err)
+
+		return
+	}
+
+	// Every export gets its own request ID, so that failures can be found in
+	// the collector logs.
+	requestID := httputils.GenerateRequestID()
+
+	ctx, cancel := context.WithTimeout(httputils.WithRequestID(context.Background(), requestID), exportTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.endpoint, bytes.NewReader(body))
+	if err != nil {
+		e.log.Error("failed to create export request", "error", err)
+
+		return
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		e.log.Error("failed to export spans", "request_id", requestID, "error", err, "spans", len(spans))
+
+		return
+	}
+
+	//nolint:errcheck
+	resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		e.log.Error("collector rejected spans",
+			"request_id", requestID, "status", resp.StatusCode, "spans", len(spans))
+	}
+}
+
+// OTLP/JSON wire types, see opentelemetry-proto trace/v1.
+type (
+	otlpRequest struct {
+		ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+	}
+
+	otlpResourceSpans struct {
+		Resource   otlpResource     `json:"resource"`
+		ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+	}
+
+	otlpResource struct {
+		Attributes []otlpKeyValue `json:"attributes"`
+	}
+
+	otlpScopeSpans struct {
+		Scope otlpScope  `json:"scope"`
+		Spans []otlpSpan `json:"spans"`
+	}
+
+	otlpScope struct {
+		Name string `json:"name"`
+	}
+
+	otlpSpan struct {
+		TraceID           string         `json:"traceId"`
+		SpanID            string         `json:"spanId"`
+		ParentSpanID      string         `json:"parentSpanId,omitempty"`
+		TraceState        string         `json:"traceState,omitempty"`
+		Name              string         `json:"name"`
+		Kind              Kind           `json:"kind"`
+		StartTimeUnixNano string         `json:"startTimeUnixNano"`
+		EndTimeUnixNano   string         `json:"endTimeUnixNano"`
+		Attributes        []otlpKeyValue `json:"attributes,omitempty"`
+		Status            otlpStatus     `json:"status"`
+	}
+
+	otlpStatus struct {
+		Code    StatusCode `json:"code,omitempty"`
+		Message string     `json:"message,omitempty"`
+	}
+
+	otlpKeyValue struct {
+		Key   string       `json:"key"`
+		Value otlpAnyValue `json:"value"`
+	}
+
+	otlpAnyValue struct {
+		StringValue *string  `json:"stringValue,omitempty"`
+		BoolValue   *bool    `json:"boolValue,omitempty"`
+		IntValue    *string  `json:"intValue,omitempty"`
+		DoubleValue *float64 `json:"doubleValue,omitempty"`
+	}
+)
+
+func (e *OTLPExporter) payload(spans []*Span) otlpRequest {
+	out := make([]otlpSpan, 0, len(spans))
+	for _, s := range spans {
+		out = append(out, toOTLP(s))
+	}
+
+	return otlpRequest{
+		ResourceSpans: []otlpResourceSpans{{
+			Resource: otlpResource{
+				Attributes: []otlpKeyValue{keyValue("service.name", e.service)},
+			},
+			ScopeSpans: []otlpScopeSpans{{
+				Scope: otlpScope{Name: scopeName},
+				Spans: out,
+			}},
+		}},
+	}
+}
+
+func toOTLP(s *Span) otlpSpan {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	span := otlpSpan{
+		TraceID:           s.sc.TraceID.String(),
+		SpanID:            s.sc.SpanID.String(),
+		TraceState:        s.sc.TraceState,
+		Name:              s.name,
+		Kind:              s.kind,
+		StartTimeUnixNano: strconv.FormatInt(s.start.UnixNano(), 10),
+		EndTimeUnixNano:   strconv.FormatInt(s.end.UnixNano(), 10),
+		Status:            otlpStatus{Code: s.status, Message: s.message},
+	}
+
+	if s.parent.IsValid() {
+		span.ParentSpanID = s.parent.String()
+	}
+
+	for _, attr := range s.attributes {
+		span.Attributes = append(span.Attributes, keyValue(attr.Key, attr.Value))
+	}
+
+	return span
+}
+
+func keyValue(key string, value any) otlpKeyValue {
+	var v otlpAnyValue
+
+	switch val := value.(type) {
+	case string:
+		v.StringValue = &val
+	case bool:
+		v.BoolValue = &val
+	case int:
+		s := strconv.Itoa(val)
+		v.IntValue = &s
+	case int64:
+		s := strconv.FormatInt(val, 10)
+		v.IntValue = &s
+	case float64:
+		v.DoubleValue = &val
+	default:
+		s := fmt.Sprint(val)
+		v.StringValue = &s
+	}
+
+	return otlpKeyValue{Key: key, Value: v}
+}
@@ -0,0 +1,177 @@
+package tracing
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Kind describes the relationship of a span to its callers, using OTLP values.
+type Kind int
+
+// Span kinds.
+const (
+	KindInternal Kind = 1
+	KindServer   Kind = 2
+	KindClient   Kind = 3
+)
+
+// StatusCode is the outcome of a span, using OTLP values.
+type StatusCode int
+
+// Span status codes.
+const (
+	StatusUnset StatusCode = 0
+	StatusOK    StatusCode = 1
+	StatusError StatusCode = 2
+)
+
+// Exporter receives finished sampled spans.
+type Exporter interface {
+	Export(span *Span)
+	Shutdown(ctx context.Context) error
+}
+
+// Tracer creates spans and hands finished ones to an exporter.
+type Tracer struct {
+	exporter Exporter
+}
+
+// NewTracer creates a Tracer. A nil exporter only propagates context.
+func NewTracer(exporter Exporter) *Tracer {
+	return &Tracer{exporter: exporter}
+}
+
+// Start creates a span that is a child of the span or remote parent in ctx.
+// New traces are always sampled; child spans follow the parent decision.
+func (t *Tracer) Start(ctx context.Context, name string, kind Kind) (context.Context, *Span) {
+	span := &Span{
+		tracer: t,
+		name:   name,
+		kind:   kind,
+		start:  time.Now(),
+	}
+
+	var parent SpanContext
+	if p := SpanFromContext(ctx); p != nil {
+		parent = p.sc
+	} else if remote, ok := ctx.Value(remoteKey).(SpanContext); ok {
+		parent = remote
+	}
+
+	if parent.IsValid() {
+		span.sc = SpanContext{TraceID: parent.TraceID, Flags: parent.Flags, TraceState: parent.TraceState}
+		span.parent = parent.SpanID
+	} else {
+		span.sc = SpanContext{TraceID: newTraceID(), Flags: flagSampled}
+	}
+
+	span.sc.SpanID = newSpanID()
+
+	return ContextWithSpan(ctx, span), span
+}
+
+// Shutdown flushes pending spans.
+func (t *Tracer) Shutdown(ctx context.Context) error {
+	if t.exporter == nil {
+		return nil
+	}
+
+	return t.exporter.Shutdown(ctx)
+}
+
+// Attribute is a key-value pair attached to a span.
+type Attribute struct {
+	Key   string
+	Value any
+}
+
+// Span is a timed operation within a trace. All methods are safe on a nil Span.
+type Span struct {
+	tracer *Tracer
+	kind   Kind
+	sc     SpanContext
+	parent SpanID
+	start  time.Time
+
+	mu         sync.Mutex
+	name       string
+	end        time.Time
+	attributes []Attribute
+	status     StatusCode
+	message    string
+	ended      bool
+}
+
+// SpanContext returns the propagated identity of the span.
+func (s *Span) SpanContext() SpanContext {
+	if s == nil {
+		return SpanContext{}
+	}
+
+	return s.sc
+}
+
+// SetName replaces the span name, e.g. once the route is known.
+func (s *Span) SetName(name string) {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.name = name
+}
+
+// SetAttributes adds alternating key-value pairs; values should be
+// strings, booleans, integers or floats.
+func (s *Span) SetAttributes(kv ...any) {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := 0; i+1 < len(kv); i += 2 {
+		if key, ok := kv[i].(string); ok {
+			s.attributes = append(s.attributes, Attribute{Key: key, Value: kv[i+1]})
+		}
+	}
+}
+
+// SetError marks the span as failed.
+func (s *Span) SetError(err error) {
+	if s == nil || err == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.status = StatusError
+	s.message = err.Error()
+}
+
+// End finishes the span and exports it if sampled. Subsequent calls are no-ops.
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+
+		return
+	}
+
+	s.ended = true
+	s.end = time.Now()
+	s.mu.Unlock()
+
+	if s.sc.Sampled() && s.tracer.exporter != nil {
+		s.tracer.exporter.Export(s)
+	}
+}
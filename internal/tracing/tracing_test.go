@@ -0,0 +1,173 @@
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"ecom-internship/internal/httputils"
+	"ecom-internship/internal/logger/std"
+)
+
+const validTraceparent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+func TestParseTraceparent(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		wantErr bool
+	}{
+		{"valid", validTraceparent, false},
+		{"future version with extra field", "01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra", false},
+		{"version ff", "ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", true},
+		{"version 00 with extra field", validTraceparent + "-extra", true},
+		{"uppercase", "00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01", true},
+		{"zero trace id", "00-00000000000000000000000000000000-00f067aa0ba902b7-01", true},
+		{"zero span id", "00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01", true},
+		{"short span id", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902-01", true},
+		{"empty", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sc, err := ParseTraceparent(tt.value)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidTraceparent) {
+					t.Errorf("Expected ErrInvalidTraceparent, got %v", err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !sc.Sampled() || sc.TraceID.String() != "4bf92f3577b34da6a3ce929d0e0e4736" {
+				t.Errorf("Unexpected span context: %+v", sc)
+			}
+		})
+	}
+}
+
+func TestTracer_Propagation(t *testing.T) {
+	tracer := NewTracer(nil)
+
+	h := http.Header{}
+	h.Set(TraceparentHeader, validTraceparent)
+	h.Set(TracestateHeader, "vendor=value")
+
+	remote, ok := Extract(h)
+	if !ok {
+		t.Fatal("Expected traceparent to be extracted")
+	}
+
+	ctx, server := tracer.Start(ContextWithRemoteParent(context.Background(), remote), "server", KindServer)
+	ctx, child := tracer.Start(ctx, "child", KindInternal)
+
+	if server.SpanContext().TraceID != remote.TraceID || child.SpanContext().TraceID != remote.TraceID {
+		t.Error("Expected spans to continue the remote trace")
+	}
+	if server.parent != remote.SpanID || child.parent != server.SpanContext().SpanID {
+		t.Error("Expected parent chain remote -> server -> child")
+	}
+
+	out := http.Header{}
+	Inject(ctx, out)
+
+	if got := out.Get(TraceparentHeader); got != child.SpanContext().Traceparent() {
+		t.Errorf("Expected injected traceparent %s, got %s", child.SpanContext().Traceparent(), got)
+	}
+	if got := out.Get(TracestateHeader); got != "vendor=value" {
+		t.Errorf("Expected tracestate to be propagated, got %q", got)
+	}
+
+	_, root := tracer.Start(context.Background(), "root", KindServer)
+	if root.parent.IsValid() || !root.SpanContext().Sampled() {
+		t.Error("Expected new sampled root span")
+	}
+}
+
+// collector is a stub OTLP/HTTP collector.
+type collector struct {
+	mu         sync.Mutex
+	spans      []otlpSpan
+	requestIDs []string
+}
+
+func (c *collector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+
+	var req otlpRequest
+	if err := json.Unmarshal(body, &req); err != nil || r.Header.Get("Content-Type") != "application/json" {
+		w.WriteHeader(http.StatusBadRequest)
+
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.requestIDs = append(c.requestIDs, r.Header.Get(httputils.RequestIDHeader))
+
+	for _, rs := range req.ResourceSpans {
+		for _, ss := range rs.ScopeSpans {
+			c.spans = append(c.spans, ss.Spans...)
+		}
+	}
+}
+
+func TestOTLPExporter(t *testing.T) {
+	c := &collector{}
+	srv := httptest.NewServer(c)
+	defer srv.Close()
+
+	exporter := NewOTLPExporter(srv.URL+"/v1/traces", "test", time.Hour, std.New("error"))
+	tracer := NewTracer(exporter)
+
+	ctx, parent := tracer.Start(context.Background(), "parent", KindServer)
+	_, child := tracer.Start(ctx, "child", KindInternal)
+	child.SetAttributes("db.operation.name", "get", "rows", 3, "cached", true)
+	child.SetError(errors.New("boom"))
+	child.End()
+	parent.End()
+
+	unsampled := NewTracer(exporter)
+	remote := ContextWithRemoteParent(context.Background(), SpanContext{TraceID: TraceID{1}, SpanID: SpanID{1}})
+	_, dropped := unsampled.Start(remote, "dropped", KindServer)
+	dropped.End()
+
+	if err := exporter.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown failed: %v", err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.spans) != 2 {
+		t.Fatalf("Expected 2 exported spans, got %d", len(c.spans))
+	}
+
+	got := c.spans[0]
+	if got.Name != "child" || got.ParentSpanID != parent.SpanContext().SpanID.String() ||
+		got.TraceID != parent.SpanContext().TraceID.String() || got.Kind != KindInternal {
+		t.Errorf("Unexpected child span: %+v", got)
+	}
+	if got.Status.Code != StatusError || got.Status.Message != "boom" {
+		t.Errorf("Expected error status, got %+v", got.Status)
+	}
+	if len(got.Attributes) != 3 || *got.Attributes[1].Value.IntValue != "3" || !*got.Attributes[2].Value.BoolValue {
+		t.Errorf("Unexpected attributes: %+v", got.Attributes)
+	}
+	if c.spans[1].ParentSpanID != "" {
+		t.Errorf("Expected root span without parent, got %s", c.spans[1].ParentSpanID)
+	}
+	if len(c.requestIDs) != 1 || !httputils.ValidRequestID(c.requestIDs[0]) {
+		t.Errorf("Expected the export to carry a request ID, got %q", c.requestIDs)
+	}
+}
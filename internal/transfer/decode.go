@@ -0,0 +1,247 @@
+package transfer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"ecom-internship/internal/model"
+)
+
+const maxLineSize = 1 << 20
+
+// Decoding errors.
+var (
+	ErrNotArray      = errors.New("expected a JSON array of todos")
+	ErrMissingColumn = errors.New("missing required csv column")
+)
+
+// Decoder reads ToDo items one by one.
+// Decode returns io.EOF when there are no more items and *RowError
+// when a single item is malformed and the rest may still be read.
+type Decoder interface {
+	Decode() (model.ToDo, error)
+	// Row returns the number of the last decoded row.
+	Row() int
+}
+
+// NewDecoder creates a Decoder reading the given format.
+func NewDecoder(format Format, r io.Reader) (Decoder, error) {
+	switch format {
+	case FormatJSON:
+		return &jsonDecoder{dec: json.NewDecoder(r)}, nil
+	case FormatNDJSON:
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+
+		return &ndjsonDecoder{scanner: scanner}, nil
+	case FormatCSV:
+		reader := csv.NewReader(r)
+		reader.FieldsPerRecord = -1
+
+		return &csvDecoder{r: reader}, nil
+	default:
+		return nil, ErrUnknownFormat
+	}
+}
+
+type jsonDecoder struct {
+	dec     *json.Decoder
+	started bool
+	row     int
+}
+
+func (d *jsonDecoder) Row() int {
+	return d.row
+}
+
+func (d *jsonDecoder) Decode() (model.ToDo, error) {
+	if !d.started {
+		tok, err := d.dec.Token()
+		if errors.Is(err, io.EOF) {
+			return model.ToDo{}, io.EOF
+		}
+
+		if err != nil {
+			return model.ToDo{}, err
+		}
+
+		if delim, ok := tok.(json.Delim); !ok || delim != '[' {
+			return model.ToDo{}, ErrNotArray
+		}
+
+		d.started = true
+	}
+
+	if !d.dec.More() {
+		if _, err := d.dec.Token(); err != nil {
+			return model.ToDo{}, err
+		}
+
+		return model.ToDo{}, io.EOF
+	}
+
+	d.row++
+
+	var raw json.RawMessage
+	if err := d.dec.Decode(&raw); err != nil {
+		return model.ToDo{}, err
+	}
+
+	var todo model.ToDo
+	if err := json.Unmarshal(raw, &todo); err != nil {
+		return model.ToDo{}, &RowError{Row: d.row, Err: err}
+	}
+
+	return todo, nil
+}
+
+type ndjsonDecoder struct {
+	scanner *bufio.Scanner
+	row     int
+}
+
+func (d *ndjsonDecoder) Row() int {
+	return d.row
+}
+
+func (d *ndjsonDecoder) Decode() (model.ToDo, error) {
+	for d.scanner.Scan() {
+		d.row++
+
+		line := bytes.TrimSpace(d.scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		var todo model.ToDo
+		if err := json.Unmarshal(line, &todo); err != nil {
+			return model.ToDo{}, &RowError{Row: d.row, Err: err}
+		}
+
+		return todo, nil
+	}
+
+	if err := d.scanner.Err(); err != nil {
+		return model.ToDo{}, err
+	}
+
+	return model.ToDo{}, io.EOF
+}
+
+type csvDecoder struct {
+	r       *csv.Reader
+	columns map[string]int
+	row     int
+}
+
+func (d *csvDecoder) readHeader() error {
+	header, err := d.r.Read()
+	if err != nil {
+		return err
+	}
+
+	d.columns = make(map[string]int, len(header))
+	for i, name := range header {
+		d.columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+
+	if _, ok := d.columns["caption"]; !ok {
+		return fmt.Errorf("%w: caption", ErrMissingColumn)
+	}
+
+	return nil
+}
+
+func (d *csvDecoder) Row() int {
+	return d.row
+}
+
+func (d *csvDecoder) Decode() (model.ToDo, error) {
+	if d.columns == nil {
+		if err := d.readHeader(); err != nil {
+			return model.ToDo{}, err
+		}
+	}
+
+	record, err := d.r.Read()
+	if err != nil {
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			d.row++
+
+			return model.ToDo{}, &RowError{Row: d.row, Err: err}
+		}
+
+		return model.ToDo{}, err
+	}
+
+	d.row++
+
+	todo, err := d.parseRecord(record)
+	if err != nil {
+		return model.ToDo{}, &RowError{Row: d.row, Err: err}
+	}
+
+	return todo, nil
+}
+
+func (d *csvDecoder) field(record []string, name string) string {
+	i, ok := d.columns[name]
+	if !ok || i >= len(record) {
+		return ""
+	}
+
+	return record[i]
+}
+
+func (d *csvDecoder) parseRecord(record []string) (model.ToDo, error) {
+	todo := model.ToDo{
+		Caption:     d.field(record, "caption"),
+		Description: d.field(record, "description"),
+	}
+
+	var err error
+
+	if v := d.field(record, "id"); v != "" {
+		if todo.ID, err = strconv.Atoi(v); err != nil {
+			return model.ToDo{}, fmt.Errorf("invalid id: %w", err)
+		}
+	}
+
+	if v := d.field(record, "is_completed"); v != "" {
+		if todo.IsCompleted, err = strconv.ParseBool(v); err != nil {
+			return model.ToDo{}, fmt.Errorf("invalid is_completed: %w", err)
+		}
+	}
+
+	if v := d.field(record, "due_at"); v != "" {
+		dueAt, err := time.Parse(time.RFC3339Nano, v)
+		if err != nil {
+			return model.ToDo{}, fmt.Errorf("invalid due_at: %w", err)
+		}
+
+		todo.DueAt = &dueAt
+	}
+
+	if v := d.field(record, "created_at"); v != "" {
+		if todo.CreatedAt, err = time.Parse(time.RFC3339Nano, v); err != nil {
+			return model.ToDo{}, fmt.Errorf("invalid created_at: %w", err)
+		}
+	}
+
+	if v := d.field(record, "updated_at"); v != "" {
+		if todo.UpdatedAt, err = time.Parse(time.RFC3339Nano, v); err != nil {
+			return model.ToDo{}, fmt.Errorf("invalid updated_at: %w", err)
+		}
+	}
+
+	return todo, nil
+}
@@ -0,0 +1,136 @@
+package transfer
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+	"time"
+
+	"ecom-internship/internal/model"
+)
+
+var csvHeader = []string{"id", "caption", "description", "is_completed", "due_at", "created_at", "updated_at"}
+
+// Encoder writes ToDo items one by one.
+type Encoder interface {
+	Encode(todo model.ToDo) error
+	// Close finishes the output. It does not close the underlying writer.
+	Close() error
+}
+
+// NewEncoder creates an Encoder writing in the given format.
+func NewEncoder(format Format, w io.Writer) (Encoder, error) {
+	switch format {
+	case FormatJSON:
+		return &jsonEncoder{w: w}, nil
+	case FormatNDJSON:
+		return &ndjsonEncoder{enc: json.NewEncoder(w)}, nil
+	case FormatCSV:
+		return &csvEncoder{w: csv.NewWriter(w)}, nil
+	default:
+		return nil, ErrUnknownFormat
+	}
+}
+
+type jsonEncoder struct {
+	w       io.Writer
+	started bool
+}
+
+func (e *jsonEncoder) Encode(todo model.ToDo) error {
+	prefix := ",\n"
+	if !e.started {
+		prefix = "[\n"
+		e.started = true
+	}
+
+	body, err := json.Marshal(todo)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.WriteString(e.w, prefix); err != nil {
+		return err
+	}
+
+	_, err = e.w.Write(body)
+
+	return err
+}
+
+func (e *jsonEncoder) Close() error {
+	suffix := "\n]\n"
+	if !e.started {
+		suffix = "[]\n"
+	}
+
+	_, err := io.WriteString(e.w, suffix)
+
+	return err
+}
+
+type ndjsonEncoder struct {
+	enc *json.Encoder
+}
+
+func (e *ndjsonEncoder) Encode(todo model.ToDo) error {
+	return e.enc.Encode(todo)
+}
+
+func (e *ndjsonEncoder) Close() error {
+	return nil
+}
+
+type csvEncoder struct {
+	w       *csv.Writer
+	started bool
+}
+
+func (e *csvEncoder) writeHeader() error {
+	if e.started {
+		return nil
+	}
+
+	e.started = true
+
+	return e.w.Write(csvHeader)
+}
+
+func (e *csvEncoder) Encode(todo model.ToDo) error {
+	if err := e.writeHeader(); err != nil {
+		return err
+	}
+
+	var dueAt string
+	if todo.DueAt != nil {
+		dueAt = todo.DueAt.Format(time.RFC3339Nano)
+	}
+
+	err := e.w.Write([]string{
+		strconv.Itoa(todo.ID),
+		todo.Caption,
+		todo.Description,
+		strconv.FormatBool(todo.IsCompleted),
+		dueAt,
+		todo.CreatedAt.Format(time.RFC3339Nano),
+		todo.UpdatedAt.Format(time.RFC3339Nano),
+	})
+	if err != nil {
+		return err
+	}
+
+	e.w.Flush()
+
+	return e.w.Error()
+}
+
+func (e *csvEncoder) Close() error {
+	if err := e.writeHeader(); err != nil {
+		return err
+	}
+
+	e.w.Flush()
+
+	return e.w.Error()
+}
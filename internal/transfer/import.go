@@ -0,0 +1,195 @@
+package transfer
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"ecom-internship/internal/database"
+	"ecom-internship/internal/model"
+	"ecom-internship/internal/validation"
+)
+
+var (
+	errDuplicateID  = errors.New("duplicate id in import")
+	errDuplicateUID = errors.New("duplicate uid in import")
+)
+
+// Result summarizes an import.
+type Result struct {
+	XMLName  xml.Name   `json:"-"                xml:"import_result"`
+	Imported int        `json:"imported"         xml:"imported"`
+	Skipped  int        `json:"skipped"          xml:"skipped"`
+	Errors   []RowIssue `json:"errors,omitempty" xml:"error,omitempty"`
+}
+
+// RowIssue is a serializable description of a RowError.
+// Validation errors produce one issue per invalid field.
+type RowIssue struct {
+	Row     int    `json:"row"             xml:"row,attr"`
+	Field   string `json:"field,omitempty" xml:"field,attr,omitempty"`
+	Message string `json:"message"         xml:",chardata"`
+}
+
+func (res *Result) addError(err *RowError) {
+	var fieldErrs validation.Errors
+	if !errors.As(err.Err, &fieldErrs) {
+		res.Errors = append(res.Errors, RowIssue{Row: err.Row, Message: err.Err.Error()})
+
+		return
+	}
+
+	for _, fe := range fieldErrs {
+		res.Errors = append(res.Errors, RowIssue{Row: err.Row, Field: fe.Field, Message: fe.Message})
+	}
+}
+
+// Import reads all items from dec and stores them in a single transaction.
+// IDs and timestamps from the input are preserved, missing ones are filled in.
+// If any row is invalid nothing is stored and ErrInvalidRows is returned
+// together with the per-row errors in Result.
+//
+// The input is read and validated before the transaction starts, so a slow
+// upload does not hold the storage lock.
+func Import(ctx context.Context,
+	db database.Transactor,
+	dec Decoder,
+	mode Mode,
+	v *validation.Validator,
+) (Result, error) {
+	var res Result
+
+	todos, seen, err := readRows(dec, v, &res)
+	if err != nil {
+		return Result{}, err
+	}
+
+	if len(res.Errors) > 0 {
+		return res, ErrInvalidRows
+	}
+
+	err = db.InTx(ctx, func(tx database.Tx) error {
+		if mode == ModeReplace {
+			tx.Clear()
+		}
+
+		for _, todo := range todos {
+			// Items imported from the same calendar before are updated in place,
+			// unless a row of the input sets that ID explicitly.
+			if id, ok := tx.IDByUID(todo.UID); ok && todo.ID == 0 && !seen[id] {
+				todo.ID = id
+			}
+
+			if mode == ModeSkipExisting && todo.ID != 0 && tx.Exists(todo.ID) {
+				res.Skipped++
+
+				continue
+			}
+
+			tx.Put(fillDefaults(todo, tx, seen))
+			res.Imported++
+		}
+
+		return nil
+	})
+	if err != nil {
+		res.Imported = 0
+		res.Skipped = 0
+	}
+
+	return res, err
+}
+
+// readRows decodes and validates all rows. Row errors are added to res; the
+// returned set holds the explicit IDs of the input.
+func readRows(dec Decoder, v *validation.Validator, res *Result) ([]model.ToDo, map[int]bool, error) {
+	var todos []model.ToDo
+
+	seen := make(map[int]bool)
+	uids := make(map[string]bool)
+
+	for {
+		todo, err := dec.Decode()
+		if errors.Is(err, io.EOF) {
+			return todos, seen, nil
+		}
+
+		var rowErr *RowError
+		if errors.As(err, &rowErr) {
+			res.addError(rowErr)
+
+			continue
+		}
+
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w: %w", ErrMalformedInput, err)
+		}
+
+		if err := checkRow(v, todo, seen, uids); err != nil {
+			res.addError(&RowError{Row: dec.Row(), Err: err})
+
+			continue
+		}
+
+		if todo.ID != 0 {
+			seen[todo.ID] = true
+		}
+
+		if todo.UID != "" {
+			uids[todo.UID] = true
+		}
+
+		todos = append(todos, todo)
+	}
+}
+
+func checkRow(v *validation.Validator, todo model.ToDo, seen map[int]bool, uids map[string]bool) error {
+	if err := v.Imported(todo); err != nil {
+		return err
+	}
+
+	if seen[todo.ID] {
+		return errDuplicateID
+	}
+
+	if uids[todo.UID] {
+		return errDuplicateUID
+	}
+
+	return nil
+}
+
+// fillDefaults sets the missing ID and timestamps of todo. Assigned IDs skip
+// the IDs in seen, so that rows with explicit IDs do not overwrite them. A row
+// replacing a stored item without created_at keeps the stored creation time.
+func fillDefaults(todo model.ToDo, tx database.Tx, seen map[int]bool) model.ToDo {
+	if todo.ID == 0 {
+		todo.ID = tx.NextID()
+		for seen[todo.ID] {
+			todo.ID++
+		}
+
+		seen[todo.ID] = true
+	}
+
+	stored, ok := tx.Get(todo.ID)
+
+	if todo.CreatedAt.IsZero() {
+		todo.CreatedAt = time.Now()
+		if ok {
+			todo.CreatedAt = stored.CreatedAt
+		}
+	}
+
+	if todo.UpdatedAt.IsZero() {
+		todo.UpdatedAt = todo.CreatedAt
+		if ok {
+			todo.UpdatedAt = time.Now()
+		}
+	}
+
+	return todo
+}
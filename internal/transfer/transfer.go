@@ -0,0 +1,85 @@
+// Package transfer provides bulk export and import of ToDo items.
+package transfer
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Format is a serialization format used for export and import.
+type Format string
+
+// Supported formats.
+const (
+	FormatJSON   Format = "json"
+	FormatCSV    Format = "csv"
+	FormatNDJSON Format = "ndjson"
+)
+
+// Mode defines how imported items are combined with the existing ones.
+type Mode string
+
+// Supported import modes.
+const (
+	// ModeMerge inserts new items and overwrites existing ones.
+	ModeMerge Mode = "merge"
+	// ModeReplace removes all existing items before importing.
+	ModeReplace Mode = "replace"
+	// ModeSkipExisting inserts new items and leaves existing ones untouched.
+	ModeSkipExisting Mode = "skip-existing"
+)
+
+// Transfer errors.
+var (
+	ErrUnknownFormat  = errors.New("unknown format")
+	ErrUnknownMode    = errors.New("unknown import mode")
+	ErrInvalidRows    = errors.New("import contains invalid rows")
+	ErrMalformedInput = errors.New("malformed import data")
+)
+
+// RowError describes a problem with a single imported row.
+// Decoders return it for recoverable errors, so decoding may continue.
+type RowError struct {
+	Row int
+	Err error
+}
+
+func (e *RowError) Error() string {
+	return fmt.Sprintf("row %d: %v", e.Row, e.Err)
+}
+
+func (e *RowError) Unwrap() error {
+	return e.Err
+}
+
+// ParseFormat converts a string into a supported Format.
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(s); f {
+	case FormatJSON, FormatCSV, FormatNDJSON:
+		return f, nil
+	default:
+		return "", fmt.Errorf("%w: %q", ErrUnknownFormat, s)
+	}
+}
+
+// ParseMode converts a string into a supported Mode.
+func ParseMode(s string) (Mode, error) {
+	switch m := Mode(s); m {
+	case ModeMerge, ModeReplace, ModeSkipExisting:
+		return m, nil
+	default:
+		return "", fmt.Errorf("%w: %q", ErrUnknownMode, s)
+	}
+}
+
+// ContentType returns the MIME type of the format.
+func (f Format) ContentType() string {
+	switch f {
+	case FormatCSV:
+		return "text/csv; charset=utf-8"
+	case FormatNDJSON:
+		return "application/x-ndjson"
+	default:
+		return "application/json; charset=utf-8"
+	}
+}
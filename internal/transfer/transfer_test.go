@@ -0,0 +1,298 @@
+package transfer
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"ecom-internship/internal/config"
+	"ecom-internship/internal/database/mem"
+	"ecom-internship/internal/logger/std"
+	"ecom-internship/internal/model"
+	"ecom-internship/internal/validation"
+)
+
+func TestEncodeDecode_RoundTrip(t *testing.T) {
+	created := time.Date(2025, 12, 29, 10, 30, 0, 0, time.UTC)
+	todos := []model.ToDo{
+		{ID: 1, Caption: "First", Description: "with, comma", CreatedAt: created, UpdatedAt: created},
+		{ID: 7, Caption: "Second", IsCompleted: true, CreatedAt: created, UpdatedAt: created.Add(time.Hour)},
+	}
+
+	for _, format := range []Format{FormatJSON, FormatCSV, FormatNDJSON} {
+		t.Run(string(format), func(t *testing.T) {
+			var buf bytes.Buffer
+
+			enc, err := NewEncoder(format, &buf)
+			if err != nil {
+				t.Fatalf("NewEncoder failed: %v", err)
+			}
+			for _, todo := range todos {
+				if err := enc.Encode(todo); err != nil {
+					t.Fatalf("Encode failed: %v", err)
+				}
+			}
+			if err := enc.Close(); err != nil {
+				t.Fatalf("Close failed: %v", err)
+			}
+
+			dec, err := NewDecoder(format, &buf)
+			if err != nil {
+				t.Fatalf("NewDecoder failed: %v", err)
+			}
+
+			for i, want := range todos {
+				got, err := dec.Decode()
+				if err != nil {
+					t.Fatalf("Decode %d failed: %v", i, err)
+				}
+				if got.ID != want.ID || got.Caption != want.Caption || got.Description != want.Description ||
+					got.IsCompleted != want.IsCompleted || !got.UpdatedAt.Equal(want.UpdatedAt) {
+					t.Errorf("Expected %+v, got %+v", want, got)
+				}
+			}
+
+			if _, err := dec.Decode(); !errors.Is(err, io.EOF) {
+				t.Errorf("Expected io.EOF, got %v", err)
+			}
+		})
+	}
+}
+
+func TestEncode_Empty(t *testing.T) {
+	var buf bytes.Buffer
+
+	enc, err := NewEncoder(FormatJSON, &buf)
+	if err != nil {
+		t.Fatalf("NewEncoder failed: %v", err)
+	}
+	if err := enc.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	if got := strings.TrimSpace(buf.String()); got != "[]" {
+		t.Errorf("Expected empty array, got %q", got)
+	}
+}
+
+func TestDecode_RowErrors(t *testing.T) {
+	input := "id,caption,is_completed\n1,ok,false\nabc,bad id,false\n3,bad bool,maybe\n"
+
+	dec, err := NewDecoder(FormatCSV, strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("NewDecoder failed: %v", err)
+	}
+
+	if _, err := dec.Decode(); err != nil {
+		t.Fatalf("Expected first row to decode, got %v", err)
+	}
+
+	for _, wantRow := range []int{2, 3} {
+		_, err := dec.Decode()
+
+		var rowErr *RowError
+		if !errors.As(err, &rowErr) {
+			t.Fatalf("Expected RowError, got %v", err)
+		}
+		if rowErr.Row != wantRow {
+			t.Errorf("Expected row %d, got %d", wantRow, rowErr.Row)
+		}
+	}
+}
+
+func newValidator() *validation.Validator {
+	return validation.New(&config.ValidationConfig{
+		MaxCaptionLength:   200,
+		MaxDescriptionSize: 4096,
+		MaxDueIn:           24 * time.Hour,
+	})
+}
+
+func newDB(t *testing.T, todos ...model.ToDo) *mem.MemDB {
+	t.Helper()
+
+	db := mem.New(std.New("debug"))
+	for _, todo := range todos {
+		if _, err := db.CreateToDo(context.Background(), todo); err != nil {
+			t.Fatalf("CreateToDo failed: %v", err)
+		}
+	}
+
+	return db
+}
+
+//nolint:funlen
+func TestImport_Modes(t *testing.T) {
+	input := `{"id":1,"caption":"Imported 1","created_at":"2020-01-01T00:00:00Z"}
+{"id":2,"caption":"Imported 2"}
+`
+
+	tests := []struct {
+		mode         Mode
+		wantImported int
+		wantSkipped  int
+		wantCount    int
+		wantCaption  string
+	}{
+		{ModeMerge, 2, 0, 3, "Imported 1"},
+		{ModeReplace, 2, 0, 2, "Imported 1"},
+		{ModeSkipExisting, 1, 1, 3, "Existing"},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.mode), func(t *testing.T) {
+			db := newDB(t,
+				model.ToDo{ID: 1, Caption: "Existing"},
+				model.ToDo{ID: 5, Caption: "Other"},
+			)
+
+			dec, err := NewDecoder(FormatNDJSON, strings.NewReader(input))
+			if err != nil {
+				t.Fatalf("NewDecoder failed: %v", err)
+			}
+
+			res, err := Import(context.Background(), db, dec, tt.mode, newValidator())
+			if err != nil {
+				t.Fatalf("Import failed: %v", err)
+			}
+
+			if res.Imported != tt.wantImported || res.Skipped != tt.wantSkipped {
+				t.Errorf("Expected %d imported and %d skipped, got %+v", tt.wantImported, tt.wantSkipped, res)
+			}
+
+			todos, err := db.GetAllToDos(context.Background())
+			if err != nil {
+				t.Fatalf("GetAllToDos failed: %v", err)
+			}
+			if len(todos) != tt.wantCount {
+				t.Errorf("Expected %d todos, got %d", tt.wantCount, len(todos))
+			}
+
+			todo, err := db.GetToDoByID(context.Background(), 1)
+			if err != nil {
+				t.Fatalf("GetToDoByID failed: %v", err)
+			}
+			if todo.Caption != tt.wantCaption {
+				t.Errorf("Expected caption %q, got %q", tt.wantCaption, todo.Caption)
+			}
+			if tt.mode != ModeSkipExisting && todo.CreatedAt.Year() != 2020 {
+				t.Errorf("Expected created_at to be preserved, got %v", todo.CreatedAt)
+			}
+		})
+	}
+}
+
+func TestImport_AssignedIDs(t *testing.T) {
+	db := newDB(t, model.ToDo{ID: 1, Caption: "Existing"})
+
+	// The first row would get ID 2 if explicit IDs later in the input were ignored.
+	input := `[{"caption":"auto"},{"id":2,"caption":"explicit"},{"caption":"auto again"}]`
+
+	dec, err := NewDecoder(FormatJSON, strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("NewDecoder failed: %v", err)
+	}
+
+	res, err := Import(context.Background(), db, dec, ModeMerge, newValidator())
+	if err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+
+	if res.Imported != 3 {
+		t.Errorf("Expected 3 imported, got %+v", res)
+	}
+
+	todos, err := db.GetAllToDos(context.Background())
+	if err != nil {
+		t.Fatalf("GetAllToDos failed: %v", err)
+	}
+
+	want := map[int]string{1: "Existing", 2: "explicit", 3: "auto", 4: "auto again"}
+	if len(todos) != len(want) {
+		t.Fatalf("Expected %d todos, got %+v", len(want), todos)
+	}
+
+	for _, todo := range todos {
+		if want[todo.ID] != todo.Caption {
+			t.Errorf("Expected caption %q for ID %d, got %q", want[todo.ID], todo.ID, todo.Caption)
+		}
+	}
+}
+
+func TestImport_KeepsCreatedAt(t *testing.T) {
+	db := newDB(t, model.ToDo{Caption: "Existing"})
+
+	existing, err := db.GetToDoByID(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("GetToDo failed: %v", err)
+	}
+
+	dec, err := NewDecoder(FormatNDJSON, strings.NewReader(`{"id":1,"caption":"Overwritten"}`))
+	if err != nil {
+		t.Fatalf("NewDecoder failed: %v", err)
+	}
+
+	if _, err := Import(context.Background(), db, dec, ModeMerge, newValidator()); err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+
+	todo, err := db.GetToDoByID(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("GetToDo failed: %v", err)
+	}
+
+	if todo.Caption != "Overwritten" || !todo.CreatedAt.Equal(existing.CreatedAt) ||
+		todo.UpdatedAt.Before(existing.CreatedAt) {
+		t.Errorf("Expected the stored created_at to be kept, got %+v, was %+v", todo, existing)
+	}
+}
+
+func TestImport_InvalidRowsRollback(t *testing.T) {
+	db := newDB(t, model.ToDo{ID: 1, Caption: "Existing"})
+
+	input := `[{"id":2,"caption":"ok"},{"id":3,"caption":""},{"id":"x"},{"id":2,"caption":"dup"}]`
+
+	dec, err := NewDecoder(FormatJSON, strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("NewDecoder failed: %v", err)
+	}
+
+	res, err := Import(context.Background(), db, dec, ModeReplace, newValidator())
+	if !errors.Is(err, ErrInvalidRows) {
+		t.Fatalf("Expected ErrInvalidRows, got %v", err)
+	}
+
+	if len(res.Errors) != 3 {
+		t.Fatalf("Expected 3 row errors, got %d", len(res.Errors))
+	}
+	for i, wantRow := range []int{2, 3, 4} {
+		if res.Errors[i].Row != wantRow {
+			t.Errorf("Expected error at row %d, got %d", wantRow, res.Errors[i].Row)
+		}
+	}
+
+	todos, err := db.GetAllToDos(context.Background())
+	if err != nil {
+		t.Fatalf("GetAllToDos failed: %v", err)
+	}
+	if len(todos) != 1 || todos[0].Caption != "Existing" {
+		t.Errorf("Expected storage to be unchanged, got %+v", todos)
+	}
+}
+
+func TestImport_MalformedInput(t *testing.T) {
+	db := newDB(t)
+
+	dec, err := NewDecoder(FormatJSON, strings.NewReader(`{"id":1}`))
+	if err != nil {
+		t.Fatalf("NewDecoder failed: %v", err)
+	}
+
+	if _, err := Import(context.Background(), db, dec, ModeMerge, newValidator()); !errors.Is(err, ErrMalformedInput) {
+		t.Errorf("Expected ErrMalformedInput, got %v", err)
+	}
+}
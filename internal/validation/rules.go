@@ -0,0 +1,89 @@
+package validation
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// StringRule checks a string value and returns a message describing
+// the violation or an empty string if the value is valid.
+type StringRule func(value string) string
+
+// TimeRule checks a time value the same way as StringRule.
+type TimeRule func(value time.Time) string
+
+// NotBlank requires a value that is not empty after trimming whitespace.
+func NotBlank() StringRule {
+	return func(value string) string {
+		if strings.TrimSpace(value) == "" {
+			return "must not be blank"
+		}
+
+		return ""
+	}
+}
+
+// MaxLength limits the number of characters. Zero disables the check.
+func MaxLength(n int) StringRule {
+	return func(value string) string {
+		if n > 0 && utf8.RuneCountInString(value) > n {
+			return fmt.Sprintf("must be at most %d characters long", n)
+		}
+
+		return ""
+	}
+}
+
+// MaxBytes limits the size of the value in bytes. Zero disables the check.
+func MaxBytes(n int) StringRule {
+	return func(value string) string {
+		if n > 0 && len(value) > n {
+			return fmt.Sprintf("must be at most %d bytes", n)
+		}
+
+		return ""
+	}
+}
+
+// PrintableOnly rejects invalid UTF-8 and non-printable characters except the allowed ones.
+func PrintableOnly(allowed ...rune) StringRule {
+	return func(value string) string {
+		if !utf8.ValidString(value) {
+			return "must be valid UTF-8"
+		}
+
+		for _, r := range value {
+			if !unicode.IsPrint(r) && r != ' ' && !slices.Contains(allowed, r) {
+				return fmt.Sprintf("must not contain character %U", r)
+			}
+		}
+
+		return ""
+	}
+}
+
+// NotBefore requires a time not earlier than limit.
+func NotBefore(limit time.Time) TimeRule {
+	return func(value time.Time) string {
+		if value.Before(limit) {
+			return "must not be before " + limit.UTC().Format(time.RFC3339)
+		}
+
+		return ""
+	}
+}
+
+// NotLaterThan requires a time at most d from now. Zero disables the check.
+func NotLaterThan(d time.Duration) TimeRule {
+	return func(value time.Time) string {
+		if d > 0 && value.After(time.Now().Add(d)) {
+			return "must not be more than " + d.String() + " in the future"
+		}
+
+		return ""
+	}
+}
@@ -0,0 +1,133 @@
+// Package validation provides declarative validation of ToDo payloads.
+package validation
+
+import (
+	"strings"
+	"time"
+
+	"ecom-internship/internal/config"
+	"ecom-internship/internal/model"
+)
+
+// FieldError describes a constraint violated by a single field.
+type FieldError struct {
+	Field   string
+	Message string
+}
+
+// Errors is a list of field errors. It is returned as error by Validator methods.
+type Errors []FieldError
+
+func (e Errors) Error() string {
+	parts := make([]string, 0, len(e))
+	for _, fe := range e {
+		parts = append(parts, fe.Field+": "+fe.Message)
+	}
+
+	return strings.Join(parts, "; ")
+}
+
+// err returns e as an error or nil if it is empty.
+func (e Errors) err() error {
+	if len(e) == 0 {
+		return nil
+	}
+
+	return e
+}
+
+// check validates a single field of a ToDo.
+type check func(todo model.ToDo) []FieldError
+
+// Validator checks ToDo items against the configured rules
+// and reports all violations at once.
+type Validator struct {
+	checks []check
+}
+
+// New creates a Validator with limits from the configuration.
+func New(cfg *config.ValidationConfig) *Validator {
+	return &Validator{
+		checks: []check{
+			stringField("caption", func(t model.ToDo) string { return t.Caption },
+				NotBlank(),
+				MaxLength(cfg.MaxCaptionLength),
+				PrintableOnly(),
+			),
+			stringField("description", func(t model.ToDo) string { return t.Description },
+				MaxBytes(cfg.MaxDescriptionSize),
+				PrintableOnly('\n', '\r', '\t'),
+			),
+			timeField("due_at", func(t model.ToDo) *time.Time { return t.DueAt },
+				NotBefore(time.Unix(0, 0)),
+				NotLaterThan(cfg.MaxDueIn),
+			),
+		},
+	}
+}
+
+// ToDo validates a ToDo payload. It returns Errors or nil.
+func (v *Validator) ToDo(todo model.ToDo) error {
+	return v.collect(todo).err()
+}
+
+// Imported validates a ToDo read from a bulk import, which also carries
+// its ID and timestamps.
+func (v *Validator) Imported(todo model.ToDo) error {
+	errs := v.collect(todo)
+
+	if todo.ID < 0 {
+		errs = append(errs, FieldError{Field: "id", Message: "must not be negative"})
+	}
+
+	if !todo.CreatedAt.IsZero() && !todo.UpdatedAt.IsZero() && todo.UpdatedAt.Before(todo.CreatedAt) {
+		errs = append(errs, FieldError{Field: "updated_at", Message: "must not be before created_at"})
+	}
+
+	return errs.err()
+}
+
+func (v *Validator) collect(todo model.ToDo) Errors {
+	var errs Errors
+
+	for _, c := range v.checks {
+		errs = append(errs, c(todo)...)
+	}
+
+	return errs
+}
+
+func stringField(name string, get func(model.ToDo) string, rules ...StringRule) check {
+	return func(todo model.ToDo) []FieldError {
+		var errs []FieldError
+
+		value := get(todo)
+		for _, rule := range rules {
+			if msg := rule(value); msg != "" {
+				errs = append(errs, FieldError{Field: name, Message: msg})
+			}
+		}
+
+		return errs
+	}
+}
+
+// timeField checks an optional time value; rules are skipped when it is not set.
+func timeField(name string, get func(model.ToDo) *time.Time, rules ...TimeRule) check {
+	return func(todo model.ToDo) []FieldError {
+		value := get(todo)
+		if value == nil {
+			return nil
+		}
+
+		var errs []FieldError
+
+		for _, rule := range rules {
+			if msg := rule(*value); msg != "" {
+				errs = append(errs, FieldError{Field: name, Message: msg})
+			}
+		}
+
+		return errs
+	}
+}
@@ -0,0 +1,102 @@
+package validation
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"ecom-internship/internal/config"
+	"ecom-internship/internal/model"
+)
+
+func newValidator() *Validator {
+	return New(&config.ValidationConfig{
+		MaxCaptionLength:   10,
+		MaxDescriptionSize: 16,
+		MaxDueIn:           24 * time.Hour,
+	})
+}
+
+func TestValidator_Valid(t *testing.T) {
+	due := time.Now().Add(time.Hour)
+	todo := model.ToDo{
+		Caption:     "Купить",
+		Description: "line 1\nline 2",
+		DueAt:       &due,
+	}
+
+	if err := newValidator().ToDo(todo); err != nil {
+		t.Errorf("Expected valid todo, got %v", err)
+	}
+}
+
+func TestValidator_CollectsAllErrors(t *testing.T) {
+	due := time.Now().Add(48 * time.Hour)
+	todo := model.ToDo{
+		Caption:     "   ",
+		Description: strings.Repeat("x", 17) + "\x00",
+		DueAt:       &due,
+	}
+
+	err := newValidator().ToDo(todo)
+
+	var errs Errors
+	if !errors.As(err, &errs) {
+		t.Fatalf("Expected Errors, got %v", err)
+	}
+
+	fields := make([]string, 0, len(errs))
+	for _, fe := range errs {
+		fields = append(fields, fe.Field)
+	}
+
+	want := "caption,description,description,due_at"
+	if got := strings.Join(fields, ","); got != want {
+		t.Errorf("Expected errors for %s, got %s (%v)", want, got, err)
+	}
+}
+
+func TestValidator_CaptionRules(t *testing.T) {
+	tests := []struct {
+		caption string
+		valid   bool
+	}{
+		{"ok", true},
+		{"", false},
+		{" \t ", false},
+		{"ровно10сим", true},
+		{"одиннадцать", false},
+		{"new\nline", false},
+		{"bad\xffutf8", false},
+	}
+
+	v := newValidator()
+
+	for _, tt := range tests {
+		err := v.ToDo(model.ToDo{Caption: tt.caption})
+		if (err == nil) != tt.valid {
+			t.Errorf("Caption %q: expected valid=%v, got %v", tt.caption, tt.valid, err)
+		}
+	}
+}
+
+func TestValidator_Imported(t *testing.T) {
+	created := time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)
+	todo := model.ToDo{
+		ID:        -1,
+		Caption:   "Imported",
+		CreatedAt: created,
+		UpdatedAt: created.Add(-time.Hour),
+	}
+
+	err := newValidator().Imported(todo)
+
+	var errs Errors
+	if !errors.As(err, &errs) || len(errs) != 2 {
+		t.Fatalf("Expected 2 field errors, got %v", err)
+	}
+	if errs[0].Field != "id" || errs[1].Field != "updated_at" {
+		t.Errorf("Unexpected errors: %v", errs)
+	}
+}